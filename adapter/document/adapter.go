@@ -1,16 +1,14 @@
 package document
 
 import (
-	"github.com/neurosnap/sentences"
 	"go.uber.org/zap"
 	"google.golang.org/genai"
 )
 
 type Adapter struct {
-	client   *genai.Client
-	training *sentences.Storage
-	model    string
-	logger   *zap.Logger
+	client *genai.Client
+	model  string
+	logger *zap.Logger
 }
 
 type Option func(*Adapter)
@@ -29,12 +27,11 @@ func WithModel(model string) Option {
 
 const defaultModel = "gemini-2.5-flash"
 
-func New(client *genai.Client, training *sentences.Storage, options ...Option) *Adapter {
+func New(client *genai.Client, options ...Option) *Adapter {
 	a := &Adapter{
-		client:   client,
-		training: training,
-		model:    defaultModel,
-		logger:   zap.NewNop(),
+		client: client,
+		model:  defaultModel,
+		logger: zap.NewNop(),
 	}
 
 	for _, o := range options {
@@ -3,10 +3,10 @@ package document
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"strings"
 
-	"github.com/neurosnap/sentences"
 	"google.golang.org/genai"
 
 	"github.com/RichardKnop/ragserver"
@@ -18,7 +18,7 @@ all the data from text on the page and all the data from tables on the page.
 Response is a JSON array, with each item being a full summary of a page.
 `
 
-func (a *Adapter) Extract(ctx context.Context, fileName string, tempFile io.ReadSeeker, topics ragserver.RelevantTopics) ([]ragserver.Document, error) {
+func (a *Adapter) Extract(ctx context.Context, fileName string, tempFile io.ReadSeeker) ([]ragserver.Document, error) {
 	documentBytes, err := io.ReadAll(tempFile)
 	if err != nil {
 		return nil, err
@@ -57,6 +57,10 @@ func (a *Adapter) Extract(ctx context.Context, fileName string, tempFile io.Read
 		config,
 	)
 	if err != nil {
+		var apiErr genai.APIError
+		if errors.As(err, &apiErr) && ragserver.IsRetryableStatusCode(apiErr.Code) {
+			return nil, ragserver.Retryable(err)
+		}
 		return nil, err
 	}
 
@@ -66,41 +70,24 @@ func (a *Adapter) Extract(ctx context.Context, fileName string, tempFile io.Read
 	}
 
 	var (
-		// Create the default sentence tokenizer
-		tokenizer  = sentences.NewSentenceTokenizer(a.training)
-		documents  = make([]ragserver.Document, 0, 100)
-		numPages   = len(response)
-		topicCount = map[string]int{}
+		documents = make([]ragserver.Document, 0, len(response))
+		numPages  = len(response)
 	)
 
+	// Each page summary becomes a single document, it is split further by a chunker
 	for i, page := range response {
 		pageNum := i + 1
 		a.logger.Sugar().Infof("processing page %d/%d", pageNum, numPages)
 
-		for _, aSentence := range tokenizer.Tokenize(page) {
-			if len(topics) > 0 {
-				aTopic, ok := topics.IsRelevant(aSentence.Text)
-				if !ok {
-					continue
-				}
-				if aTopic.Name != "" {
-					_, ok := topicCount[aTopic.Name]
-					if !ok {
-						topicCount[aTopic.Name] = 0
-					}
-					topicCount[aTopic.Name] += 1
-				}
-			}
-
-			documents = append(documents, ragserver.Document{
-				Content: strings.TrimSpace(aSentence.Text),
-				Page:    i + 1,
-			})
+		page = strings.TrimSpace(page)
+		if page == "" {
+			continue
 		}
-	}
 
-	for name, count := range topicCount {
-		a.logger.Sugar().Infof("%s relevant sentences: %d", name, count)
+		documents = append(documents, ragserver.Document{
+			Content: page,
+			Page:    pageNum,
+		})
 	}
 
 	a.logger.Sugar().Infof("number of documents: %d", len(documents))
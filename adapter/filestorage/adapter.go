@@ -54,6 +54,10 @@ func (a *Adapter) NewTempFile() (ragserver.TempFile, error) {
 	return os.CreateTemp("", "file*")
 }
 
+func (a *Adapter) OpenTempFile(name string) (ragserver.TempFile, error) {
+	return os.OpenFile(name, os.O_RDWR, 0)
+}
+
 func (a *Adapter) DeleteTempFile(name string) error {
 	return os.Remove(name)
 }
@@ -1,8 +1,12 @@
 package googlegenai
 
 import (
+	"errors"
+
 	"go.uber.org/zap"
 	"google.golang.org/genai"
+
+	"github.com/RichardKnop/ragserver"
 )
 
 type Adapter struct {
@@ -68,3 +72,13 @@ const adapterName = "google-genai"
 func (a *Adapter) Name() string {
 	return adapterName
 }
+
+// retryableAPIError marks errors of requests which could succeed if sent again later,
+// such as rate limited requests or a temporarily unavailable model, as retryable.
+func retryableAPIError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && ragserver.IsRetryableStatusCode(apiErr.Code) {
+		return ragserver.Retryable(err)
+	}
+	return err
+}
@@ -10,7 +10,8 @@ import (
 )
 
 func (a *Adapter) EmbedDocuments(ctx context.Context, documents []ragserver.Document) ([]ragserver.Vector, error) {
-	// Use the batch embedding API to embed all documents at once.
+	// Use the batch embedding API to embed all documents at once, wrap the adapter with
+	// ragserver.NewBatchEmbedder to stay within the API batch limit and retry rate limited requests.
 	contents := make([]*genai.Content, 0, len(documents))
 	for _, aDocument := range documents {
 		contents = append(contents, genai.NewContentFromText(aDocument.Content, genai.RoleUser))
@@ -22,7 +23,7 @@ func (a *Adapter) EmbedDocuments(ctx context.Context, documents []ragserver.Docu
 	)
 	a.logger.Sugar().Infof("invoking embedding model with %d documents", len(documents))
 	if err != nil {
-		return nil, fmt.Errorf("embed content error: %w", err)
+		return nil, retryableAPIError(fmt.Errorf("embed content error: %w", err))
 	}
 
 	if len(embedResponse.Embeddings) != len(documents) {
@@ -45,7 +46,7 @@ func (a *Adapter) EmbedContent(ctx context.Context, content string) (ragserver.V
 		nil,
 	)
 	if err != nil {
-		return ragserver.Vector{}, err
+		return ragserver.Vector{}, retryableAPIError(err)
 	}
 	return embedResponse.Embeddings[0].Values, nil
 }
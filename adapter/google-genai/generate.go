@@ -63,6 +63,77 @@ var (
 )
 
 func (a *Adapter) Generate(ctx context.Context, question ragserver.Question, documents []ragserver.Document) ([]ragserver.Response, error) {
+	prompt, config, err := a.preparePrompt(question, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Sugar().With("question", question.Content).Info("generating answer")
+
+	resp, err := a.client.Models.GenerateContent(
+		ctx,
+		a.generativeModel,
+		genai.Text(prompt),
+		config,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("calling generative model: %v", err)
+	}
+	if len(resp.Candidates) != 1 {
+		return nil, fmt.Errorf("got %v candidates, expected 1", len(resp.Candidates))
+	}
+
+	a.logger.Sugar().Infof("genai response: %s", resp.Text())
+
+	response, err := a.parseResponse(question, resp.Text(), documents)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ragserver.Response{response}, nil
+}
+
+// StreamGenerate works like Generate but uses the streaming API, passing each chunk of model output
+// to onPartial as it arrives. The complete output is parsed into a structured response at the end.
+func (a *Adapter) StreamGenerate(ctx context.Context, question ragserver.Question, documents []ragserver.Document, onPartial func(text string) error) ([]ragserver.Response, error) {
+	prompt, config, err := a.preparePrompt(question, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Sugar().With("question", question.Content).Info("streaming answer")
+
+	var output strings.Builder
+	for resp, err := range a.client.Models.GenerateContentStream(
+		ctx,
+		a.generativeModel,
+		genai.Text(prompt),
+		config,
+	) {
+		if err != nil {
+			return nil, fmt.Errorf("calling generative model: %v", err)
+		}
+		chunk := resp.Text()
+		if chunk == "" {
+			continue
+		}
+		output.WriteString(chunk)
+		if err := onPartial(chunk); err != nil {
+			return nil, fmt.Errorf("handling partial response: %w", err)
+		}
+	}
+
+	a.logger.Sugar().Infof("genai response: %s", output.String())
+
+	response, err := a.parseResponse(question, output.String(), documents)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ragserver.Response{response}, nil
+}
+
+func (a *Adapter) preparePrompt(question ragserver.Question, documents []ragserver.Document) (string, *genai.GenerateContentConfig, error) {
 	config := &genai.GenerateContentConfig{
 		ResponseMIMEType: "application/json",
 		ThinkingConfig: &genai.ThinkingConfig{
@@ -83,7 +154,7 @@ func (a *Adapter) Generate(ctx context.Context, question ragserver.Question, doc
 	case ragserver.QuestionTypeBoolean:
 		config.ResponseSchema = booeleanSchema
 	default:
-		return nil, fmt.Errorf("invalid query type")
+		return "", nil, fmt.Errorf("invalid query type")
 	}
 
 	var template string
@@ -91,48 +162,35 @@ func (a *Adapter) Generate(ctx context.Context, question ragserver.Question, doc
 	case ragserver.QuestionTypeText:
 		templateBytes, err := os.ReadFile(path.Join(a.templatesDir, "text.tmpl"))
 		if err != nil {
-			return nil, fmt.Errorf("reading text template: %w", err)
+			return "", nil, fmt.Errorf("reading text template: %w", err)
 		}
 		template = string(templateBytes)
 	case ragserver.QuestionTypeMetric:
 		templateBytes, err := os.ReadFile(path.Join(a.templatesDir, "metric.tmpl"))
 		if err != nil {
-			return nil, fmt.Errorf("reading metric template: %w", err)
+			return "", nil, fmt.Errorf("reading metric template: %w", err)
 		}
 		template = string(templateBytes)
 	case ragserver.QuestionTypeBoolean:
 		templateBytes, err := os.ReadFile(path.Join(a.templatesDir, "boolean.tmpl"))
 		if err != nil {
-			return nil, fmt.Errorf("reading boolean template: %w", err)
+			return "", nil, fmt.Errorf("reading boolean template: %w", err)
 		}
 		template = string(templateBytes)
 	default:
-		return nil, fmt.Errorf("invalid query type")
+		return "", nil, fmt.Errorf("invalid query type")
 	}
 
 	// Create a RAG query for the LLM with the most relevant documents as context.
 	prompt := fmt.Sprintf(template, question.Content, strings.Join(contexts, "\n"))
 
-	a.logger.Sugar().With("question", question.Content).Info("generating answer")
-
-	resp, err := a.client.Models.GenerateContent(
-		ctx,
-		a.generativeModel,
-		genai.Text(prompt),
-		config,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("calling generative model: %v", err)
-	}
-	if len(resp.Candidates) != 1 {
-		return nil, fmt.Errorf("got %v candidates, expected 1", len(resp.Candidates))
-	}
-
-	a.logger.Sugar().Infof("genai response: %s", resp.Text())
+	return prompt, config, nil
+}
 
+func (a *Adapter) parseResponse(question ragserver.Question, text string, documents []ragserver.Document) (ragserver.Response, error) {
 	modelResponse := ragserver.ModelResponse{}
-	if err := json.Unmarshal([]byte(resp.Text()), &modelResponse); err != nil {
-		return nil, fmt.Errorf("unmarshalling model response: %v", err)
+	if err := json.Unmarshal([]byte(text), &modelResponse); err != nil {
+		return ragserver.Response{}, fmt.Errorf("unmarshalling model response: %v", err)
 	}
 
 	response := ragserver.Response{
@@ -152,5 +210,5 @@ func (a *Adapter) Generate(ctx context.Context, question ragserver.Question, doc
 	}
 	response.Documents = matchedDocuments
 
-	return []ragserver.Response{response}, nil
+	return response, nil
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 
 	"github.com/knights-analytics/hugot"
 	"github.com/knights-analytics/hugot/pipelineBackends"
@@ -20,14 +21,18 @@ type modelConfig struct {
 }
 
 type Adapter struct {
-	session          *hugot.Session
-	embedding        *pipelines.FeatureExtractionPipeline
-	generative       *pipelines.TextGenerationPipeline
-	embeddingConfig  modelConfig
-	generativeConfig modelConfig
-	templatesDir     string
-	modelsDir        string
-	logger           *zap.Logger
+	session           *hugot.Session
+	embedding         *pipelines.FeatureExtractionPipeline
+	generative        *pipelines.TextGenerationPipeline
+	zeroShot          *pipelines.ZeroShotClassificationPipeline
+	zeroShotMu        sync.Mutex // labels are set on the pipeline before each run
+	embeddingConfig   modelConfig
+	generativeConfig  modelConfig
+	zeroShotConfig    modelConfig
+	zeroShotThreshold float64
+	templatesDir      string
+	modelsDir         string
+	logger            *zap.Logger
 }
 
 type Option func(*Adapter)
@@ -62,6 +67,29 @@ func WithGenerativeModelExternalDataPath(path string) Option {
 	}
 }
 
+// WithZeroShotModelName sets a zero-shot classification model used to classify documents
+// by relevant topics, see Classify.
+func WithZeroShotModelName(name string) Option {
+	return func(a *Adapter) {
+		a.zeroShotConfig.name = name
+	}
+}
+
+func WithZeroShotModelOnnxFilePath(path string) Option {
+	return func(a *Adapter) {
+		a.zeroShotConfig.onxFilePath = path
+	}
+}
+
+// WithZeroShotThreshold sets the minimum probability of a topic for a document to be relevant to it, 0.5 by default.
+func WithZeroShotThreshold(threshold float64) Option {
+	return func(a *Adapter) {
+		if threshold > 0 {
+			a.zeroShotThreshold = threshold
+		}
+	}
+}
+
 func WithTemplatesDir(dir string) Option {
 	return func(a *Adapter) {
 		a.templatesDir = dir
@@ -84,16 +112,20 @@ const (
 	defaultTemplatesDir = "templates/hugot/"
 	defaultModelsDir    = "/models"
 	defaultOnxFilePath  = "onnx/model.onnx"
+
+	defaultZeroShotThreshold = 0.5
 )
 
 func New(ctx context.Context, session *hugot.Session, options ...Option) (*Adapter, error) {
 	a := &Adapter{
-		session:          session,
-		embeddingConfig:  modelConfig{onxFilePath: defaultOnxFilePath},
-		generativeConfig: modelConfig{onxFilePath: defaultOnxFilePath},
-		templatesDir:     defaultTemplatesDir,
-		modelsDir:        defaultModelsDir,
-		logger:           zap.NewNop(),
+		session:           session,
+		embeddingConfig:   modelConfig{onxFilePath: defaultOnxFilePath},
+		generativeConfig:  modelConfig{onxFilePath: defaultOnxFilePath},
+		zeroShotConfig:    modelConfig{onxFilePath: defaultOnxFilePath},
+		zeroShotThreshold: defaultZeroShotThreshold,
+		templatesDir:      defaultTemplatesDir,
+		modelsDir:         defaultModelsDir,
+		logger:            zap.NewNop(),
 	}
 
 	for _, o := range options {
@@ -103,6 +135,7 @@ func New(ctx context.Context, session *hugot.Session, options ...Option) (*Adapt
 	a.logger.Sugar().With(
 		"embedding model config", fmt.Sprintf("%+v", a.embeddingConfig),
 		"generative model config", fmt.Sprintf("%+v", a.generativeConfig),
+		"zero-shot model config", fmt.Sprintf("%+v", a.zeroShotConfig),
 		"templates dir", a.templatesDir,
 		"models dir", a.modelsDir,
 	).Info("init hugot adapter")
@@ -121,8 +154,8 @@ func (a *Adapter) Name() string {
 }
 
 func (a *Adapter) init(ctx context.Context) error {
-	if a.embeddingConfig.name == "" && a.generativeConfig.name == "" {
-		return fmt.Errorf("either embedding model or generative model must be specified")
+	if a.embeddingConfig.name == "" && a.generativeConfig.name == "" && a.zeroShotConfig.name == "" {
+		return fmt.Errorf("either embedding, generative or zero-shot model must be specified")
 	}
 
 	if a.embeddingConfig.name != "" {
@@ -201,6 +234,49 @@ func (a *Adapter) init(ctx context.Context) error {
 		}
 	}
 
+	if a.zeroShotConfig.name != "" {
+		modelPath, err := checkModelExists(a.modelsDir, a.zeroShotConfig.name)
+		if err != nil {
+			return fmt.Errorf("failed to check zero-shot model: %w", err)
+		}
+
+		if modelPath == "" {
+			a.logger.Sugar().Info("start downloading zero-shot model:", a.zeroShotConfig.name)
+
+			downloadOptions := hugot.NewDownloadOptions()
+			downloadOptions.OnnxFilePath = a.zeroShotConfig.onxFilePath
+			modelPath, err = hugot.DownloadModel(a.zeroShotConfig.name, a.modelsDir, downloadOptions)
+			if err != nil {
+				return fmt.Errorf("failed to download zero-shot model: %w", err)
+			}
+
+			a.logger.Sugar().Info("downloaded zero-shot model:", a.zeroShotConfig.name)
+		} else {
+			a.logger.Sugar().Info("zero-shot model already exists, skipping download:", modelPath)
+		}
+
+		// Create zero-shot classification pipeline configuration, topics are scored independently
+		// so a document can be relevant to any number of them
+		config := hugot.ZeroShotClassificationConfig{
+			ModelPath:    modelPath,
+			Name:         "zeroShotPipeline",
+			OnnxFilename: a.zeroShotConfig.onxFilePath,
+			Options: []pipelineBackends.PipelineOption[*pipelines.ZeroShotClassificationPipeline]{
+				// Labels are replaced with topics of each file, the pipeline can't be created without any
+				pipelines.WithLabels([]string{"relevant"}),
+				pipelines.WithMultilabel(true),
+			},
+		}
+
+		// Create the zero-shot classification pipeline
+		a.zeroShot, err = hugot.NewPipeline(a.session, config)
+		if err != nil {
+			return fmt.Errorf("failed to create zero-shot pipeline: %w", err)
+		}
+		// The pipeline overrides hypothesis template set with an option
+		a.zeroShot.HypothesisTemplate = zeroShotHypothesisTemplate
+	}
+
 	return nil
 }
 
@@ -0,0 +1,78 @@
+package hugot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+const zeroShotHypothesisTemplate = "This text is about {}."
+
+// Classify classifies documents by topics with the zero-shot classification model, a document is relevant
+// to the most probable topic if its probability is at least the threshold. Topics are described by their
+// description, or name and keywords. Each document is run against each topic, so it is considerably
+// slower than keyword matching for many topics.
+func (a *Adapter) Classify(ctx context.Context, topics ragserver.RelevantTopics, documents []ragserver.Document) ([]*ragserver.Topic, error) {
+	if a.zeroShot == nil {
+		return nil, fmt.Errorf("zero-shot pipeline not initialized")
+	}
+
+	classified := make([]*ragserver.Topic, len(documents))
+	if len(topics) == 0 || len(documents) == 0 {
+		return classified, nil
+	}
+
+	var (
+		labels      = make([]string, 0, len(topics))
+		labelTopics = make(map[string]*ragserver.Topic, len(topics))
+	)
+	for i := range topics {
+		label := topics[i].Label()
+		if _, ok := labelTopics[label]; ok {
+			continue
+		}
+		labels = append(labels, label)
+		labelTopics[label] = &topics[i]
+	}
+
+	sequences := make([]string, 0, len(documents))
+	for _, aDocument := range documents {
+		if aDocument.Section != "" {
+			sequences = append(sequences, aDocument.Section+"\n"+aDocument.Content)
+			continue
+		}
+		sequences = append(sequences, aDocument.Content)
+	}
+
+	a.zeroShotMu.Lock()
+	defer a.zeroShotMu.Unlock()
+
+	a.zeroShot.Labels = labels
+	result, err := a.zeroShot.RunPipeline(sequences)
+	if err != nil {
+		return nil, fmt.Errorf("calling zero-shot model: %w", err)
+	}
+	if len(result.ClassificationOutputs) != len(documents) {
+		return nil, fmt.Errorf("classified batch size mismatch")
+	}
+
+	for i, output := range result.ClassificationOutputs {
+		// Values are sorted by probability, the most probable topic which doesn't exclude the document wins
+		for _, value := range output.SortedValues {
+			if value.Value < a.zeroShotThreshold {
+				break
+			}
+			aTopic := labelTopics[value.Key]
+			if aTopic == nil || aTopic.Excludes(sequences[i]) {
+				continue
+			}
+			classified[i] = aTopic
+			break
+		}
+	}
+
+	a.logger.Sugar().With("documents", len(documents), "topics", len(topics)).Info("classified documents by topics")
+
+	return classified, nil
+}
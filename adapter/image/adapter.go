@@ -0,0 +1,37 @@
+package image
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// Adapter extracts documents from images such as scanned certificates or screenshots
+// using an OCR engine.
+type Adapter struct {
+	ocr    ragserver.OCR
+	logger *zap.Logger
+}
+
+type Option func(*Adapter)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(a *Adapter) {
+		a.logger = logger
+	}
+}
+
+func New(ocr ragserver.OCR, options ...Option) *Adapter {
+	a := &Adapter{
+		ocr:    ocr,
+		logger: zap.NewNop(),
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	a.logger.Sugar().Info("init image adapter")
+
+	return a
+}
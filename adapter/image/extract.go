@@ -0,0 +1,58 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// Extract runs OCR on the image and returns a document per paragraph of recognized text. OCR output
+// keeps line breaks of the original image, so lines are joined into paragraphs separated by blank lines.
+// All documents are on page 1.
+func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.ReadSeeker) ([]ragserver.Document, error) {
+	text, err := a.ocr.Recognize(ctx, contents)
+	if err != nil {
+		return nil, fmt.Errorf("recognizing text: %w", err)
+	}
+
+	documents := make([]ragserver.Document, 0, 10)
+	for _, aParagraph := range paragraphs(text) {
+		documents = append(documents, ragserver.Document{
+			Content: aParagraph,
+			Page:    1,
+		})
+	}
+
+	a.logger.Sugar().Infof("number of documents: %d", len(documents))
+
+	return documents, nil
+}
+
+func paragraphs(text string) []string {
+	var (
+		result    []string
+		paragraph []string
+	)
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			result = append(result, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+
+	return result
+}
@@ -0,0 +1,69 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/ragservertest"
+)
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	var (
+		certificate = []byte("certificate image")
+		screenshot  = []byte("screenshot image")
+		ocr         = ragservertest.NewOCR("")
+	)
+	ocr.Add(certificate, "This certifies that the company\nhas verified its scope 1 emissions.\n\nIssued in 2023.")
+	ocr.Add(screenshot, "Scope 1 emissions 2022: 100 tCO2e\n\nScope 2 emissions 2022: 50 tCO2e")
+
+	adapter := New(ocr)
+
+	t.Run("Lines are joined into paragraphs", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "certificate.png", bytes.NewReader(certificate))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "This certifies that the company has verified its scope 1 emissions.", Page: 1},
+			{Content: "Issued in 2023.", Page: 1},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Paragraphs", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "screenshot.jpg", bytes.NewReader(screenshot))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Scope 1 emissions 2022: 100 tCO2e", Page: 1},
+			{Content: "Scope 2 emissions 2022: 50 tCO2e", Page: 1},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Unknown image", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "blank.png", bytes.NewReader([]byte("blank")))
+		require.NoError(t, err)
+		assert.Empty(t, documents)
+	})
+
+	t.Run("OCR error", func(t *testing.T) {
+		adapter := New(failingOCR{})
+		_, err := adapter.Extract(context.Background(), "broken.png", bytes.NewReader(certificate))
+		require.Error(t, err)
+	})
+}
+
+type failingOCR struct{}
+
+func (failingOCR) Recognize(ctx context.Context, image io.Reader) (string, error) {
+	return "", errors.New("ocr failed")
+}
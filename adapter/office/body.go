@@ -0,0 +1,251 @@
+package office
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// headingStyleRegex matches built-in Word paragraph styles used for headings
+var headingStyleRegex = regexp.MustCompile(`(?i)^(heading\s*\d*|title)$`)
+
+// Word documents (w: namespace) and slides (a: namespace) share the same structure of paragraphs,
+// text runs and tables, only the namespace differs, so both are parsed by local element names.
+type bodyParser struct {
+	page       int
+	pageBreaks bool // whether page breaks should increment the page number
+	blocks     []block
+	paragraphs []*strings.Builder // stack, text boxes can contain nested paragraphs
+	tables     []*tableState      // stack, tables can be nested
+	inText     bool
+	heading    bool // whether the current paragraph is a heading
+	titleShape bool // whether the current slide shape is a title placeholder
+	// Word writes both an explicit page break and a rendered page break marker
+	// for the same break, pendingBreak prevents counting it twice.
+	pendingBreak bool
+}
+
+type tableState struct {
+	rows [][]string
+	row  []string
+	cell *strings.Builder
+}
+
+func parseBody(r io.Reader, page int, pageBreaks bool) ([]block, error) {
+	p := &bodyParser{
+		page:       page,
+		pageBreaks: pageBreaks,
+	}
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			p.start(t)
+		case xml.EndElement:
+			p.end(t)
+		case xml.CharData:
+			if p.inText && len(p.paragraphs) > 0 {
+				p.pendingBreak = false
+				p.paragraphs[len(p.paragraphs)-1].Write(t)
+			}
+		}
+	}
+
+	return p.blocks, nil
+}
+
+func (p *bodyParser) start(t xml.StartElement) {
+	switch t.Name.Local {
+	case "p":
+		if len(p.paragraphs) == 0 {
+			p.heading = false
+		}
+		p.paragraphs = append(p.paragraphs, new(strings.Builder))
+	case "pStyle":
+		if headingStyleRegex.MatchString(attr(t, "val")) {
+			p.heading = true
+		}
+	case "sp":
+		p.titleShape = false
+	case "ph":
+		if typ := attr(t, "type"); typ == "title" || typ == "ctrTitle" {
+			p.titleShape = true
+		}
+	case "t":
+		p.inText = true
+	case "tab":
+		p.writeSpace()
+	case "br":
+		if p.pageBreaks && attr(t, "type") == "page" {
+			p.page += 1
+			p.pendingBreak = true
+			return
+		}
+		p.writeSpace()
+	case "lastRenderedPageBreak":
+		if !p.pageBreaks {
+			return
+		}
+		if p.pendingBreak {
+			p.pendingBreak = false
+			return
+		}
+		p.page += 1
+	case "tbl":
+		p.tables = append(p.tables, new(tableState))
+	case "tr":
+		if aTable := p.table(); aTable != nil {
+			aTable.row = nil
+		}
+	case "tc":
+		if aTable := p.table(); aTable != nil {
+			aTable.cell = new(strings.Builder)
+		}
+	}
+}
+
+func (p *bodyParser) end(t xml.EndElement) {
+	switch t.Name.Local {
+	case "t":
+		p.inText = false
+	case "sp":
+		p.titleShape = false
+	case "p":
+		if len(p.paragraphs) == 0 {
+			return
+		}
+		text := collapseSpaces(p.paragraphs[len(p.paragraphs)-1].String())
+		p.paragraphs = p.paragraphs[:len(p.paragraphs)-1]
+		if text == "" {
+			return
+		}
+		switch {
+		case len(p.paragraphs) > 0:
+			// Nested paragraph (e.g. a text box), merge into the outer one
+			p.paragraphs[len(p.paragraphs)-1].WriteString(" " + text)
+		case p.table() != nil && p.table().cell != nil:
+			p.table().cell.WriteString(" " + text)
+		default:
+			p.blocks = append(p.blocks, block{
+				Page:    p.page,
+				Text:    text,
+				Heading: p.heading || p.titleShape,
+			})
+		}
+	case "tc":
+		if aTable := p.table(); aTable != nil && aTable.cell != nil {
+			aTable.row = append(aTable.row, collapseSpaces(aTable.cell.String()))
+			aTable.cell = nil
+		}
+	case "tr":
+		if aTable := p.table(); aTable != nil {
+			aTable.rows = append(aTable.rows, aTable.row)
+			aTable.row = nil
+		}
+	case "tbl":
+		aTable := p.table()
+		if aTable == nil {
+			return
+		}
+		p.tables = p.tables[:len(p.tables)-1]
+		if outer := p.table(); outer != nil && outer.cell != nil {
+			// Nested table, flatten its text into the outer cell
+			for _, aRow := range aTable.rows {
+				outer.cell.WriteString(" " + strings.Join(aRow, " "))
+			}
+			return
+		}
+		p.blocks = append(p.blocks, block{Page: p.page, Table: normalizeTable("", aTable.rows)})
+	}
+}
+
+func (p *bodyParser) table() *tableState {
+	if len(p.tables) == 0 {
+		return nil
+	}
+	return p.tables[len(p.tables)-1]
+}
+
+func (p *bodyParser) writeSpace() {
+	if len(p.paragraphs) > 0 {
+		p.paragraphs[len(p.paragraphs)-1].WriteString(" ")
+	}
+}
+
+func attr(t xml.StartElement, local string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parseDocx(files map[string]*zip.File) ([]block, error) {
+	r, err := files["word/document.xml"].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	blocks, err := parseBody(r, 1, true)
+	if err != nil {
+		return nil, fmt.Errorf("parsing word/document.xml: %w", err)
+	}
+	return blocks, nil
+}
+
+type pptxPresentation struct {
+	Slides []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sldIdLst>sldId"`
+}
+
+func parsePptx(files map[string]*zip.File) ([]block, error) {
+	presentation := pptxPresentation{}
+	if err := readXML(files, "ppt/presentation.xml", &presentation); err != nil {
+		return nil, err
+	}
+
+	targets, err := readRelationships(files, "ppt", "ppt/_rels/presentation.xml.rels")
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []block
+	for i, aSlide := range presentation.Slides {
+		name, ok := targets[aSlide.RID]
+		if !ok {
+			return nil, fmt.Errorf("missing relationship for slide %d", i+1)
+		}
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("missing %s", name)
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		slideBlocks, err := parseBody(r, i+1, false)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		blocks = append(blocks, slideBlocks...)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,176 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/adapter/pdf"
+)
+
+// block is either a paragraph of text or a table found on a page, slide or sheet.
+type block struct {
+	Page    int
+	Text    string
+	Heading bool
+	Table   *pdf.Table
+}
+
+// Extract parses a DOCX, XLSX or PPTX file. Each paragraph becomes a document, table rows
+// are rendered as "row: column: value" contexts. Document.Page is the page number for Word
+// documents (based on page breaks), the slide number for presentations and the sheet number
+// for workbooks. Headings and slide titles are recorded as Document.Section of the blocks
+// following them, sheet names are used as sections of spreadsheet rows.
+func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.ReadSeeker) ([]ragserver.Document, error) {
+	data, err := io.ReadAll(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("opening zip archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var blocks []block
+	switch {
+	case files["word/document.xml"] != nil:
+		blocks, err = parseDocx(files)
+	case files["xl/workbook.xml"] != nil:
+		blocks, err = parseXlsx(files)
+	case files["ppt/presentation.xml"] != nil:
+		blocks, err = parsePptx(files)
+	default:
+		return nil, fmt.Errorf("unsupported office document: %s", fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		documents = make([]ragserver.Document, 0, len(blocks))
+		section   string
+	)
+
+	addDocument := func(content string, page int, section, layoutType string) {
+		content = strings.TrimSpace(content)
+		if content == "" {
+			return
+		}
+
+		documents = append(documents, ragserver.Document{
+			Content:    content,
+			Page:       page,
+			Section:    section,
+			LayoutType: layoutType,
+		})
+	}
+
+	for _, aBlock := range blocks {
+		if aBlock.Table != nil {
+			tableSection := section
+			if aBlock.Table.Title != "" {
+				tableSection = aBlock.Table.Title
+			}
+			for _, aContext := range aBlock.Table.ToContexts() {
+				addDocument(aContext, aBlock.Page, tableSection, ragserver.LayoutTypeTable)
+			}
+			continue
+		}
+
+		if aBlock.Heading {
+			section = strings.TrimSpace(aBlock.Text)
+			continue
+		}
+
+		addDocument(aBlock.Text, aBlock.Page, section, "")
+	}
+
+	a.logger.Sugar().Infof("number of documents: %d", len(documents))
+
+	return documents, nil
+}
+
+func readXML(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("missing %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return nil
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// readRelationships returns a map of relationship IDs to part names resolved relative to dir.
+func readRelationships(files map[string]*zip.File, dir, name string) (map[string]string, error) {
+	rels := relationships{}
+	if err := readXML(files, name, &rels); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join(dir, rel.Target)
+		}
+	}
+	return targets, nil
+}
+
+// normalizeTable pads rows to the same number of columns and drops empty rows so the table
+// can be safely rendered with ToContexts.
+func normalizeTable(title string, rows [][]string) *pdf.Table {
+	width := 0
+	for _, aRow := range rows {
+		width = max(width, len(aRow))
+	}
+
+	aTable := &pdf.Table{Title: title}
+	for _, aRow := range rows {
+		empty := true
+		for _, cell := range aRow {
+			if strings.TrimSpace(cell) != "" {
+				empty = false
+				break
+			}
+		}
+		if empty {
+			continue
+		}
+		padded := make(pdf.Row, width)
+		copy(padded, aRow)
+		aTable.Rows = append(aTable.Rows, padded)
+	}
+	return aTable
+}
+
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
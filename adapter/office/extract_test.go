@@ -0,0 +1,216 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+const (
+	testDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Emissions</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Our total scope 1 emissions were 100 tCO2e.</w:t></w:r><w:r><w:t xml:space="preserve"> This is a decrease.</w:t></w:r></w:p>
+    <w:p><w:r><w:br w:type="page"/></w:r><w:r><w:lastRenderedPageBreak/><w:t>We aim to reach net zero by 2050.</w:t></w:r></w:p>
+    <w:tbl>
+      <w:tr><w:tc><w:p><w:r><w:t>Scope</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>2022</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>2023</w:t></w:r></w:p></w:tc></w:tr>
+      <w:tr><w:tc><w:p><w:r><w:t>Scope 1</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>100</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>90</w:t></w:r></w:p></w:tc></w:tr>
+    </w:tbl>
+    <w:p><w:r><w:lastRenderedPageBreak/><w:t>Interim target is set for 2030.</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+	testWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets>
+    <sheet name="Emissions" sheetId="1" r:id="rId1"/>
+    <sheet name="Hidden" sheetId="2" state="hidden" r:id="rId2"/>
+    <sheet name="Targets" sheetId="3" r:id="rId3"/>
+  </sheets>
+</workbook>`
+
+	testWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet3.xml"/>
+</Relationships>`
+
+	testSharedStrings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>Scope</t></si>
+  <si><t>Scope 1</t></si>
+  <si><r><t>Scope </t></r><r><t>2</t></r></si>
+</sst>`
+
+	testSheet1 = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1"><v>2022</v></c><c r="C1"><v>2023</v></c></row>
+    <row r="2"></row>
+    <row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3"><v>100</v></c><c r="C3"><v>90</v></c></row>
+    <row r="4"><c r="A4" t="s"><v>2</v></c><c r="C4"><v>50</v></c></row>
+  </sheetData>
+</worksheet>`
+
+	testSheet3 = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="inlineStr"><is><t>Target</t></is></c><c r="B1" t="inlineStr"><is><t>Year</t></is></c></row>
+    <row r="2"><c r="A2" t="inlineStr"><is><t>Net zero</t></is></c><c r="B2"><v>2050</v></c></row>
+  </sheetData>
+</worksheet>`
+
+	testPresentation = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <p:sldIdLst>
+    <p:sldId id="256" r:id="rId3"/>
+    <p:sldId id="257" r:id="rId2"/>
+  </p:sldIdLst>
+</p:presentation>`
+
+	testPresentationRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide2.xml"/>
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>
+</Relationships>`
+
+	testSlide1 = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+  <p:cSld><p:spTree><p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody>
+    <a:p><a:r><a:t>Climate</a:t></a:r></a:p>
+  </p:txBody></p:sp><p:sp><p:txBody>
+    <a:p><a:r><a:t>Our total scope 1 emissions</a:t></a:r><a:br/><a:r><a:t>were 100 tCO2e.</a:t></a:r></a:p>
+  </p:txBody></p:sp></p:spTree></p:cSld>
+</p:sld>`
+
+	testSlide2 = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+  <p:cSld><p:spTree><p:graphicFrame><a:graphic><a:graphicData><a:tbl>
+    <a:tr><a:tc><a:txBody><a:p><a:r><a:t>Target</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>Year</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+    <a:tr><a:tc><a:txBody><a:p><a:r><a:t>Net zero</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>2050</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+  </a:tbl></a:graphicData></a:graphic></p:graphicFrame></p:spTree></p:cSld>
+</p:sld>`
+)
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	adapter := New()
+
+	t.Run("Word document", func(t *testing.T) {
+		data := testZip(t, map[string]string{
+			"word/document.xml": testDocument,
+		})
+
+		documents, err := adapter.Extract(context.Background(), "report.docx", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Our total scope 1 emissions were 100 tCO2e. This is a decrease.", Page: 1, Section: "Emissions"},
+			{Content: "We aim to reach net zero by 2050.", Page: 2, Section: "Emissions"},
+			{Content: "Scope 1: For year 2022: 100, For year 2023: 90", Page: 2, Section: "Emissions", LayoutType: ragserver.LayoutTypeTable},
+			{Content: "Interim target is set for 2030.", Page: 3, Section: "Emissions"},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Excel workbook", func(t *testing.T) {
+		data := testZip(t, map[string]string{
+			"xl/workbook.xml":            testWorkbook,
+			"xl/_rels/workbook.xml.rels": testWorkbookRels,
+			"xl/sharedStrings.xml":       testSharedStrings,
+			"xl/worksheets/sheet1.xml":   testSheet1,
+			"xl/worksheets/sheet2.xml":   testSheet1,
+			"xl/worksheets/sheet3.xml":   testSheet3,
+		})
+
+		documents, err := adapter.Extract(context.Background(), "report.xlsx", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Scope 1: For year 2022: 100, For year 2023: 90", Page: 1, Section: "Emissions", LayoutType: ragserver.LayoutTypeTable},
+			{Content: "Scope 2: For year 2023: 50", Page: 1, Section: "Emissions", LayoutType: ragserver.LayoutTypeTable},
+			{Content: "Net zero: Year: 2050", Page: 3, Section: "Targets", LayoutType: ragserver.LayoutTypeTable},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("PowerPoint presentation", func(t *testing.T) {
+		data := testZip(t, map[string]string{
+			"ppt/presentation.xml":            testPresentation,
+			"ppt/_rels/presentation.xml.rels": testPresentationRels,
+			"ppt/slides/slide1.xml":           testSlide1,
+			"ppt/slides/slide2.xml":           testSlide2,
+		})
+
+		documents, err := adapter.Extract(context.Background(), "report.pptx", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Our total scope 1 emissions were 100 tCO2e.", Page: 1, Section: "Climate"},
+			{Content: "Net zero: Year: 2050", Page: 2, Section: "Climate", LayoutType: ragserver.LayoutTypeTable},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Excel cell beyond the last column", func(t *testing.T) {
+		data := testZip(t, map[string]string{
+			"xl/workbook.xml":            testWorkbook,
+			"xl/_rels/workbook.xml.rels": testWorkbookRels,
+			"xl/sharedStrings.xml":       testSharedStrings,
+			"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="ZZZZZZZ1"><v>2022</v></c></row>
+  </sheetData>
+</worksheet>`,
+			"xl/worksheets/sheet2.xml": testSheet1,
+			"xl/worksheets/sheet3.xml": testSheet3,
+		})
+
+		_, err := adapter.Extract(context.Background(), "report.xlsx", bytes.NewReader(data))
+		require.ErrorContains(t, err, `cell "ZZZZZZZ1" of sheet Emissions is beyond the last column XFD`)
+	})
+
+	t.Run("Unsupported archive", func(t *testing.T) {
+		data := testZip(t, map[string]string{
+			"foo.txt": "bar",
+		})
+
+		_, err := adapter.Extract(context.Background(), "foo.zip", bytes.NewReader(data))
+		require.Error(t, err)
+	})
+}
+
+func TestColumnIndex(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, columnIndex("A1"))
+	assert.Equal(t, 25, columnIndex("Z10"))
+	assert.Equal(t, 27, columnIndex("AB12"))
+	assert.Equal(t, -1, columnIndex("12"))
+	assert.Equal(t, 16383, columnIndex("XFD1"))
+	assert.Equal(t, maxColumns, columnIndex("XFE1"))
+	assert.Equal(t, maxColumns, columnIndex("ZZZZZZZZZZZZZZZZZZZZ1"))
+}
+
+func testZip(t *testing.T, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for name, contents := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
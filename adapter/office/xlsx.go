@@ -0,0 +1,157 @@
+package office
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name  string `xml:"name,attr"`
+		State string `xml:"state,attr"`
+		RID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.Text
+	}
+	var b strings.Builder
+	for _, aRun := range rt.Runs {
+		b.WriteString(aRun.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// parseXlsx returns one table per visible sheet, using the first non-empty row as a header.
+func parseXlsx(files map[string]*zip.File) ([]block, error) {
+	workbook := xlsxWorkbook{}
+	if err := readXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+
+	targets, err := readRelationships(files, "xl", "xl/_rels/workbook.xml.rels")
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		sst := xlsxSharedStrings{}
+		if err := readXML(files, "xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		sharedStrings = make([]string, 0, len(sst.Items))
+		for _, item := range sst.Items {
+			sharedStrings = append(sharedStrings, item.String())
+		}
+	}
+
+	var blocks []block
+	for i, aSheet := range workbook.Sheets {
+		if aSheet.State == "hidden" || aSheet.State == "veryHidden" {
+			continue
+		}
+
+		name, ok := targets[aSheet.RID]
+		if !ok {
+			return nil, fmt.Errorf("missing relationship for sheet %s", aSheet.Name)
+		}
+
+		worksheet := xlsxWorksheet{}
+		if err := readXML(files, name, &worksheet); err != nil {
+			return nil, err
+		}
+
+		rows := make([][]string, 0, len(worksheet.Rows))
+		for _, aRow := range worksheet.Rows {
+			var cells []string
+			for j, aCell := range aRow.Cells {
+				column := columnIndex(aCell.Ref)
+				if column < 0 {
+					column = j
+				}
+				if column >= maxColumns {
+					return nil, fmt.Errorf("cell %q of sheet %s is beyond the last column XFD", aCell.Ref, aSheet.Name)
+				}
+
+				var value string
+				switch aCell.Type {
+				case "s":
+					var idx int
+					if _, err := fmt.Sscanf(aCell.Value, "%d", &idx); err == nil && idx >= 0 && idx < len(sharedStrings) {
+						value = sharedStrings[idx]
+					}
+				case "inlineStr":
+					value = aCell.Inline.String()
+				case "b":
+					value = "FALSE"
+					if aCell.Value == "1" {
+						value = "TRUE"
+					}
+				case "e":
+					// Skip formula errors such as #DIV/0!
+				default:
+					value = aCell.Value
+				}
+
+				for len(cells) <= column {
+					cells = append(cells, "")
+				}
+				cells[column] = collapseSpaces(value)
+			}
+			rows = append(rows, cells)
+		}
+
+		blocks = append(blocks, block{
+			Page:  i + 1,
+			Table: normalizeTable(aSheet.Name, rows),
+		})
+	}
+
+	return blocks, nil
+}
+
+// Excel sheets have at most 16384 columns, the last one is XFD
+const maxColumns = 16384
+
+// columnIndex converts a cell reference such as "AB12" to a zero based column index,
+// it returns -1 if the reference has no column part. Columns past XFD are returned as
+// maxColumns so long references can't overflow.
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		if index > maxColumns {
+			return maxColumns
+		}
+	}
+	return index - 1
+}
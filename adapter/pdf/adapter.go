@@ -5,14 +5,12 @@ import (
 	"time"
 
 	"go.uber.org/zap"
-
-	"github.com/neurosnap/sentences"
 )
 
 type Adapter struct {
 	httpClient *http.Client
 	baseURL    string
-	training   *sentences.Storage
+	tables     bool
 	logger     *zap.Logger
 }
 
@@ -36,11 +34,19 @@ func WithHttpClient(client *http.Client) Option {
 	}
 }
 
-func New(training *sentences.Storage, options ...Option) *Adapter {
+// WithTables enables or disables table extraction. When enabled (default), tables
+// are extracted separately and each table row becomes a single document.
+func WithTables(enabled bool) Option {
+	return func(a *Adapter) {
+		a.tables = enabled
+	}
+}
+
+func New(options ...Option) *Adapter {
 	a := &Adapter{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		baseURL:    "http://pdf-document-layout-analysis:5060",
-		training:   training,
+		tables:     true,
 		logger:     zap.NewNop(),
 	}
 
@@ -50,6 +56,7 @@ func New(training *sentences.Storage, options ...Option) *Adapter {
 
 	a.logger.Sugar().With(
 		"base URL", a.baseURL,
+		"tables", a.tables,
 	).Info("init pdf adapter")
 
 	return a
@@ -5,13 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"mime/multipart"
 	"net/http"
+	"slices"
 	"strings"
 
-	"github.com/neurosnap/sentences"
-
 	"github.com/RichardKnop/ragserver"
 )
 
@@ -27,55 +28,118 @@ type item struct {
 	Type       string  `json:"type"`
 }
 
+// boundingBox returns nil if the layout service didn't return the page size.
+func (i item) boundingBox() *ragserver.BoundingBox {
+	if i.PageWidth == 0 || i.PageHeight == 0 {
+		return nil
+	}
+	return &ragserver.BoundingBox{
+		Left:       i.Left,
+		Top:        i.Top,
+		Width:      i.Width,
+		Height:     i.Height,
+		PageWidth:  i.PageWidth,
+		PageHeight: i.PageHeight,
+	}
+}
+
 //	curl -X POST \
 //	  -F 'file=@/Users/richardknop/Desktop/Statement on Emissions.pdf' \
 //	  -F 'fast=true' \
 //	  -F 'types=all' \
 //	  http://localhost:5060
-func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.ReadSeeker, topics ragserver.RelevantTopics) ([]ragserver.Document, error) {
+func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.ReadSeeker) ([]ragserver.Document, error) {
 	items, err := a.extractItems(ctx, fileName, contents)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO - extract tables too
-
 	var (
-		// Create the default sentence tokenizer
-		tokenizer  = sentences.NewSentenceTokenizer(a.training)
-		documents  = make([]ragserver.Document, 0, 100)
-		topicCount = map[string]int{}
+		documents = make([]ragserver.Document, 0, 100)
+		// Tables matched to table items, their rows replace text of the table item
+		itemTables = map[int][]Table{}
+		// Tables which could not be matched to any table item, keyed by page of the nearest item
+		unmatchedTables = map[int][]Table{}
 	)
 
-	for _, anItem := range items {
-		if anItem.Type != "Text" && anItem.Type != "Footnote" && anItem.Type != "List item" && anItem.Type != "Table" {
-			continue
+	if a.tables {
+		// Reset the contents offset to the beginning for the second request
+		if _, err := contents.Seek(0, io.SeekStart); err != nil {
+			return nil, err
 		}
 
-		for _, aSentence := range tokenizer.Tokenize(anItem.Text) {
-			if len(topics) > 0 {
-				aTopic, ok := topics.IsRelevant(aSentence.Text)
-				if !ok {
-					continue
-				}
-				if aTopic.Name != "" {
-					_, ok := topicCount[aTopic.Name]
-					if !ok {
-						topicCount[aTopic.Name] = 0
-					}
-					topicCount[aTopic.Name] += 1
-				}
+		// Table items are extracted as text too, so documents are only less structured without tables
+		tables, err := a.extractHTMLTables(ctx, fileName, contents)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("extracting tables: %w", err)
 			}
+			a.logger.Sugar().With("file_name", fileName, "error", err).Warn("error extracting tables, using text of table items instead")
+			tables = nil
+		}
+
+		for _, aTable := range tables {
+			if idx, ok := matchTableItem(aTable, items); ok {
+				itemTables[idx] = append(itemTables[idx], aTable)
+				continue
+			}
+			page, ok := nearestItemPage(aTable, items)
+			if !ok {
+				a.logger.Sugar().With("file_name", fileName, "title", aTable.Title).Warn("dropping table not found in any layout item")
+				continue
+			}
+			unmatchedTables[page] = append(unmatchedTables[page], aTable)
+		}
+	}
 
+	// Rows of a table share the bounding box of the table item, it is nil for unmatched tables
+	addTableRows := func(aTable Table, page int, section string, box *ragserver.BoundingBox) {
+		// Title often says what the table is about, so it is a better section than the heading
+		if aTable.Title != "" {
+			section = aTable.Title
+		}
+		for _, aContext := range aTable.ToContexts() {
 			documents = append(documents, ragserver.Document{
-				Content: strings.TrimSpace(aSentence.Text),
-				Page:    anItem.PageNumber,
+				Content:     strings.TrimSpace(aContext),
+				Page:        page,
+				Section:     section,
+				LayoutType:  ragserver.LayoutTypeTable,
+				BoundingBox: box,
 			})
 		}
 	}
 
-	for name, count := range topicCount {
-		a.logger.Sugar().Infof("%s relevant sentences: %d", name, count)
+	var section string
+	for i, anItem := range items {
+		switch anItem.Type {
+		case "Title", "Section header":
+			section = strings.TrimSpace(anItem.Text)
+			continue
+		case "Text", "Footnote", "List item", "Table":
+		default:
+			continue
+		}
+
+		if tables, ok := itemTables[i]; ok {
+			for _, aTable := range tables {
+				addTableRows(aTable, anItem.PageNumber, section, anItem.boundingBox())
+			}
+			continue
+		}
+
+		documents = append(documents, ragserver.Document{
+			Content:     strings.TrimSpace(anItem.Text),
+			Page:        anItem.PageNumber,
+			Section:     section,
+			LayoutType:  anItem.Type,
+			BoundingBox: anItem.boundingBox(),
+		})
+	}
+
+	for _, page := range slices.Sorted(maps.Keys(unmatchedTables)) {
+		for _, aTable := range unmatchedTables[page] {
+			addTableRows(aTable, page, "", nil)
+		}
 	}
 
 	a.logger.Sugar().Infof("number of documents: %d", len(documents))
@@ -83,6 +147,74 @@ func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.Read
 	return documents, nil
 }
 
+// matchTableItem finds the item of type Table the table was extracted from. HTML tables do not
+// contain page numbers, so we pick the table item whose text contains most of the table cells.
+// Several tables can match the same item as parseTables splits tables on empty rows.
+func matchTableItem(aTable Table, items []item) (int, bool) {
+	cells := tableCells(aTable)
+	bestIdx, bestScore := bestMatchingItem(cells, items, func(anItem item) bool {
+		return anItem.Type == "Table"
+	})
+
+	// Require at least half of the cells to be found to avoid matching unrelated tables
+	if bestIdx < 0 || bestScore*2 < len(cells) {
+		return 0, false
+	}
+
+	return bestIdx, true
+}
+
+// nearestItemPage returns the page of the item of any type whose text contains most of the cells
+// of a table which didn't match a table item, layout analysis sometimes detects tables as text.
+func nearestItemPage(aTable Table, items []item) (int, bool) {
+	bestIdx, _ := bestMatchingItem(tableCells(aTable), items, func(item) bool { return true })
+	if bestIdx < 0 {
+		return 0, false
+	}
+	return items[bestIdx].PageNumber, true
+}
+
+func tableCells(aTable Table) []string {
+	var cells []string
+	for _, aRow := range aTable.Rows {
+		for _, aCell := range aRow {
+			if aCell = normalizeText(aCell); aCell != "" {
+				cells = append(cells, aCell)
+			}
+		}
+	}
+	return cells
+}
+
+// bestMatchingItem returns the index of the item whose text contains most of the cells and the
+// number of cells it contains, -1 if no item contains any.
+func bestMatchingItem(cells []string, items []item, include func(item) bool) (int, int) {
+	var (
+		bestIdx   = -1
+		bestScore = 0
+	)
+	for i, anItem := range items {
+		if !include(anItem) {
+			continue
+		}
+		text := normalizeText(anItem.Text)
+		score := 0
+		for _, aCell := range cells {
+			if strings.Contains(text, aCell) {
+				score += 1
+			}
+		}
+		if score > bestScore {
+			bestIdx, bestScore = i, score
+		}
+	}
+	return bestIdx, bestScore
+}
+
+func normalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
 func (a *Adapter) extractItems(ctx context.Context, fileName string, contents io.ReadSeeker) ([]item, error) {
 	buf := new(bytes.Buffer)
 	writer := multipart.NewWriter(buf)
@@ -100,7 +232,13 @@ func (a *Adapter) extractItems(ctx context.Context, fileName string, contents io
 	if err := writer.WriteField("fast", "true"); err != nil {
 		return nil, err
 	}
-	if err := writer.WriteField("types", "text,list item"); err != nil {
+	// Titles and section headers are used as sections of the following items
+	types := "title,section header,text,list item"
+	if a.tables {
+		// Table items are used to find page numbers of tables extracted as HTML
+		types += ",table"
+	}
+	if err := writer.WriteField("types", types); err != nil {
 		return nil, err
 	}
 	if err := writer.Close(); err != nil {
@@ -124,7 +262,7 @@ func (a *Adapter) extractItems(ctx context.Context, fileName string, contents io
 		return nil, err
 	}
 	if resp.StatusCode >= 400 {
-		return nil, errors.New(string(respData))
+		return nil, responseError(resp.StatusCode, string(respData))
 	}
 
 	items := []item{}
@@ -175,8 +313,18 @@ func (a *Adapter) extractHTMLTables(ctx context.Context, fileName string, conten
 		if err != nil {
 			return nil, err
 		}
-		return nil, errors.New(string(respData))
+		return nil, responseError(resp.StatusCode, string(respData))
 	}
 
 	return parseTables(a.logger, resp.Body)
 }
+
+// responseError returns an error for a failed response of the layout analysis service,
+// server errors are retryable as the service may be restarting or overloaded.
+func responseError(code int, body string) error {
+	err := errors.New(body)
+	if ragserver.IsRetryableStatusCode(code) {
+		return ragserver.Retryable(err)
+	}
+	return err
+}
@@ -8,7 +8,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/neurosnap/sentences"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -20,10 +19,26 @@ func TestExtract(t *testing.T) {
 
 	items := []item{
 		{
+			PageNumber: 2,
+			Text:       "Climate",
+			Type:       "Section header",
+		},
+		{
+			Left:       72,
+			Top:        100.5,
+			Width:      451,
+			Height:     36,
 			PageNumber: 3,
+			PageWidth:  595,
+			PageHeight: 842,
 			Text:       "foo",
 			Type:       "Text",
 		},
+		{
+			PageNumber: 4,
+			Text:       "Page 4",
+			Type:       "Page footer",
+		},
 		{
 			PageNumber: 5,
 			Text:       "bar",
@@ -31,14 +46,13 @@ func TestExtract(t *testing.T) {
 		},
 	}
 
-	training, err := sentences.LoadTraining([]byte(ragserver.TestEn))
-	require.NoError(t, err)
-
+	var types string
 	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/html" {
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("<html></html>"))
 		} else {
+			types = r.FormValue("types")
 			data, _ := json.Marshal(items)
 			w.WriteHeader(http.StatusOK)
 			w.Write(data)
@@ -46,37 +60,208 @@ func TestExtract(t *testing.T) {
 	}))
 	defer svr.Close()
 
-	adapter := New(training, WithBaseURL(svr.URL))
+	adapter := New(WithBaseURL(svr.URL))
+
+	documents, err := adapter.Extract(context.Background(), "test.pdf", bytes.NewReader([]byte("test")))
+	require.NoError(t, err)
 
-	t.Run("Without relevant topics", func(t *testing.T) {
-		documents, err := adapter.Extract(context.Background(), "test.pdf", bytes.NewReader([]byte("test")), ragserver.RelevantTopics{})
+	expected := []ragserver.Document{
+		{
+			Content:    "foo",
+			Page:       3,
+			Section:    "Climate",
+			LayoutType: "Text",
+			BoundingBox: &ragserver.BoundingBox{
+				Left:       72,
+				Top:        100.5,
+				Width:      451,
+				Height:     36,
+				PageWidth:  595,
+				PageHeight: 842,
+			},
+		},
+		{
+			Content:    "bar",
+			Page:       5,
+			Section:    "Climate",
+			LayoutType: "List item",
+		},
+	}
+	assert.Equal(t, expected, documents)
+	assert.Equal(t, "title,section header,text,list item,table", types)
+}
+
+func TestExtract_Tables(t *testing.T) {
+	t.Parallel()
+
+	items := []item{
+		{
+			PageNumber: 1,
+			Text:       "Our emissions are reported below.",
+			Type:       "Text",
+		},
+		{
+			Left:       50,
+			Top:        200,
+			Width:      500,
+			Height:     120,
+			PageNumber: 43,
+			PageWidth:  612,
+			PageHeight: 792,
+			Text:       "Emissions (MTCO2e) 2022 Total Scope 1 77,476 Total Scope 2 (location) 593,495",
+			Type:       "Table",
+		},
+		{
+			PageNumber: 50,
+			Text:       "Unrelated table.",
+			Type:       "Table",
+		},
+	}
+
+	tablesHTML := `<html><body><table>
+		<tr><td>Emissions (MTCO2e)</td><td>2022</td></tr>
+		<tr><td>Total Scope 1</td><td>77,476</td></tr>
+		<tr><td>Total Scope 2 (location)</td><td>593,495</td></tr>
+	</table></body></html>`
+
+	newServer := func(htmlCalls *int, types *string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/html" {
+				*htmlCalls += 1
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tablesHTML))
+				return
+			}
+			*types = r.FormValue("types")
+			data, _ := json.Marshal(items)
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+		}))
+	}
+
+	t.Run("Tables enabled", func(t *testing.T) {
+		var (
+			htmlCalls int
+			types     string
+		)
+		svr := newServer(&htmlCalls, &types)
+		defer svr.Close()
+
+		adapter := New(WithBaseURL(svr.URL))
+
+		documents, err := adapter.Extract(context.Background(), "test.pdf", bytes.NewReader([]byte("test")))
 		require.NoError(t, err)
 
+		tableBox := &ragserver.BoundingBox{
+			Left:       50,
+			Top:        200,
+			Width:      500,
+			Height:     120,
+			PageWidth:  612,
+			PageHeight: 792,
+		}
 		expected := []ragserver.Document{
 			{
-				Content: "foo",
-				Page:    3,
+				Content:    "Our emissions are reported below.",
+				Page:       1,
+				LayoutType: "Text",
 			},
 			{
-				Content: "bar",
-				Page:    5,
+				Content:     "Total Scope 1: For year 2022: 77,476",
+				Page:        43,
+				LayoutType:  "Table",
+				BoundingBox: tableBox,
+			},
+			{
+				Content:     "Total Scope 2 (location): For year 2022: 593,495",
+				Page:        43,
+				LayoutType:  "Table",
+				BoundingBox: tableBox,
+			},
+			{
+				Content:    "Unrelated table.",
+				Page:       50,
+				LayoutType: "Table",
 			},
 		}
 		assert.Equal(t, expected, documents)
+		assert.Equal(t, 1, htmlCalls)
+		assert.Equal(t, "title,section header,text,list item,table", types)
 	})
 
-	t.Run("With relevant topics", func(t *testing.T) {
-		documents, err := adapter.Extract(context.Background(), "test.pdf", bytes.NewReader([]byte("test")), ragserver.RelevantTopics{
-			{Keywords: []string{"foo"}},
-		})
+	t.Run("Tables disabled", func(t *testing.T) {
+		var (
+			htmlCalls int
+			types     string
+		)
+		svr := newServer(&htmlCalls, &types)
+		defer svr.Close()
+
+		adapter := New(WithBaseURL(svr.URL), WithTables(false))
+
+		documents, err := adapter.Extract(context.Background(), "test.pdf", bytes.NewReader([]byte("test")))
 		require.NoError(t, err)
 
-		expected := []ragserver.Document{
-			{
-				Content: "foo",
-				Page:    3,
-			},
-		}
-		assert.Equal(t, expected, documents)
+		assert.Len(t, documents, 3)
+		assert.Equal(t, 0, htmlCalls)
+		assert.Equal(t, "title,section header,text,list item", types)
 	})
+
+	t.Run("Text of table items is used if tables fail", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/html" {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("internal server error"))
+				return
+			}
+			data, _ := json.Marshal(items)
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+		}))
+		defer svr.Close()
+
+		adapter := New(WithBaseURL(svr.URL))
+
+		documents, err := adapter.Extract(context.Background(), "test.pdf", bytes.NewReader([]byte("test")))
+		require.NoError(t, err)
+
+		require.Len(t, documents, 3)
+		assert.Equal(t, items[1].Text, documents[1].Content)
+		assert.Equal(t, 43, documents[1].Page)
+	})
+}
+
+func TestMatchTableItem(t *testing.T) {
+	t.Parallel()
+
+	items := []item{
+		{Type: "Text", Text: "Total Scope 1 77,476"},
+		{Type: "Table", Text: "Total Scope 1 77,476 Total Scope 2 593,495", PageNumber: 5},
+	}
+
+	idx, ok := matchTableItem(Table{Rows: []Row{{"Total  Scope 1", "77,476"}, {"Total Scope 2", "593,495"}}}, items)
+	require.True(t, ok)
+	assert.Equal(t, 1, idx)
+
+	_, ok = matchTableItem(Table{Rows: []Row{{"Category 1", "1,300,698"}, {"Total Scope 2", "593,495"}, {"Category 2", "293,289"}}}, items)
+	assert.False(t, ok)
+
+	_, ok = matchTableItem(Table{}, items)
+	assert.False(t, ok)
+}
+
+func TestNearestItemPage(t *testing.T) {
+	t.Parallel()
+
+	items := []item{
+		{Type: "Text", Text: "Our emissions are reported below.", PageNumber: 4},
+		{Type: "Text", Text: "Category 1 1,300,698 Category 2 293,289", PageNumber: 5},
+	}
+
+	page, ok := nearestItemPage(Table{Rows: []Row{{"Category 1", "1,300,698"}, {"Category 3", "44,028"}}}, items)
+	require.True(t, ok)
+	assert.Equal(t, 5, page)
+
+	_, ok = nearestItemPage(Table{Rows: []Row{{"Total Scope 2", "593,495"}}}, items)
+	assert.False(t, ok)
 }
@@ -0,0 +1,33 @@
+package pdftext
+
+import (
+	"go.uber.org/zap"
+)
+
+// Adapter extracts documents from PDF files by parsing their content streams locally,
+// without calling any external service.
+type Adapter struct {
+	logger *zap.Logger
+}
+
+type Option func(*Adapter)
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(a *Adapter) {
+		a.logger = logger
+	}
+}
+
+func New(options ...Option) *Adapter {
+	a := &Adapter{
+		logger: zap.NewNop(),
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	a.logger.Sugar().Info("init pdftext adapter")
+
+	return a
+}
@@ -0,0 +1,382 @@
+package pdftext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ledongthuc/pdf"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+const (
+	// Glyphs whose baselines differ by less than this fraction of the font size are on the same line
+	lineTolerance = 0.5
+	// Horizontal gap between glyphs, as a fraction of the font size, which is treated as a space
+	spaceGap = 0.25
+	// Horizontal gap between glyphs, as a fraction of the font size, which can separate two columns
+	columnGap = 2.0
+	// Number of lines which must have a column gap at the same position for a page to be read in columns
+	minColumnLines = 3
+	// Text on both sides of a column gap must be at least this long, shorter cells are more likely a table row
+	minColumnLength = 20
+	// Vertical distance between baselines, as a fraction of the font size, which starts a new paragraph
+	paragraphGap = 1.6
+	// Lines with font size at least this many times larger than the body text are headings
+	headingRatio = 1.2
+	// Headings are short, longer text in a large font is more likely a pull quote
+	maxHeadingLength = 200
+)
+
+// line is a row of glyphs sharing the same baseline.
+type line struct {
+	glyphs []pdf.Text
+	y      float64
+	size   float64
+	text   string
+}
+
+// paragraph is a group of consecutive lines separated from neighbours by a larger vertical gap
+// or a change of font size.
+type paragraph struct {
+	lines []*line
+	size  float64
+}
+
+// Extract parses text objects in content streams of each page, groups glyphs into lines and lines
+// into paragraphs in top-to-bottom, left-to-right reading order. Pages with two columns are read
+// column by column, see splitColumns. Each paragraph becomes a document with its page number.
+// Short paragraphs in a font noticeably larger than the body text are treated as headings, they are
+// not returned as documents but recorded as Document.Section.
+//
+// The pdf package panics on malformed objects and content streams, so panics are turned into errors.
+func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.ReadSeeker) (_ []ragserver.Document, err error) {
+	var pageNum int
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if pageNum < 1 {
+			err = fmt.Errorf("malformed pdf: %v", r)
+			return
+		}
+		err = fmt.Errorf("malformed pdf at page %d: %v", pageNum, r)
+	}()
+
+	data, err := io.ReadAll(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("opening pdf: %w", err)
+	}
+
+	var (
+		numPages  = reader.NumPage()
+		documents = make([]ragserver.Document, 0, 100)
+		section   string
+	)
+
+	for pageNum = 1; pageNum <= numPages; pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		a.logger.Sugar().Infof("processing page %d/%d", pageNum, numPages)
+
+		page := reader.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+
+		glyphs := page.Content().Text
+		bodySize := dominantSize(glyphs)
+		for _, aParagraph := range groupParagraphs(splitColumns(groupLines(glyphs))) {
+			content := aParagraph.Text()
+			if content == "" {
+				continue
+			}
+
+			if aParagraph.size >= bodySize*headingRatio && len(content) <= maxHeadingLength {
+				section = content
+				continue
+			}
+
+			documents = append(documents, ragserver.Document{
+				Content: content,
+				Page:    pageNum,
+				Section: section,
+			})
+		}
+	}
+
+	a.logger.Sugar().Infof("number of documents: %d", len(documents))
+
+	return documents, nil
+}
+
+// dominantSize returns the font size used by most glyphs on the page, which is the body text size.
+func dominantSize(glyphs []pdf.Text) float64 {
+	var (
+		counts = map[float64]int{}
+		result float64
+	)
+	for _, aGlyph := range glyphs {
+		if strings.TrimSpace(aGlyph.S) == "" {
+			continue
+		}
+		size := math.Round(aGlyph.FontSize*10) / 10
+		counts[size] += 1
+		if counts[size] > counts[result] || counts[size] == counts[result] && size < result {
+			result = size
+		}
+	}
+	return result
+}
+
+// groupLines groups glyphs into lines by their baseline, lines are sorted top to bottom
+// and glyphs within a line left to right. Text of columns sharing a baseline ends up in
+// the same line, splitColumns separates them.
+func groupLines(glyphs []pdf.Text) []*line {
+	var lines []*line
+
+	for _, aGlyph := range glyphs {
+		// Line breaks are emitted after each TJ operator, positions are used instead
+		if aGlyph.S == "\n" || aGlyph.S == "\r" {
+			continue
+		}
+
+		var found *line
+		for _, aLine := range lines {
+			if math.Abs(aLine.y-aGlyph.Y) < lineTolerance*max(aLine.size, aGlyph.FontSize) {
+				found = aLine
+				break
+			}
+		}
+		if found == nil {
+			found = &line{y: aGlyph.Y}
+			lines = append(lines, found)
+		}
+		found.glyphs = append(found.glyphs, aGlyph)
+		found.size = max(found.size, aGlyph.FontSize)
+	}
+
+	// PDF coordinates increase from bottom to top
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].y > lines[j].y
+	})
+
+	for _, aLine := range lines {
+		sort.SliceStable(aLine.glyphs, func(i, j int) bool {
+			return aLine.glyphs[i].X < aLine.glyphs[j].X
+		})
+		aLine.text = lineText(aLine.glyphs)
+	}
+
+	return lines
+}
+
+// splitColumns reorders lines of pages with two columns so the left column is read before the right one.
+// Columns are separated by a gutter, a horizontal position where at least minColumnLines lines have
+// a wide gap with enough text on both sides. Lines crossing the gutter, such as paragraphs spanning
+// the whole page, are kept in place and columns are read between them. Pages with more columns are
+// only split at the gutter shared by most lines.
+func splitColumns(lines []*line) []*line {
+	gutter, ok := findGutter(lines)
+	if !ok {
+		return lines
+	}
+
+	var (
+		result      = make([]*line, 0, len(lines))
+		left, right []*line
+	)
+	for _, aLine := range lines {
+		var leftGlyphs, rightGlyphs []pdf.Text
+		crosses := false
+		for _, aGlyph := range aLine.glyphs {
+			switch {
+			case aGlyph.X+aGlyph.W <= gutter:
+				leftGlyphs = append(leftGlyphs, aGlyph)
+			case aGlyph.X >= gutter:
+				rightGlyphs = append(rightGlyphs, aGlyph)
+			case strings.TrimSpace(aGlyph.S) != "":
+				crosses = true
+			}
+		}
+
+		if crosses {
+			result = append(result, left...)
+			result = append(result, right...)
+			result = append(result, aLine)
+			left, right = nil, nil
+			continue
+		}
+		if len(leftGlyphs) > 0 {
+			left = append(left, newLine(aLine.y, leftGlyphs))
+		}
+		if len(rightGlyphs) > 0 {
+			right = append(right, newLine(aLine.y, rightGlyphs))
+		}
+	}
+	result = append(result, left...)
+	result = append(result, right...)
+
+	return result
+}
+
+// findGutter returns the horizontal position between two columns, if there is one.
+func findGutter(lines []*line) (float64, bool) {
+	var gaps [][2]float64
+	for _, aLine := range lines {
+		gaps = append(gaps, columnGaps(aLine)...)
+	}
+
+	var (
+		gutter float64
+		most   int
+	)
+	for _, candidate := range gaps {
+		x := (candidate[0] + candidate[1]) / 2
+		count := 0
+		for _, aGap := range gaps {
+			if aGap[0] <= x && x <= aGap[1] {
+				count += 1
+			}
+		}
+		if count > most {
+			gutter, most = x, count
+		}
+	}
+
+	return gutter, most >= minColumnLines
+}
+
+// columnGaps returns start and end of horizontal gaps in the line wide enough to separate columns,
+// with at least minColumnLength of text on both sides.
+func columnGaps(aLine *line) [][2]float64 {
+	var (
+		segments [][]pdf.Text
+		current  []pdf.Text
+		prev     *pdf.Text
+	)
+	for i, aGlyph := range aLine.glyphs {
+		if strings.TrimSpace(aGlyph.S) == "" {
+			continue
+		}
+		if prev != nil && aGlyph.X-(prev.X+prev.W) > columnGap*max(aGlyph.FontSize, prev.FontSize) {
+			segments = append(segments, current)
+			current = nil
+		}
+		current = append(current, aGlyph)
+		prev = &aLine.glyphs[i]
+	}
+	segments = append(segments, current)
+
+	var gaps [][2]float64
+	for i := 1; i < len(segments); i++ {
+		var (
+			before = segments[i-1]
+			after  = segments[i]
+		)
+		if len(lineText(before)) < minColumnLength || len(lineText(after)) < minColumnLength {
+			continue
+		}
+		last := before[len(before)-1]
+		gaps = append(gaps, [2]float64{last.X + last.W, after[0].X})
+	}
+	return gaps
+}
+
+func newLine(y float64, glyphs []pdf.Text) *line {
+	aLine := &line{glyphs: glyphs, y: y, text: lineText(glyphs)}
+	for _, aGlyph := range glyphs {
+		aLine.size = max(aLine.size, aGlyph.FontSize)
+	}
+	return aLine
+}
+
+// lineText joins glyphs of a line, inserting spaces where the horizontal gap between glyphs
+// is wide enough, as many PDFs position words individually instead of using space characters.
+func lineText(glyphs []pdf.Text) string {
+	var (
+		text  strings.Builder
+		prev  *pdf.Text
+		space bool
+	)
+	for i, aGlyph := range glyphs {
+		if strings.TrimSpace(aGlyph.S) == "" {
+			space = true
+			continue
+		}
+		if prev != nil && aGlyph.X-(prev.X+prev.W) > spaceGap*max(aGlyph.FontSize, prev.FontSize) {
+			space = true
+		}
+		if space && text.Len() > 0 {
+			text.WriteString(" ")
+		}
+		text.WriteString(aGlyph.S)
+		prev, space = &glyphs[i], false
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// groupParagraphs groups consecutive lines into paragraphs, a new paragraph is started when
+// the gap between baselines is large, the font size changes or the next line is higher up
+// the page, as the first line of the right column is.
+func groupParagraphs(lines []*line) []*paragraph {
+	var (
+		paragraphs []*paragraph
+		current    *paragraph
+		prev       *line
+	)
+
+	for _, aLine := range lines {
+		if aLine.text == "" {
+			continue
+		}
+
+		if current != nil {
+			var (
+				gap         = prev.y - aLine.y
+				sizeChanged = math.Abs(aLine.size-current.size) > 0.1*current.size
+			)
+			if gap < 0 || gap > paragraphGap*max(prev.size, aLine.size) || sizeChanged {
+				current = nil
+			}
+		}
+		if current == nil {
+			current = &paragraph{size: aLine.size}
+			paragraphs = append(paragraphs, current)
+		}
+		current.lines = append(current.lines, aLine)
+		prev = aLine
+	}
+
+	return paragraphs
+}
+
+// Text joins lines of the paragraph. Words broken by a hyphen at the end of a line are joined
+// without a space, the hyphen is kept as it can't be told apart from compounds such as net-zero.
+func (p *paragraph) Text() string {
+	var text strings.Builder
+	for i, aLine := range p.lines {
+		if i > 0 {
+			next := []rune(aLine.text)
+			if !strings.HasSuffix(p.lines[i-1].text, "-") || !unicode.IsLower(next[0]) {
+				text.WriteString(" ")
+			}
+		}
+		text.WriteString(aLine.text)
+	}
+	return strings.Join(strings.Fields(text.String()), " ")
+}
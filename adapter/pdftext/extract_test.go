@@ -0,0 +1,159 @@
+package pdftext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	adapter := New()
+
+	t.Run("Paragraphs, headings and pages", func(t *testing.T) {
+		data := testPDF(
+			`BT /F1 18 Tf 72 720 Td (Emissions) Tj ET
+			BT /F1 12 Tf 72 690 Td (Our total scope 1 emissions) Tj 0 -14 Td (were 100 tCO2e.) Tj ET
+			BT /F1 12 Tf 72 640 Td (This is a decrease from last year.) Tj ET`,
+			`BT /F1 12 Tf 72 720 Td (We aim to reach net-) Tj 0 -14 Td (zero by 2050.) Tj ET`,
+		)
+
+		documents, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Our total scope 1 emissions were 100 tCO2e.", Page: 1, Section: "Emissions"},
+			{Content: "This is a decrease from last year.", Page: 1, Section: "Emissions"},
+			{Content: "We aim to reach net-zero by 2050.", Page: 2, Section: "Emissions"},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Reading order follows positions", func(t *testing.T) {
+		data := testPDF(
+			`BT /F1 12 Tf 72 600 Td (Second paragraph.) Tj ET
+			BT /F1 12 Tf 200 700 Td (77,476) Tj ET
+			BT /F1 12 Tf 72 700 Td (Total Scope 1) Tj ET`,
+		)
+
+		documents, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Total Scope 1 77,476", Page: 1},
+			{Content: "Second paragraph.", Page: 1},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Two columns are read one after another", func(t *testing.T) {
+		data := testPDF(
+			`BT /F1 18 Tf 72 720 Td (Emissions) Tj ET
+			BT /F1 10 Tf 72 700 Td (Our total scope 1 emissions were) Tj 248 0 Td (Scope 2 emissions were reduced by) Tj ET
+			BT /F1 10 Tf 72 688 Td (100 tCO2e in the reporting year,) Tj 248 0 Td (half after switching to renewable) Tj ET
+			BT /F1 10 Tf 72 676 Td (a decrease from last year.) Tj 248 0 Td (electricity at all our offices.) Tj ET
+			BT /F1 10 Tf 72 640 Td (All figures were verified by an independent auditor in 2024.) Tj ET`,
+		)
+
+		documents, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Our total scope 1 emissions were 100 tCO2e in the reporting year, a decrease from last year.", Page: 1, Section: "Emissions"},
+			{Content: "Scope 2 emissions were reduced by half after switching to renewable electricity at all our offices.", Page: 1, Section: "Emissions"},
+			{Content: "All figures were verified by an independent auditor in 2024.", Page: 1, Section: "Emissions"},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Table rows are not read as columns", func(t *testing.T) {
+		data := testPDF(
+			`BT /F1 10 Tf 72 700 Td (Total Scope 1 emissions in tCO2e) Tj 248 0 Td (77,476) Tj ET
+			BT /F1 10 Tf 72 688 Td (Total Scope 2 emissions in tCO2e) Tj 248 0 Td (12,310) Tj ET
+			BT /F1 10 Tf 72 676 Td (Total Scope 3 emissions in tCO2e) Tj 248 0 Td (1,204) Tj ET`,
+		)
+
+		documents, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Total Scope 1 emissions in tCO2e 77,476 Total Scope 2 emissions in tCO2e 12,310 Total Scope 3 emissions in tCO2e 1,204", Page: 1},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("Empty page", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "empty.pdf", bytes.NewReader(testPDF("")))
+		require.NoError(t, err)
+		assert.Empty(t, documents)
+	})
+
+	t.Run("Malformed page object", func(t *testing.T) {
+		data := bytes.Replace(testPDF("BT /F1 12 Tf 72 720 Td (Text) Tj ET"), []byte("/Type /Page /Parent"), []byte("/Type ) Page /Parent"), 1)
+
+		_, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(data))
+		require.EqualError(t, err, "malformed pdf at page 1: unexpected delimiter ')'")
+	})
+
+	t.Run("Malformed content stream", func(t *testing.T) {
+		_, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(testPDF("BT /F1 12 Tf 72 720 Td Tj ET")))
+		require.EqualError(t, err, "malformed pdf at page 1: bad Tj operator")
+	})
+
+	t.Run("Malformed page tree", func(t *testing.T) {
+		data := bytes.Replace(testPDF("BT /F1 12 Tf 72 720 Td (Text) Tj ET"), []byte("/Kids [4 0 R]"), []byte("/Kids [4 0 R>"), 1)
+
+		_, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader(data))
+		require.EqualError(t, err, "malformed pdf: unexpected delimiter '>'")
+	})
+
+	t.Run("Not a PDF", func(t *testing.T) {
+		_, err := adapter.Extract(context.Background(), "report.pdf", bytes.NewReader([]byte("not a pdf")))
+		require.Error(t, err)
+	})
+}
+
+// testPDF builds a minimal PDF with a page per content stream. All pages use a single font
+// with a fixed glyph width of half the font size.
+func testPDF(pages ...string) []byte {
+	var (
+		buf     = new(bytes.Buffer)
+		offsets []int
+		kids    = make([]string, 0, len(pages))
+	)
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+i*2))
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding /FirstChar 32 /LastChar 126 /Widths [" + strings.Repeat("500 ", 95) + "] >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
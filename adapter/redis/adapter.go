@@ -3,9 +3,12 @@ package redis
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+
+	"github.com/RichardKnop/ragserver"
 )
 
 type Adapter struct {
@@ -15,6 +18,8 @@ type Adapter struct {
 	dialectVersion       int
 	vectorDim            int
 	vectorDistanceMetric string
+	metadataFields       []ragserver.MetadataField
+	embeddingCacheTTL    time.Duration
 	logger               *zap.Logger
 }
 
@@ -50,6 +55,21 @@ func WithVectorDistanceMetric(metric string) Option {
 	}
 }
 
+// WithMetadataFields indexes metadata copied to documents so they can be filtered by it, tag fields
+// are matched by exact values and numeric fields by ranges. Use the same fields as ragserver.WithIndexedMetadata.
+func WithMetadataFields(fields ...ragserver.MetadataField) Option {
+	return func(a *Adapter) {
+		a.metadataFields = fields
+	}
+}
+
+// WithEmbeddingCacheTTL expires cached embeddings after the TTL, they are kept until evicted by default.
+func WithEmbeddingCacheTTL(ttl time.Duration) Option {
+	return func(a *Adapter) {
+		a.embeddingCacheTTL = ttl
+	}
+}
+
 func WithLogger(logger *zap.Logger) Option {
 	return func(a *Adapter) {
 		a.logger = logger
@@ -92,6 +112,7 @@ func New(ctx context.Context, client *redis.Client, options ...Option) (*Adapter
 		"dialect version", a.dialectVersion,
 		"vector dim", a.vectorDim,
 		"vector distance metric", a.vectorDistanceMetric,
+		"metadata fields", a.metadataFields,
 	).Info("init redis adapter")
 
 	return a, a.init(ctx)
@@ -115,7 +136,7 @@ func (a *Adapter) init(ctx context.Context) error {
 	for _, existingIndex := range indexes {
 		if existingIndex == a.indexName {
 			a.logger.Sugar().Infof("redis index already exists: %s", a.indexName)
-			return nil
+			return a.alterIndex(ctx)
 		}
 	}
 	return a.createIndex(ctx)
@@ -138,35 +159,40 @@ func (a *Adapter) dropIndex(ctx context.Context) error {
 func (a *Adapter) createIndex(ctx context.Context) error {
 	// Read the documentation to choose the right options:
 	// https://redis.io/docs/latest/develop/ai/search-and-query/vectors/
-	_, err := a.client.FTCreate(ctx,
-		a.indexName,
-		&redis.FTCreateOptions{
-			OnHash: true,
-			Prefix: []any{a.indexPrefix},
-		},
-		&redis.FieldSchema{
+	schema := []*redis.FieldSchema{
+		{
 			FieldName: "content",
 			FieldType: redis.SearchFieldTypeText,
 		},
-		&redis.FieldSchema{
+		{
 			FieldName: "file_id",
 			FieldType: redis.SearchFieldTypeTag,
 		},
-		&redis.FieldSchema{
+		{
 			FieldName: "page",
 			FieldType: redis.SearchFieldTypeTag,
 		},
-		&redis.FieldSchema{
-			FieldName: "embedding",
-			FieldType: redis.SearchFieldTypeVector,
-			VectorArgs: &redis.FTVectorArgs{
-				HNSWOptions: &redis.FTHNSWOptions{
-					Dim:            a.vectorDim,
-					DistanceMetric: a.vectorDistanceMetric,
-					Type:           "FLOAT32",
-				},
+	}
+	schema = append(schema, a.addedFields()...)
+	schema = append(schema, &redis.FieldSchema{
+		FieldName: "embedding",
+		FieldType: redis.SearchFieldTypeVector,
+		VectorArgs: &redis.FTVectorArgs{
+			HNSWOptions: &redis.FTHNSWOptions{
+				Dim:            a.vectorDim,
+				DistanceMetric: a.vectorDistanceMetric,
+				Type:           "FLOAT32",
 			},
 		},
+	})
+
+	_, err := a.client.FTCreate(ctx,
+		a.indexName,
+		&redis.FTCreateOptions{
+			OnHash: true,
+			Prefix: []any{a.indexPrefix},
+		},
+		schema...,
 	).Result()
 	if err != nil {
 		return fmt.Errorf("error creating redis index: %v", err)
@@ -174,3 +200,52 @@ func (a *Adapter) createIndex(ctx context.Context) error {
 	a.logger.Sugar().Infof("created redis index: %s", a.indexName)
 	return nil
 }
+
+// addedFields are fields which can be missing from an existing index, either introduced after
+// the index was created or configured metadata fields.
+func (a *Adapter) addedFields() []*redis.FieldSchema {
+	fields := []*redis.FieldSchema{
+		{
+			FieldName: "language",
+			FieldType: redis.SearchFieldTypeTag,
+		},
+	}
+	for _, field := range a.metadataFields {
+		fields = append(fields, metadataFieldSchema(field))
+	}
+	return fields
+}
+
+// alterIndex adds fields missing from the index, documents saved before
+// are indexed again in the background.
+func (a *Adapter) alterIndex(ctx context.Context) error {
+	info, err := a.client.FTInfo(ctx, a.indexName).Result()
+	if err != nil {
+		return fmt.Errorf("error reading redis index: %v", err)
+	}
+	existing := map[string]bool{}
+	for _, anAttribute := range info.Attributes {
+		existing[anAttribute.Attribute] = true
+	}
+
+	for _, field := range a.addedFields() {
+		if existing[field.FieldName] {
+			continue
+		}
+
+		definition := []any{field.FieldName, field.FieldType.String()}
+		if field.Separator != "" {
+			definition = append(definition, "SEPARATOR", field.Separator)
+		}
+		if field.CaseSensitive {
+			definition = append(definition, "CASESENSITIVE")
+		}
+
+		if _, err := a.client.FTAlter(ctx, a.indexName, false, definition).Result(); err != nil {
+			return fmt.Errorf("error altering redis index: %v", err)
+		}
+		a.logger.Sugar().Infof("added %s field to redis index: %s", field.FieldName, a.indexName)
+	}
+
+	return nil
+}
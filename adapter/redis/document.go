@@ -3,6 +3,8 @@ package redis
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -20,16 +22,28 @@ func (a *Adapter) SaveDocuments(ctx context.Context, documents []ragserver.Docum
 	}
 
 	for i, vector := range vectors {
+		values := map[string]any{
+			"content":     documents[i].Content,
+			"file_id":     documents[i].FileID.String(),
+			"page":        documents[i].Page,
+			"section":     documents[i].Section,
+			"language":    string(documents[i].Language),
+			"layout_type": documents[i].LayoutType,
+			"embedding":   floatsToBytes(vector),
+		}
+		if documents[i].BoundingBox != nil {
+			box, err := json.Marshal(documents[i].BoundingBox)
+			if err != nil {
+				return fmt.Errorf("error encoding bounding box: %w", err)
+			}
+			values["bounding_box"] = string(box)
+		}
+		for key, value := range documents[i].Metadata {
+			values[metadataFieldName(key)] = value
+		}
+
 		key := fmt.Sprintf("doc:%v", uuid.Must(uuid.NewV4()))
-		fields, err := a.client.HSet(ctx,
-			key,
-			map[string]any{
-				"content":   documents[i].Content,
-				"file_id":   documents[i].FileID.String(),
-				"page":      documents[i].Page,
-				"embedding": floatsToBytes(vector),
-			},
-		).Result()
+		fields, err := a.client.HSet(ctx, key, values).Result()
 		if fields == 0 {
 			return fmt.Errorf("no fields were added to redis")
 		}
@@ -49,11 +63,15 @@ func (a *Adapter) ListFileDocuments(ctx context.Context, id ragserver.FileID, li
 		a.indexName,
 		query,
 		&redis.FTSearchOptions{
-			Return: []redis.FTSearchReturn{
+			Return: append([]redis.FTSearchReturn{
 				{FieldName: "content"},
 				{FieldName: "file_id"},
 				{FieldName: "page"},
-			},
+				{FieldName: "section"},
+				{FieldName: "language"},
+				{FieldName: "layout_type"},
+				{FieldName: "bounding_box"},
+			}, a.metadataReturnFields()...),
 			DialectVersion: a.dialectVersion,
 			Limit:          limit,
 		},
@@ -83,8 +101,13 @@ func (a *Adapter) SearchDocuments(ctx context.Context, filter ragserver.Document
 	var query string
 	if fileIDFilter != "" {
 		query += fmt.Sprintf("(@file_id:{%s})", fileIDFilter)
-	} else {
-		query += "*"
+	}
+	if filter.Language != "" {
+		query += fmt.Sprintf("(@language:{%s})", filter.Language)
+	}
+	query += metadataQuery(filter.Metadata)
+	if query == "" {
+		query = "*"
 	}
 	query += fmt.Sprintf("=>[KNN %d @embedding $vec AS vector_distance]", limit)
 
@@ -94,12 +117,16 @@ func (a *Adapter) SearchDocuments(ctx context.Context, filter ragserver.Document
 		a.indexName,
 		query,
 		&redis.FTSearchOptions{
-			Return: []redis.FTSearchReturn{
+			Return: append([]redis.FTSearchReturn{
 				{FieldName: "vector_distance"},
 				{FieldName: "content"},
 				{FieldName: "file_id"},
 				{FieldName: "page"},
-			},
+				{FieldName: "section"},
+				{FieldName: "language"},
+				{FieldName: "layout_type"},
+				{FieldName: "bounding_box"},
+			}, a.metadataReturnFields()...),
 			DialectVersion: a.dialectVersion,
 			Params: map[string]any{
 				"vec": floatsToBytes(filter.Vector),
@@ -125,30 +152,117 @@ func (a *Adapter) SearchDocuments(ctx context.Context, filter ragserver.Document
 func (a *Adapter) DeleteFileDocuments(ctx context.Context, id ragserver.FileID) error {
 	query := fmt.Sprintf("@file_id:{%s}", escapeUUID(id.UUID))
 
-	results, err := a.client.FTSearchWithArgs(ctx,
-		a.indexName,
-		query,
-		&redis.FTSearchOptions{
-			Return: []redis.FTSearchReturn{
-				{FieldName: "file_id"},
+	// Deleted documents are removed from the index, keep searching until none are left
+	for {
+		results, err := a.client.FTSearchWithArgs(ctx,
+			a.indexName,
+			query,
+			&redis.FTSearchOptions{
+				Return: []redis.FTSearchReturn{
+					{FieldName: "file_id"},
+				},
+				DialectVersion: a.dialectVersion,
+				Limit:          100, // Override default limit of 10
 			},
-			DialectVersion: a.dialectVersion,
-			Limit:          100, // Override default limit of 10
-		},
-	).Result()
+		).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(results.Docs) == 0 {
+			return nil
+		}
+
+		for _, doc := range results.Docs {
+			if _, err := a.client.Del(ctx, doc.ID).Result(); err != nil {
+				return fmt.Errorf("error deleting document %s: %w", doc.ID, err)
+			}
+		}
+	}
+}
+
+func (a *Adapter) CopyFileDocuments(ctx context.Context, from, to ragserver.FileID) error {
+	// Keys are collected before copying so new documents are not visited by the iteration
+	keys, err := a.fileDocumentKeys(ctx, from)
 	if err != nil {
 		return err
 	}
 
-	for _, doc := range results.Docs {
-		if _, err := a.client.Del(ctx, doc.ID).Result(); err != nil {
-			return fmt.Errorf("error deleting document %s: %w", doc.ID, err)
+	for _, key := range keys {
+		fields, err := a.client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("error reading document %s: %w", key, err)
+		}
+		if len(fields) == 0 {
+			continue // deleted since keys were collected
+		}
+		fields["file_id"] = to.String()
+
+		copyKey := fmt.Sprintf("doc:%v", uuid.Must(uuid.NewV4()))
+		if _, err := a.client.HSet(ctx, copyKey, fields).Result(); err != nil {
+			return fmt.Errorf("error copying document %s: %w", key, err)
 		}
 	}
 
 	return nil
 }
 
+// scanCount is a hint of how many keys are scanned per iteration.
+const scanCount = 1000
+
+// fileDocumentKeys returns keys of all documents of the file. Search results have no stable
+// order so paging them by offset skips or repeats documents, SCAN is used instead as it
+// returns every key which exists during the whole iteration.
+func (a *Adapter) fileDocumentKeys(ctx context.Context, id ragserver.FileID) ([]string, error) {
+	var (
+		keys   []string
+		seen   = map[string]struct{}{}
+		cursor uint64
+	)
+
+	for {
+		scanned, next, err := a.client.Scan(ctx, cursor, a.indexPrefix+"*", scanCount).Result()
+		if err != nil {
+			return nil, fmt.Errorf("error scanning documents: %w", err)
+		}
+
+		if len(scanned) > 0 {
+			pipe := a.client.Pipeline()
+			cmds := make([]*redis.StringCmd, 0, len(scanned))
+			for _, key := range scanned {
+				cmds = append(cmds, pipe.HGet(ctx, key, "file_id"))
+			}
+			if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+				return nil, fmt.Errorf("error reading file IDs of documents: %w", err)
+			}
+
+			for i, cmd := range cmds {
+				fileID, err := cmd.Result()
+				if errors.Is(err, redis.Nil) {
+					continue // deleted since it was scanned
+				}
+				if err != nil {
+					return nil, fmt.Errorf("error reading file ID of document %s: %w", scanned[i], err)
+				}
+				if fileID != id.String() {
+					continue
+				}
+				// SCAN may return a key more than once
+				if _, ok := seen[scanned[i]]; ok {
+					continue
+				}
+				seen[scanned[i]] = struct{}{}
+				keys = append(keys, scanned[i])
+			}
+		}
+
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
 func mapRedisDocuments(rds []redis.Document) ([]ragserver.Document, error) {
 	documents := make([]ragserver.Document, 0, len(rds))
 
@@ -180,9 +294,31 @@ func mapRedisDocument(rd redis.Document) (ragserver.Document, error) {
 	}
 
 	aDocument := ragserver.Document{
-		FileID:  ragserver.FileID{UUID: fileID},
-		Content: rd.Fields["content"],
-		Page:    page,
+		FileID:     ragserver.FileID{UUID: fileID},
+		Content:    rd.Fields["content"],
+		Page:       page,
+		Section:    rd.Fields["section"],
+		Language:   ragserver.Language(rd.Fields["language"]),
+		LayoutType: rd.Fields["layout_type"],
+	}
+
+	// Bounding box is optional, documents extracted without layout analysis don't have it
+	if box, ok := rd.Fields["bounding_box"]; ok && box != "" {
+		aDocument.BoundingBox = new(ragserver.BoundingBox)
+		if err := json.Unmarshal([]byte(box), aDocument.BoundingBox); err != nil {
+			return ragserver.Document{}, fmt.Errorf("invalid bounding_box value: %v", err)
+		}
+	}
+
+	for name, value := range rd.Fields {
+		key, ok := strings.CutPrefix(name, metadataFieldPrefix)
+		if !ok || value == "" {
+			continue
+		}
+		if aDocument.Metadata == nil {
+			aDocument.Metadata = ragserver.Metadata{}
+		}
+		aDocument.Metadata[key] = value
 	}
 
 	_, ok = rd.Fields["vector_distance"]
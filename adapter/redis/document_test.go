@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"fmt"
 	"math/rand/v2"
 
 	"github.com/gofrs/uuid/v5"
@@ -17,19 +18,31 @@ func (s *RedisTestSuite) TestSearchDocuments() {
 		fileID2   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
 		documents = []ragserver.Document{
 			{
-				Content: "This is a test document.",
-				FileID:  fileID1,
-				Page:    1,
+				Content:  "This is a test document.",
+				FileID:   fileID1,
+				Page:     1,
+				Language: ragserver.LanguageEnglish,
 			},
 			{
-				Content: "This is another test document.",
-				FileID:  fileID1,
-				Page:    2,
+				Content:  "Dies ist ein weiteres Testdokument.",
+				FileID:   fileID1,
+				Page:     2,
+				Language: ragserver.LanguageGerman,
 			},
 			{
-				Content: "This is a document from another file.",
-				FileID:  fileID2,
-				Page:    3,
+				Content:    "This is a document from another file.",
+				FileID:     fileID2,
+				Page:       3,
+				Language:   ragserver.LanguageEnglish,
+				LayoutType: "Text",
+				BoundingBox: &ragserver.BoundingBox{
+					Left:       72,
+					Top:        100.5,
+					Width:      451,
+					Height:     36,
+					PageWidth:  595,
+					PageHeight: 842,
+				},
 			},
 		}
 		vectors = []ragserver.Vector{
@@ -55,6 +68,8 @@ func (s *RedisTestSuite) TestSearchDocuments() {
 		s.Require().NoError(err)
 		s.Require().Len(results, 1)
 		s.Equal(documents[2].Content, results[0].Content)
+		s.Equal(documents[2].LayoutType, results[0].LayoutType)
+		s.Equal(documents[2].BoundingBox, results[0].BoundingBox)
 		s.NotEmpty(results[0].Distance)
 	})
 
@@ -72,6 +87,24 @@ func (s *RedisTestSuite) TestSearchDocuments() {
 		s.Equal(documents[1].Content, results[0].Content)
 		s.Equal(documents[2].Content, results[1].Content)
 		s.Equal(documents[0].Content, results[2].Content)
+		s.Nil(results[2].BoundingBox)
+	})
+
+	s.Run("Search documents by language", func() {
+		results, err := s.adapter.SearchDocuments(
+			ctx,
+			ragserver.DocumentFilter{
+				Vector:   searchVector,
+				FileIDs:  []ragserver.FileID{fileID1, fileID2},
+				Language: ragserver.LanguageEnglish,
+			},
+			25,
+		)
+		s.Require().NoError(err)
+		s.Require().Len(results, 2)
+		s.Equal(documents[2].Content, results[0].Content)
+		s.Equal(documents[0].Content, results[1].Content)
+		s.Equal(ragserver.LanguageEnglish, results[0].Language)
 	})
 }
 
@@ -136,6 +169,100 @@ func (s *RedisTestSuite) TestListFileDocuments() {
 	})
 }
 
+func (s *RedisTestSuite) TestCopyFileDocuments() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		fileID1   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		fileID2   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		documents = []ragserver.Document{
+			{
+				Content: "This is a test document.",
+				FileID:  fileID1,
+				Page:    1,
+			},
+			{
+				Content: "This is another test document.",
+				FileID:  fileID1,
+				Page:    2,
+				Section: "Emissions",
+			},
+		}
+		vectors = []ragserver.Vector{
+			testVector(s.adapter.vectorDim, 0, 100),
+			testVector(s.adapter.vectorDim, 0, 2),
+		}
+	)
+
+	err := s.adapter.SaveDocuments(ctx, documents, vectors)
+	s.Require().NoError(err)
+
+	err = s.adapter.CopyFileDocuments(ctx, fileID1, fileID2)
+	s.Require().NoError(err)
+
+	results, err := s.adapter.ListFileDocuments(ctx, fileID2, 100)
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	for _, doc := range documents {
+		doc.FileID = fileID2
+		s.Contains(results, doc)
+	}
+
+	// Vectors are copied as well so copied documents can be searched
+	results, err = s.adapter.SearchDocuments(ctx, ragserver.DocumentFilter{
+		Vector:  vectors[1],
+		FileIDs: []ragserver.FileID{fileID2},
+	}, 1)
+	s.Require().NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal(documents[1].Content, results[0].Content)
+
+	// Original documents are kept
+	results, err = s.adapter.ListFileDocuments(ctx, fileID1, 100)
+	s.Require().NoError(err)
+	s.Len(results, 2)
+}
+
+func (s *RedisTestSuite) TestCopyFileDocuments_ManyDocuments() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		fileID1   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		fileID2   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		otherID   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		documents = make([]ragserver.Document, 0, 251)
+		vectors   = make([]ragserver.Vector, 0, 251)
+	)
+	for i := range 250 {
+		documents = append(documents, ragserver.Document{
+			Content: fmt.Sprintf("Test document %d.", i),
+			FileID:  fileID1,
+			Page:    i + 1,
+		})
+		vectors = append(vectors, testVector(s.adapter.vectorDim, 0, 1))
+	}
+	documents = append(documents, ragserver.Document{Content: "Document of another file.", FileID: otherID, Page: 1})
+	vectors = append(vectors, testVector(s.adapter.vectorDim, 0, 1))
+
+	err := s.adapter.SaveDocuments(ctx, documents, vectors)
+	s.Require().NoError(err)
+
+	err = s.adapter.CopyFileDocuments(ctx, fileID1, fileID2)
+	s.Require().NoError(err)
+
+	// Every document is copied exactly once and documents of other files are not copied
+	results, err := s.adapter.ListFileDocuments(ctx, fileID2, 1000)
+	s.Require().NoError(err)
+	s.Require().Len(results, 250)
+	pages := map[int]struct{}{}
+	for _, result := range results {
+		pages[result.Page] = struct{}{}
+	}
+	s.Len(pages, 250)
+}
+
 func testVector(dim int, min, max float32) ragserver.Vector {
 	vec := make([]float32, dim)
 	for i := range vec {
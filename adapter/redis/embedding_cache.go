@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// embeddingCachePrefix is not the index prefix, so cached embeddings are not indexed as documents
+const embeddingCachePrefix = "embedding:"
+
+func embeddingCacheKey(embedder, model, hash string) string {
+	return fmt.Sprintf("%s%s:%s:%s", embeddingCachePrefix, embedder, model, hash)
+}
+
+func (a *Adapter) FindEmbeddings(ctx context.Context, embedder, model string, hashes ...string) (map[string]ragserver.Vector, error) {
+	if len(hashes) < 1 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		keys = append(keys, embeddingCacheKey(embedder, model, hash))
+	}
+
+	values, err := a.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cached embeddings: %w", err)
+	}
+
+	vectors := make(map[string]ragserver.Vector, len(hashes))
+	for i, value := range values {
+		// Missing keys are nil
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		vectors[hashes[i]] = bytesToFloats([]byte(data))
+	}
+
+	return vectors, nil
+}
+
+func (a *Adapter) SaveEmbeddings(ctx context.Context, embedder, model string, vectors map[string]ragserver.Vector) error {
+	if len(vectors) < 1 {
+		return nil
+	}
+
+	pipe := a.client.Pipeline()
+	for hash, vector := range vectors {
+		pipe.Set(ctx, embeddingCacheKey(embedder, model, hash), floatsToBytes(vector), a.embeddingCacheTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("error caching embeddings: %w", err)
+	}
+
+	return nil
+}
+
+// helper function to convert []byte to []float32, the reverse of floatsToBytes
+func bytesToFloats(buf []byte) []float32 {
+	fs := make([]float32, len(buf)/4)
+
+	for i := range fs {
+		fs[i] = math.Float32frombits(binary.NativeEndian.Uint32(buf[i*4:]))
+	}
+
+	return fs
+}
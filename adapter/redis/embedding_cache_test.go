@@ -0,0 +1,29 @@
+package redis
+
+import (
+	"github.com/RichardKnop/ragserver"
+)
+
+func (s *RedisTestSuite) TestEmbeddingCache() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	vectors := map[string]ragserver.Vector{
+		"hash1": {0.1, -0.2, 0.3},
+		"hash2": {1.5, 2.5, -3.5},
+	}
+
+	cached, err := s.adapter.FindEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", "hash1", "hash2")
+	s.Require().NoError(err)
+	s.Empty(cached)
+
+	s.Require().NoError(s.adapter.SaveEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", vectors), "error saving embeddings")
+
+	cached, err = s.adapter.FindEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", "hash1", "hash2", "hash3")
+	s.Require().NoError(err)
+	s.Equal(vectors, cached)
+
+	cached, err = s.adapter.FindEmbeddings(ctx, "hugot", "another-model", "hash1")
+	s.Require().NoError(err)
+	s.Empty(cached)
+}
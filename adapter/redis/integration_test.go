@@ -12,6 +12,8 @@ import (
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/RichardKnop/ragserver"
 )
 
 func TestRedisTestSuite(t *testing.T) {
@@ -62,6 +64,10 @@ func (s *RedisTestSuite) SetupTest() {
 		WithDialectVersion(2),
 		WithVectorDim(768),
 		WithVectorDistanceMetric("L2"),
+		WithMetadataFields(
+			ragserver.MetadataField{Key: "company", Type: ragserver.MetadataFieldTag},
+			ragserver.MetadataField{Key: "reporting_year", Type: ragserver.MetadataFieldNumeric},
+		),
 	)
 	s.Require().NoError(err)
 }
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// metadataFieldPrefix keeps metadata fields apart from other fields of documents.
+const metadataFieldPrefix = "meta_"
+
+func metadataFieldName(key string) string {
+	return metadataFieldPrefix + key
+}
+
+// metadataFieldSchema returns the index schema of a metadata field. Tag values are matched exactly,
+// commas are common in values such as company names so a pipe is used as the separator instead.
+func metadataFieldSchema(field ragserver.MetadataField) *redis.FieldSchema {
+	if field.Type == ragserver.MetadataFieldNumeric {
+		return &redis.FieldSchema{
+			FieldName: metadataFieldName(field.Key),
+			FieldType: redis.SearchFieldTypeNumeric,
+		}
+	}
+	return &redis.FieldSchema{
+		FieldName:     metadataFieldName(field.Key),
+		FieldType:     redis.SearchFieldTypeTag,
+		Separator:     "|",
+		CaseSensitive: true,
+	}
+}
+
+// metadataReturnFields returns configured metadata fields so they are mapped to documents.
+func (a *Adapter) metadataReturnFields() []redis.FTSearchReturn {
+	fields := make([]redis.FTSearchReturn, 0, len(a.metadataFields))
+	for _, field := range a.metadataFields {
+		fields = append(fields, redis.FTSearchReturn{FieldName: metadataFieldName(field.Key)})
+	}
+	return fields
+}
+
+// metadataQuery returns query clauses matching documents with all conditions of the filter.
+func metadataQuery(filter ragserver.MetadataFilter) string {
+	var query string
+	for _, condition := range filter {
+		if condition.IsRange() {
+			var (
+				min = "-inf"
+				max = "+inf"
+			)
+			if condition.Min != nil {
+				min = strconv.FormatFloat(*condition.Min, 'f', -1, 64)
+			}
+			if condition.Max != nil {
+				max = strconv.FormatFloat(*condition.Max, 'f', -1, 64)
+			}
+			query += fmt.Sprintf("(@%s:[%s %s])", metadataFieldName(condition.Key), min, max)
+			continue
+		}
+
+		values := make([]string, 0, len(condition.Values))
+		for _, value := range condition.Values {
+			values = append(values, escapeTag(value))
+		}
+		query += fmt.Sprintf("(@%s:{%s})", metadataFieldName(condition.Key), strings.Join(values, "|"))
+	}
+	return query
+}
+
+// escapeTag escapes punctuation and spaces which have a special meaning in tag queries.
+func escapeTag(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// UpdateFileMetadata replaces metadata fields of all documents of the file,
+// fields of configured keys missing from the metadata are deleted.
+func (a *Adapter) UpdateFileMetadata(ctx context.Context, id ragserver.FileID, metadata ragserver.Metadata) error {
+	values := map[string]any{}
+	deleted := []string{}
+	for _, field := range a.metadataFields {
+		if value, ok := metadata[field.Key]; ok {
+			values[metadataFieldName(field.Key)] = value
+		} else {
+			deleted = append(deleted, metadataFieldName(field.Key))
+		}
+	}
+
+	// Keys are collected before updating, updated documents are indexed again which would
+	// change results of a search paged through at the same time
+	keys, err := a.fileDocumentKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		// Setting fields of a deleted document would create a document without content
+		exists, err := a.client.Exists(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("error checking document %s exists: %w", key, err)
+		}
+		if exists == 0 {
+			continue
+		}
+
+		if len(values) > 0 {
+			if _, err := a.client.HSet(ctx, key, values).Result(); err != nil {
+				return fmt.Errorf("error updating metadata of document %s: %w", key, err)
+			}
+		}
+		if len(deleted) > 0 {
+			if _, err := a.client.HDel(ctx, key, deleted...).Result(); err != nil {
+				return fmt.Errorf("error deleting metadata of document %s: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+func TestMetadataQuery(t *testing.T) {
+	t.Parallel()
+
+	var (
+		min2020 = float64(2020)
+		max2023 = float64(2023.5)
+	)
+
+	testCases := []struct {
+		Name     string
+		Filter   ragserver.MetadataFilter
+		Expected string
+	}{
+		{"Empty filter", nil, ""},
+		{
+			"Tag values are escaped",
+			ragserver.MetadataFilter{{Key: "company", Values: []string{"Acme, Inc.", "Globex"}}},
+			`(@meta_company:{Acme\,\ Inc\.|Globex})`,
+		},
+		{
+			"Closed range",
+			ragserver.MetadataFilter{{Key: "reporting_year", Min: &min2020, Max: &max2023}},
+			`(@meta_reporting_year:[2020 2023.5])`,
+		},
+		{
+			"Open ranges and multiple conditions",
+			ragserver.MetadataFilter{
+				{Key: "reporting_year", Min: &min2020},
+				{Key: "reporting_year", Max: &max2023},
+			},
+			`(@meta_reporting_year:[2020 +inf])(@meta_reporting_year:[-inf 2023.5])`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, metadataQuery(tc.Filter))
+		})
+	}
+}
+
+func (s *RedisTestSuite) TestSearchDocuments_Metadata() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		fileID1   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		fileID2   = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		documents = []ragserver.Document{
+			{
+				Content:  "This is a test document.",
+				FileID:   fileID1,
+				Page:     1,
+				Metadata: ragserver.Metadata{"company": "Acme, Inc.", "reporting_year": "2023"},
+			},
+			{
+				Content:  "This is a document from another file.",
+				FileID:   fileID2,
+				Page:     1,
+				Metadata: ragserver.Metadata{"company": "Globex", "reporting_year": "2024"},
+			},
+		}
+		vectors = []ragserver.Vector{
+			testVector(s.adapter.vectorDim, 0, 100),
+			testVector(s.adapter.vectorDim, 0, 2),
+		}
+		searchVector = testVector(s.adapter.vectorDim, 0, 5)
+		min2024      = float64(2024)
+	)
+
+	err := s.adapter.SaveDocuments(ctx, documents, vectors)
+	s.Require().NoError(err)
+
+	s.Run("Search documents by tag", func() {
+		results, err := s.adapter.SearchDocuments(ctx, ragserver.DocumentFilter{
+			Vector:   searchVector,
+			FileIDs:  []ragserver.FileID{fileID1, fileID2},
+			Metadata: ragserver.MetadataFilter{{Key: "company", Values: []string{"Acme, Inc."}}},
+		}, 25)
+		s.Require().NoError(err)
+		s.Require().Len(results, 1)
+		s.Equal(documents[0].Content, results[0].Content)
+		s.Equal(documents[0].Metadata, results[0].Metadata)
+	})
+
+	s.Run("Search documents by range", func() {
+		results, err := s.adapter.SearchDocuments(ctx, ragserver.DocumentFilter{
+			Vector:   searchVector,
+			FileIDs:  []ragserver.FileID{fileID1, fileID2},
+			Metadata: ragserver.MetadataFilter{{Key: "reporting_year", Min: &min2024}},
+		}, 25)
+		s.Require().NoError(err)
+		s.Require().Len(results, 1)
+		s.Equal(documents[1].Content, results[0].Content)
+	})
+
+	s.Run("Update metadata of file documents", func() {
+		err := s.adapter.UpdateFileMetadata(ctx, fileID1, ragserver.Metadata{"company": "Initech"})
+		s.Require().NoError(err)
+
+		results, err := s.adapter.ListFileDocuments(ctx, fileID1, 100)
+		s.Require().NoError(err)
+		s.Require().Len(results, 1)
+		s.Equal(ragserver.Metadata{"company": "Initech"}, results[0].Metadata)
+
+		results, err = s.adapter.SearchDocuments(ctx, ragserver.DocumentFilter{
+			Vector:   searchVector,
+			Metadata: ragserver.MetadataFilter{{Key: "company", Values: []string{"Acme, Inc."}}},
+		}, 25)
+		s.Require().NoError(err)
+		s.Empty(results)
+	})
+}
+
+func (s *RedisTestSuite) TestUpdateFileMetadata_ManyDocuments() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		fileID    = ragserver.FileID{UUID: uuid.Must(uuid.NewV4())}
+		documents = make([]ragserver.Document, 0, 250)
+		vectors   = make([]ragserver.Vector, 0, 250)
+	)
+	for i := range 250 {
+		documents = append(documents, ragserver.Document{
+			Content:  "This is a test document.",
+			FileID:   fileID,
+			Page:     i + 1,
+			Metadata: ragserver.Metadata{"company": "Acme, Inc.", "reporting_year": "2023"},
+		})
+		vectors = append(vectors, testVector(s.adapter.vectorDim, 0, 1))
+	}
+
+	err := s.adapter.SaveDocuments(ctx, documents, vectors)
+	s.Require().NoError(err)
+
+	err = s.adapter.UpdateFileMetadata(ctx, fileID, ragserver.Metadata{"company": "Initech"})
+	s.Require().NoError(err)
+
+	// Every document is updated, the reporting year is deleted as it's missing from the metadata
+	results, err := s.adapter.ListFileDocuments(ctx, fileID, 1000)
+	s.Require().NoError(err)
+	s.Require().Len(results, 250)
+	for _, result := range results {
+		s.Equal(ragserver.Metadata{"company": "Initech"}, result.Metadata)
+	}
+}
@@ -15,24 +15,57 @@ import (
 )
 
 type RagServer interface {
-	CreateFile(ctx context.Context, principal authz.Principal, file io.ReadSeeker, header *multipart.FileHeader) (*ragserver.File, error)
-	ListFiles(ctx context.Context, principal authz.Principal) ([]*ragserver.File, error)
+	CreateFile(ctx context.Context, principal authz.Principal, file io.ReadSeeker, header *multipart.FileHeader, params ragserver.FileParams) (*ragserver.File, error)
+	CreateFiles(ctx context.Context, principal authz.Principal, entries []ragserver.FileEntry, params ragserver.FileParams) ([]ragserver.FileEntryResult, error)
+	CreateFilesFromArchive(ctx context.Context, principal authz.Principal, archive io.ReaderAt, size int64, params ragserver.FileParams) ([]ragserver.FileEntryResult, error)
+	ImportFile(ctx context.Context, principal authz.Principal, sourceURL string, params ragserver.FileParams) (*ragserver.File, error)
+	CreateUpload(ctx context.Context, principal authz.Principal, fileName string, size int64, params ragserver.FileParams) (*ragserver.Upload, error)
+	FindUpload(ctx context.Context, principal authz.Principal, id ragserver.UploadID) (*ragserver.Upload, error)
+	WriteUpload(ctx context.Context, principal authz.Principal, id ragserver.UploadID, offset int64, data io.Reader) (*ragserver.Upload, error)
+	DeleteUpload(ctx context.Context, principal authz.Principal, id ragserver.UploadID) error
+	ListFiles(ctx context.Context, principal authz.Principal, metadata ragserver.MetadataFilter) ([]*ragserver.File, error)
 	FindFile(ctx context.Context, principal authz.Principal, id ragserver.FileID) (*ragserver.File, error)
+	UpdateFile(ctx context.Context, principal authz.Principal, id ragserver.FileID, update ragserver.FileUpdate) (*ragserver.File, error)
 	ListFileDocuments(ctx context.Context, principal authz.Principal, id ragserver.FileID, filter ragserver.DocumentFilter, limit int) ([]ragserver.Document, error)
+	FileContent(ctx context.Context, principal authz.Principal, id ragserver.FileID) (*ragserver.File, io.ReadSeekCloser, error)
+	FileText(ctx context.Context, principal authz.Principal, id ragserver.FileID, format ragserver.TextFormat) (string, error)
 	DeleteFile(ctx context.Context, principal authz.Principal, id ragserver.FileID) error
+	ReprocessFile(ctx context.Context, principal authz.Principal, id ragserver.FileID) (*ragserver.File, error)
+	ReindexFiles(ctx context.Context, principal authz.Principal, all bool) ([]*ragserver.File, error)
 	CreateScreening(ctx context.Context, principal authz.Principal, params ragserver.ScreeningParams) (*ragserver.Screening, error)
 	ListScreenings(ctx context.Context, principal authz.Principal) ([]*ragserver.Screening, error)
 	FindScreening(ctx context.Context, principal authz.Principal, id ragserver.ScreeningID) (*ragserver.Screening, error)
 	DeleteScreening(ctx context.Context, principal authz.Principal, id ragserver.ScreeningID) error
+	SaveTopicSet(ctx context.Context, principal authz.Principal, aTopicSet *ragserver.TopicSet) (*ragserver.TopicSet, error)
+	ListTopicSets(ctx context.Context, principal authz.Principal) ([]*ragserver.TopicSet, error)
+	FindTopicSet(ctx context.Context, principal authz.Principal, name string) (*ragserver.TopicSet, error)
+	DeleteTopicSet(ctx context.Context, principal authz.Principal, name string) error
+	CreateLogicalDocument(ctx context.Context, principal authz.Principal, params ragserver.LogicalDocumentParams) (*ragserver.LogicalDocument, error)
+	ListLogicalDocuments(ctx context.Context, principal authz.Principal) ([]*ragserver.LogicalDocument, error)
+	FindLogicalDocument(ctx context.Context, principal authz.Principal, id ragserver.LogicalDocumentID) (*ragserver.LogicalDocument, error)
+	AddLogicalDocumentVersion(ctx context.Context, principal authz.Principal, id ragserver.LogicalDocumentID, fileID ragserver.FileID) (*ragserver.LogicalDocument, error)
+	DeleteLogicalDocument(ctx context.Context, principal authz.Principal, id ragserver.LogicalDocumentID) error
+	ValidateQuery(ctx context.Context, principal authz.Principal, question ragserver.Question, filter ragserver.DocumentFilter) error
+	Query(ctx context.Context, principal authz.Principal, question ragserver.Question, filter ragserver.DocumentFilter) (ragserver.Response, error)
+	StreamQuery(ctx context.Context, principal authz.Principal, question ragserver.Question, filter ragserver.DocumentFilter, onPartial func(text string) error) (ragserver.Response, error)
 }
 
 type Adapter struct {
-	ragServer RagServer
-	logger    *zap.Logger
+	ragServer      RagServer
+	maxRequestSize int64
+	logger         *zap.Logger
 }
 
 type Option func(*Adapter)
 
+// WithMaxRequestSize limits size of multipart upload requests which can hold multiple files
+// or an archive, 100MB by default. Size of each file is limited by the RAG server.
+func WithMaxRequestSize(size int64) Option {
+	return func(a *Adapter) {
+		a.maxRequestSize = size
+	}
+}
+
 func WithLogger(logger *zap.Logger) Option {
 	return func(a *Adapter) {
 		a.logger = logger
@@ -41,8 +74,9 @@ func WithLogger(logger *zap.Logger) Option {
 
 func New(ragServer RagServer, options ...Option) *Adapter {
 	a := &Adapter{
-		ragServer: ragServer,
-		logger:    zap.NewNop(),
+		ragServer:      ragServer,
+		maxRequestSize: defaultMaxRequestSize,
+		logger:         zap.NewNop(),
 	}
 
 	for _, o := range options {
@@ -53,7 +87,8 @@ func New(ragServer RagServer, options ...Option) *Adapter {
 }
 
 const (
-	defaultTimeout = 3 * time.Second
+	defaultTimeout        = 3 * time.Second
+	defaultMaxRequestSize = 100 * ragserver.MB
 )
 
 var (
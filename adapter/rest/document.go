@@ -41,8 +41,15 @@ func (a *Adapter) ListFileDocuments(w http.ResponseWriter, r *http.Request, id o
 		limit = defaultLimit
 	}
 
+	language, err := mapApiLanguage(params.Language)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
 	documents, err := a.ragServer.ListFileDocuments(ctx, principal, ragserver.FileID{UUID: fileID}, ragserver.DocumentFilter{
 		SimilarTo: api.FromString(params.SimilarTo),
+		Language:  language,
 	}, api.FromInt(params.Limit))
 	if err != nil {
 		if errors.Is(err, ragserver.ErrNotFound) {
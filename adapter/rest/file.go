@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -17,9 +18,12 @@ import (
 // TODO - implement a file lifecycle so UploadFile can return relatively quickly
 // and the file is processed in the background. This will allow us to return a 202 Accepted
 // response with a Location header pointing to the file resource, which can be polled for status.
-const uploadTimeout = 300 * time.Second
+const (
+	uploadTimeout  = 300 * time.Second
+	reindexTimeout = 300 * time.Second
+)
 
-// Upload a file and add documents extracted from it to the knowledge base
+// Upload one or more files and add documents extracted from them to the knowledge base
 // (POST /files)
 func (a *Adapter) UploadFile(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -28,23 +32,188 @@ func (a *Adapter) UploadFile(w http.ResponseWriter, r *http.Request) {
 	)
 	defer cancel()
 
-	// Limit memory usage to 20MB, anythin over this limit will be stored in a temporary file.
-	r.ParseMultipartForm(ragserver.MaxFileSize)
+	if !a.parseMultipartForm(w, r) {
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
 
-	// Limit the size of the request body to prevent large uploads. This will return
-	// io.MaxBytesError if the request body exceeds the limit while being read.
-	r.Body = http.MaxBytesReader(w, r.Body, ragserver.MaxFileSize)
+	headers := r.MultipartForm.File["file"]
+	if len(headers) == 0 {
+		renderJSONError(w, http.StatusBadRequest, fmt.Errorf("missing file in request"))
+		return
+	}
+
+	params, err := fileParamsFromForm(r)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
 
-	file, header, err := r.FormFile("file")
+	// Multiple files are created in a single transaction with a result for each file
+	if len(headers) > 1 {
+		entries := make([]ragserver.FileEntry, 0, len(headers))
+		for _, header := range headers {
+			entries = append(entries, ragserver.FileEntry{
+				FileName: header.Filename,
+				Open: func() (io.ReadCloser, error) {
+					return header.Open()
+				},
+			})
+		}
+
+		results, err := a.ragServer.CreateFiles(ctx, principal, entries, params)
+		if err != nil {
+			a.renderBatchError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusMultiStatus)
+		renderJSON(w, mapFileBatch(results))
+		return
+	}
+
+	file, err := headers[0].Open()
 	if err != nil {
 		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error reading file from request: %w", err))
 		return
 	}
 	defer file.Close()
 
-	aFile, err := a.ragServer.CreateFile(ctx, principal, file, header)
+	aFile, err := a.ragServer.CreateFile(ctx, principal, file, headers[0], params)
 	if err != nil {
-		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error creating file: %w", err))
+		switch {
+		case errors.Is(err, ragserver.ErrFileTooLarge):
+			renderJSONError(w, http.StatusRequestEntityTooLarge, err)
+		case errors.Is(err, ragserver.ErrInvalidFileType):
+			renderJSONError(w, http.StatusUnsupportedMediaType, err)
+		case errors.Is(err, ragserver.ErrInvalidTopics), errors.Is(err, ragserver.ErrInvalidMetadata):
+			renderJSONError(w, http.StatusBadRequest, err)
+		default:
+			renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error creating file: %w", err))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	renderJSON(w, mapFile(aFile))
+}
+
+// Upload a ZIP archive and create a file from each entry
+// (POST /files/archive)
+func (a *Adapter) UploadArchive(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), uploadTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	if !a.parseMultipartForm(w, r) {
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	archive, header, err := r.FormFile("file")
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, fmt.Errorf("error reading archive from request: %w", err))
+		return
+	}
+	defer archive.Close()
+
+	params, err := fileParamsFromForm(r)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := a.ragServer.CreateFilesFromArchive(ctx, principal, archive, header.Size, params)
+	if err != nil {
+		a.renderBatchError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusMultiStatus)
+	renderJSON(w, mapFileBatch(results))
+}
+
+// parseMultipartForm parses the request body with its size limited, an error response
+// is rendered if it can't be parsed.
+func (a *Adapter) parseMultipartForm(w http.ResponseWriter, r *http.Request) bool {
+	// Limit the size of the request body to prevent large uploads. This will return
+	// http.MaxBytesError if the request body exceeds the limit while being read.
+	r.Body = http.MaxBytesReader(w, r.Body, a.maxRequestSize)
+
+	// Limit memory usage to 20MB, anything over this limit will be stored in a temporary file.
+	if err := r.ParseMultipartForm(ragserver.MaxFileSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			renderJSONError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request exceeds limit of %d bytes, use resumable uploads for large files", a.maxRequestSize))
+			return false
+		}
+		renderJSONError(w, http.StatusBadRequest, fmt.Errorf("error parsing multipart form: %w", err))
+		return false
+	}
+
+	return true
+}
+
+func (a *Adapter) renderBatchError(w http.ResponseWriter, err error) {
+	a.logger.Sugar().With("error", err).Error("error creating files")
+	switch {
+	case errors.Is(err, ragserver.ErrInvalidArchive), errors.Is(err, ragserver.ErrTooManyFiles), errors.Is(err, ragserver.ErrInvalidTopics), errors.Is(err, ragserver.ErrInvalidMetadata):
+		renderJSONError(w, http.StatusBadRequest, err)
+	default:
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error creating files: %w", err))
+	}
+}
+
+func mapFileBatch(results []ragserver.FileEntryResult) api.FileBatch {
+	apiResponse := api.FileBatch{
+		Results: make([]api.FileBatchResult, 0, len(results)),
+	}
+	for _, result := range results {
+		apiResult := api.FileBatchResult{
+			FileName: result.FileName,
+		}
+		if result.Err != nil {
+			message := result.Err.Error()
+			apiResult.Error = &message
+		} else {
+			apiFile := mapFile(result.File)
+			apiResult.File = &apiFile
+		}
+		apiResponse.Results = append(apiResponse.Results, apiResult)
+	}
+	return apiResponse
+}
+
+// Download a file from a URL and add documents extracted from it to the knowledge base
+// (POST /files/import)
+func (a *Adapter) ImportFile(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), uploadTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	apiRequest := api.ImportFileParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	aFile, err := a.ragServer.ImportFile(ctx, principal, apiRequest.Url, mapApiFileParams(apiRequest.TopicSet, apiRequest.Topics, apiRequest.Metadata))
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("error importing file")
+		switch {
+		case errors.Is(err, ragserver.ErrInvalidURL), errors.Is(err, ragserver.ErrInvalidTopics), errors.Is(err, ragserver.ErrInvalidMetadata):
+			renderJSONError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ragserver.ErrFileTooLarge):
+			renderJSONError(w, http.StatusRequestEntityTooLarge, err)
+		case errors.Is(err, ragserver.ErrInvalidFileType):
+			renderJSONError(w, http.StatusUnsupportedMediaType, err)
+		default:
+			renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error importing file: %w", err))
+		}
 		return
 	}
 
@@ -53,31 +222,70 @@ func (a *Adapter) UploadFile(w http.ResponseWriter, r *http.Request) {
 }
 
 func mapFile(file *ragserver.File) api.File {
-	return api.File{
+	apiFile := api.File{
 		Id:            openapi_types.UUID(file.ID.UUID[0:16]),
 		FileName:      file.FileName,
 		ContentType:   file.ContentType,
 		Extension:     file.Extension,
 		Size:          file.Size,
 		Hash:          file.Hash,
+		Chunker:       file.Chunker,
 		Status:        api.FileStatus(file.Status),
 		StatusMessage: file.StatusMessage,
-		CreatedAt:     file.Created,
-		UpdatedAt:     file.Updated,
+		Attempts:      file.Attempts,
+		Progress: api.FileProgress{
+			Pages:             file.Progress.Pages,
+			Documents:         file.Progress.Documents,
+			DocumentsEmbedded: file.Progress.DocumentsEmbedded,
+		},
+		CreatedAt: file.Created,
+		UpdatedAt: file.Updated,
+		Topics:    mapFileTopics(file.Topics),
+		Metadata:  mapMetadata(file.Metadata),
 	}
+	if file.Language != "" {
+		language := api.Language(file.Language)
+		apiFile.Language = &language
+	}
+	if !file.LogicalDocumentID.IsNil() {
+		logicalDocumentID := openapi_types.UUID(file.LogicalDocumentID.UUID[0:16])
+		apiFile.LogicalDocumentId = &logicalDocumentID
+		apiFile.Version = &file.Version
+	}
+	if file.Stage != "" {
+		stage := api.FileStage(file.Stage)
+		apiFile.Stage = &stage
+	}
+	if file.SourceURL != "" {
+		apiFile.SourceUrl = &file.SourceURL
+	}
+	if !file.NextAttempt.IsZero() {
+		apiFile.NextAttemptAt = &file.NextAttempt
+	}
+	return apiFile
 }
 
 // List uploaded files
 // (GET /files)
-func (a *Adapter) ListFiles(w http.ResponseWriter, r *http.Request) {
+func (a *Adapter) ListFiles(w http.ResponseWriter, r *http.Request, params api.ListFilesParams) {
 	var (
 		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
 		principal   = a.principalFromRequest(r)
 	)
 	defer cancel()
 
-	files, err := a.ragServer.ListFiles(ctx, principal)
+	metadata, err := parseMetadataFilter(params.Metadata)
 	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	files, err := a.ragServer.ListFiles(ctx, principal, metadata)
+	if err != nil {
+		if errors.Is(err, ragserver.ErrInvalidMetadata) {
+			renderJSONError(w, http.StatusBadRequest, err)
+			return
+		}
 		a.logger.Sugar().With("error", err).Error("error listing files")
 		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error listing files: %w", err))
 		return
@@ -126,6 +334,49 @@ func (a *Adapter) GetFileById(w http.ResponseWriter, r *http.Request, id openapi
 	renderJSON(w, mapFile(aFile))
 }
 
+// Update metadata of a file
+// (PATCH /files/{id})
+func (a *Adapter) UpdateFileById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	fileID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid file ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid file ID: %w", err))
+		return
+	}
+
+	apiRequest := api.FileUpdateParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	aFile, err := a.ragServer.UpdateFile(ctx, principal, ragserver.FileID{UUID: fileID}, ragserver.FileUpdate{
+		Metadata: ragserver.Metadata(apiRequest.Metadata),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ragserver.ErrNotFound):
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("file not found"))
+		case errors.Is(err, ragserver.ErrInvalidMetadata):
+			renderJSONError(w, http.StatusBadRequest, err)
+		case errors.Is(err, ragserver.ErrInvalidFileStatus):
+			renderJSONError(w, http.StatusConflict, err)
+		default:
+			a.logger.Sugar().With("error", err).Error("error updating file")
+			renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error updating file: %w", err))
+		}
+		return
+	}
+
+	renderJSON(w, mapFile(aFile))
+}
+
 // Delete a file by ID
 // (DELETE /files/{id})
 func (a *Adapter) DeleteFileById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
@@ -155,10 +406,84 @@ func (a *Adapter) DeleteFileById(w http.ResponseWriter, r *http.Request, id open
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Reprocess a file with the current extractor, embedder and retriever
+// (POST /files/{id}/reprocess)
+func (a *Adapter) ReprocessFile(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	fileID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid file ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid file ID: %w", err))
+		return
+	}
+
+	aFile, err := a.ragServer.ReprocessFile(ctx, principal, ragserver.FileID{UUID: fileID})
+	if err != nil {
+		switch {
+		case errors.Is(err, ragserver.ErrNotFound):
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("file not found"))
+		case errors.Is(err, ragserver.ErrInvalidFileStatus), errors.Is(err, ragserver.ErrUnknownRetriever):
+			renderJSONError(w, http.StatusConflict, err)
+		default:
+			a.logger.Sugar().With("error", err).Error("error reprocessing file")
+			renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error reprocessing file: %w", err))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	renderJSON(w, mapFile(aFile))
+}
+
+// Reprocess files embedded with a different embedder or stored by a different retriever
+// (POST /files/reindex)
+func (a *Adapter) ReindexFiles(w http.ResponseWriter, r *http.Request, params api.ReindexFilesParams) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), reindexTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	var all bool
+	if params.All != nil {
+		all = *params.All
+	}
+
+	files, err := a.ragServer.ReindexFiles(ctx, principal, all)
+	if err != nil {
+		if errors.Is(err, ragserver.ErrUnknownRetriever) {
+			renderJSONError(w, http.StatusConflict, err)
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error reindexing files")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error reindexing files: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	renderJSON(w, mapFiles(files))
+}
+
 func mapDocument(document ragserver.Document) api.Document {
 	aDocument := api.Document{
-		Content: document.Content,
-		Page:    int32(document.Page),
+		Content:     document.Content,
+		Page:        int32(document.Page),
+		BoundingBox: mapBoundingBox(document.BoundingBox),
+	}
+	if document.Section != "" {
+		aDocument.Section = &document.Section
+	}
+	if document.Language != "" {
+		language := api.Language(document.Language)
+		aDocument.Language = &language
+	}
+	if document.LayoutType != "" {
+		aDocument.LayoutType = &document.LayoutType
 	}
 	if document.Distance != nil {
 		aDocument.Distance = document.Distance
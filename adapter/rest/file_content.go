@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/api"
+)
+
+// Download original contents of a file
+// (GET /files/{id}/content)
+func (a *Adapter) GetFileContent(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	fileID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid file ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid file ID: %w", err))
+		return
+	}
+
+	aFile, content, err := a.ragServer.FileContent(ctx, principal, ragserver.FileID{UUID: fileID})
+	if err != nil {
+		if errors.Is(err, ragserver.ErrNotFound) {
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("file not found"))
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error opening file content")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error opening file content: %w", err))
+		return
+	}
+	defer content.Close()
+
+	// Contents are stored by hash so it is a strong validator for conditional and range requests
+	w.Header().Set("Content-Type", aFile.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": aFile.FileName}))
+	w.Header().Set("ETag", `"`+aFile.Hash+`"`)
+
+	http.ServeContent(w, r, aFile.FileName, aFile.Created, content)
+}
+
+// Extract text from original contents of a file, grouped by page
+// (GET /files/{id}/text)
+func (a *Adapter) GetFileText(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params api.GetFileTextParams) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), uploadTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	fileID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid file ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid file ID: %w", err))
+		return
+	}
+
+	format := ragserver.TextFormatPlain
+	if params.Format != nil {
+		switch *params.Format {
+		case api.Text:
+		case api.Markdown:
+			format = ragserver.TextFormatMarkdown
+		default:
+			renderJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid format: %s", *params.Format))
+			return
+		}
+	}
+
+	text, err := a.ragServer.FileText(ctx, principal, ragserver.FileID{UUID: fileID}, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, ragserver.ErrNotFound):
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("file not found"))
+		case errors.Is(err, ragserver.ErrInvalidFileType):
+			renderJSONError(w, http.StatusBadRequest, err)
+		default:
+			a.logger.Sugar().With("error", err).Error("error extracting file text")
+			renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error extracting file text: %w", err))
+		}
+		return
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	if format == ragserver.TextFormatMarkdown {
+		contentType = "text/markdown; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(text))
+}
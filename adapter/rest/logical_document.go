@@ -0,0 +1,216 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/api"
+)
+
+// List logical documents with their versions
+// (GET /logical-documents)
+func (a *Adapter) ListLogicalDocuments(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	documents, err := a.ragServer.ListLogicalDocuments(ctx, principal)
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("error listing logical documents")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error listing logical documents: %w", err))
+		return
+	}
+
+	apiResponse := api.LogicalDocuments{
+		LogicalDocuments: make([]api.LogicalDocument, 0, len(documents)),
+	}
+	for _, aDocument := range documents {
+		apiResponse.LogicalDocuments = append(apiResponse.LogicalDocuments, mapLogicalDocument(aDocument))
+	}
+
+	renderJSON(w, apiResponse)
+}
+
+// Create a logical document grouping files which are versions of the same document
+// (POST /logical-documents)
+func (a *Adapter) CreateLogicalDocument(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	apiRequest := api.LogicalDocumentParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	params := ragserver.LogicalDocumentParams{
+		Name: apiRequest.Name,
+	}
+	if apiRequest.FileIds != nil {
+		fileIDs, err := mapApiFileIDs(*apiRequest.FileIds)
+		if err != nil {
+			renderJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		params.FileIDs = fileIDs
+	}
+
+	aDocument, err := a.ragServer.CreateLogicalDocument(ctx, principal, params)
+	if err != nil {
+		if errors.Is(err, ragserver.ErrInvalidLogicalDocument) {
+			renderJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error creating logical document")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error creating logical document: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	renderJSON(w, mapLogicalDocument(aDocument))
+}
+
+// Get a logical document by ID
+// (GET /logical-documents/{id})
+func (a *Adapter) GetLogicalDocumentById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	documentID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid logical document ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid logical document ID: %w", err))
+		return
+	}
+
+	aDocument, err := a.ragServer.FindLogicalDocument(ctx, principal, ragserver.LogicalDocumentID{UUID: documentID})
+	if err != nil {
+		if errors.Is(err, ragserver.ErrNotFound) {
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("logical document not found"))
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error finding logical document")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error finding logical document: %w", err))
+		return
+	}
+
+	renderJSON(w, mapLogicalDocument(aDocument))
+}
+
+// Delete a logical document, files of its versions are kept
+// (DELETE /logical-documents/{id})
+func (a *Adapter) DeleteLogicalDocumentById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	documentID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid logical document ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid logical document ID: %w", err))
+		return
+	}
+
+	if err := a.ragServer.DeleteLogicalDocument(ctx, principal, ragserver.LogicalDocumentID{UUID: documentID}); err != nil {
+		if errors.Is(err, ragserver.ErrNotFound) {
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("logical document not found"))
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error deleting logical document")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error deleting logical document: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Add a file as the latest version of a logical document
+// (POST /logical-documents/{id}/versions)
+func (a *Adapter) AddLogicalDocumentVersion(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	documentID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid logical document ID")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("invalid logical document ID: %w", err))
+		return
+	}
+
+	apiRequest := api.LogicalDocumentVersionParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fileID, err := uuid.FromString(apiRequest.FileId.String())
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid file ID: %w", err))
+		return
+	}
+
+	aDocument, err := a.ragServer.AddLogicalDocumentVersion(ctx, principal, ragserver.LogicalDocumentID{UUID: documentID}, ragserver.FileID{UUID: fileID})
+	if err != nil {
+		switch {
+		case errors.Is(err, ragserver.ErrNotFound):
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("logical document not found"))
+		case errors.Is(err, ragserver.ErrInvalidLogicalDocument):
+			renderJSONError(w, http.StatusBadRequest, err)
+		default:
+			a.logger.Sugar().With("error", err).Error("error adding logical document version")
+			renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error adding logical document version: %w", err))
+		}
+		return
+	}
+
+	renderJSON(w, mapLogicalDocument(aDocument))
+}
+
+func mapLogicalDocument(aDocument *ragserver.LogicalDocument) api.LogicalDocument {
+	apiDocument := api.LogicalDocument{
+		Id:        openapi_types.UUID(aDocument.ID.UUID[0:16]),
+		Name:      aDocument.Name,
+		Versions:  make([]api.File, 0, len(aDocument.Versions)),
+		CreatedAt: aDocument.Created,
+		UpdatedAt: aDocument.Updated,
+	}
+	for _, aFile := range aDocument.Versions {
+		apiDocument.Versions = append(apiDocument.Versions, mapFile(aFile))
+	}
+	if latest := aDocument.LatestVersion(); latest != nil {
+		latestFileID := openapi_types.UUID(latest.ID.UUID[0:16])
+		apiDocument.LatestFileId = &latestFileID
+	}
+	return apiDocument
+}
+
+func mapApiLogicalDocumentIDs(ids []openapi_types.UUID) ([]ragserver.LogicalDocumentID, error) {
+	documentIDs := make([]ragserver.LogicalDocumentID, 0, len(ids))
+	for _, id := range ids {
+		documentID, err := uuid.FromString(id.String())
+		if err != nil {
+			return nil, err
+		}
+		documentIDs = append(documentIDs, ragserver.LogicalDocumentID{UUID: documentID})
+	}
+	return documentIDs, nil
+}
@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/api"
+)
+
+// fileParamsFromForm reads topics and a JSON object of metadata from multipart form fields.
+func fileParamsFromForm(r *http.Request) (ragserver.FileParams, error) {
+	topics, err := topicSelectionFromForm(r)
+	if err != nil {
+		return ragserver.FileParams{}, err
+	}
+
+	params := ragserver.FileParams{
+		Topics: topics,
+	}
+	if metadata := r.FormValue("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &params.Metadata); err != nil {
+			return ragserver.FileParams{}, fmt.Errorf("%w: %v", ragserver.ErrInvalidMetadata, err)
+		}
+	}
+	return params, nil
+}
+
+func mapApiFileParams(topicSet *string, topics *[]api.Topic, metadata *api.Metadata) ragserver.FileParams {
+	return ragserver.FileParams{
+		Topics:   mapApiTopicSelection(topicSet, topics),
+		Metadata: mapApiMetadata(metadata),
+	}
+}
+
+func mapApiMetadata(apiMetadata *api.Metadata) ragserver.Metadata {
+	if apiMetadata == nil {
+		return nil
+	}
+	return ragserver.Metadata(*apiMetadata)
+}
+
+func mapMetadata(metadata ragserver.Metadata) *api.Metadata {
+	if len(metadata) == 0 {
+		return nil
+	}
+	apiMetadata := api.Metadata(metadata)
+	return &apiMetadata
+}
+
+func mapApiMetadataFilter(apiConditions *[]api.MetadataCondition) ragserver.MetadataFilter {
+	if apiConditions == nil {
+		return nil
+	}
+	filter := make(ragserver.MetadataFilter, 0, len(*apiConditions))
+	for _, apiCondition := range *apiConditions {
+		condition := ragserver.MetadataCondition{
+			Key: apiCondition.Key,
+			Min: apiCondition.Min,
+			Max: apiCondition.Max,
+		}
+		if apiCondition.Values != nil {
+			condition.Values = *apiCondition.Values
+		}
+		filter = append(filter, condition)
+	}
+	return filter
+}
+
+// parseMetadataFilter parses conditions written as key:value, key>=number or key<=number,
+// alternative values are separated by a pipe. Keys can't contain operators, the first one ends the key.
+func parseMetadataFilter(params *[]string) (ragserver.MetadataFilter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	filter := make(ragserver.MetadataFilter, 0, len(*params))
+	for _, param := range *params {
+		i := strings.IndexAny(param, ":<>")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: condition %q must be written as key:value, key>=number or key<=number", ragserver.ErrInvalidMetadata, param)
+		}
+
+		var (
+			key      = param[:i]
+			operator = param[i:]
+		)
+		switch {
+		case strings.HasPrefix(operator, ":"):
+			filter = append(filter, ragserver.MetadataCondition{Key: key, Values: strings.Split(operator[1:], "|")})
+		case strings.HasPrefix(operator, ">="), strings.HasPrefix(operator, "<="):
+			number, err := strconv.ParseFloat(operator[2:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid number in %q", ragserver.ErrInvalidMetadata, param)
+			}
+			condition := ragserver.MetadataCondition{Key: key}
+			if operator[0] == '>' {
+				condition.Min = &number
+			} else {
+				condition.Max = &number
+			}
+			filter = append(filter, condition)
+		default:
+			return nil, fmt.Errorf("%w: condition %q must be written as key:value, key>=number or key<=number", ragserver.ErrInvalidMetadata, param)
+		}
+	}
+	return filter, nil
+}
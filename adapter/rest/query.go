@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/api"
+)
+
+const (
+	queryTimeout = 120 * time.Second
+)
+
+// Answer a single question using the given files, without creating a screening
+// (POST /query)
+func (a *Adapter) Query(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), queryTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	apiRequest := api.QueryParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fileIDs, err := mapApiFileIDs(apiRequest.FileIds)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	language, err := mapApiLanguage(apiRequest.Language)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	question := ragserver.Question{
+		Type:    ragserver.QuestionType(apiRequest.Type),
+		Content: apiRequest.Content,
+	}
+
+	response, err := a.ragServer.Query(ctx, principal, question, mapApiDocumentFilter(apiRequest, fileIDs, language))
+	if err != nil {
+		a.renderQueryError(w, err)
+		return
+	}
+
+	renderJSON(w, mapQueryResponse(question, response))
+}
+
+func (a *Adapter) renderQueryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ragserver.ErrNotFound):
+		renderJSONError(w, http.StatusNotFound, err)
+	case errors.Is(err, ragserver.ErrInvalidQuery), errors.Is(err, ragserver.ErrInvalidMetadata), errors.Is(err, ragserver.ErrInvalidFileStatus):
+		renderJSONError(w, http.StatusBadRequest, err)
+	default:
+		a.logger.Sugar().With("error", err).Error("error querying files")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error querying files: %w", err))
+	}
+}
+
+func mapApiDocumentFilter(apiRequest api.QueryParams, fileIDs []ragserver.FileID, language ragserver.Language) ragserver.DocumentFilter {
+	return ragserver.DocumentFilter{
+		FileIDs:  fileIDs,
+		Language: language,
+		Metadata: mapApiMetadataFilter(apiRequest.Metadata),
+	}
+}
+
+func mapApiLanguage(apiLanguage *api.Language) (ragserver.Language, error) {
+	if apiLanguage == nil {
+		return "", nil
+	}
+	language := ragserver.Language(*apiLanguage)
+	if !language.Valid() {
+		return "", fmt.Errorf("unsupported language: %s", language)
+	}
+	return language, nil
+}
+
+func mapQueryResponse(question ragserver.Question, response ragserver.Response) api.QueryResponse {
+	apiResponse := api.QueryResponse{
+		Text:     string(response.Text),
+		Evidence: mapEvidence(response.Documents),
+	}
+
+	if question.Type == ragserver.QuestionTypeMetric {
+		apiResponse.Metric = &api.MetricValue{
+			Value: response.Metric.Value,
+			Unit:  api.String(response.Metric.Unit),
+		}
+	}
+	if question.Type == ragserver.QuestionTypeBoolean {
+		apiResponse.Boolean = api.Boolean(bool(response.Boolean))
+	}
+
+	return apiResponse
+}
+
+// Answer a single question using the given files, streaming the answer as Server-Sent Events
+// (POST /query/stream)
+func (a *Adapter) StreamQuery(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), queryTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	apiRequest := api.QueryParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fileIDs, err := mapApiFileIDs(apiRequest.FileIds)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	language, err := mapApiLanguage(apiRequest.Language)
+	if err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	question := ragserver.Question{
+		Type:    ragserver.QuestionType(apiRequest.Type),
+		Content: apiRequest.Content,
+	}
+
+	filter := mapApiDocumentFilter(apiRequest, fileIDs, language)
+
+	// Once the stream is open, the status can't be changed anymore
+	if err := a.ragServer.ValidateQuery(ctx, principal, question, filter); err != nil {
+		a.renderQueryError(w, err)
+		return
+	}
+
+	stream, err := newSSEWriter(w)
+	if err != nil {
+		renderJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response, err := a.ragServer.StreamQuery(ctx, principal, question, filter, func(text string) error {
+		return stream.writeEvent("partial", map[string]any{
+			"text": text,
+		})
+	})
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("error streaming query")
+		if err := stream.writeEvent("error", map[string]any{
+			"error": fmt.Sprintf("error querying files: %s", err),
+		}); err != nil {
+			a.logger.Sugar().With("error", err).Error("error writing error event")
+		}
+		return
+	}
+
+	if err := stream.writeEvent("response", mapQueryResponse(question, response)); err != nil {
+		a.logger.Sugar().With("error", err).Error("error writing response event")
+	}
+}
@@ -29,17 +29,32 @@ func (a *Adapter) CreateScreening(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fileIDs, err := mapApiFileIDs(apiRequest.FileIds)
-	if err != nil {
-		renderJSONError(w, http.StatusInternalServerError, err)
-		return
+	params := ragserver.ScreeningParams{
+		Questions: mapApiQuestions(apiRequest.Questions),
+	}
+	if apiRequest.FileIds != nil {
+		fileIDs, err := mapApiFileIDs(*apiRequest.FileIds)
+		if err != nil {
+			renderJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		params.FileIDs = fileIDs
+	}
+	if apiRequest.LogicalDocumentIds != nil {
+		documentIDs, err := mapApiLogicalDocumentIDs(*apiRequest.LogicalDocumentIds)
+		if err != nil {
+			renderJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		params.LogicalDocumentIDs = documentIDs
 	}
 
-	aScreening, err := a.ragServer.CreateScreening(ctx, principal, ragserver.ScreeningParams{
-		FileIDs:   fileIDs,
-		Questions: mapApiQuestions(apiRequest.Questions),
-	})
+	aScreening, err := a.ragServer.CreateScreening(ctx, principal, params)
 	if err != nil {
+		if errors.Is(err, ragserver.ErrInvalidLogicalDocument) {
+			renderJSONError(w, http.StatusBadRequest, err)
+			return
+		}
 		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error creating file: %w", err))
 		return
 	}
@@ -153,18 +168,42 @@ func mapAnswer(question *ragserver.Question, answer ragserver.Answer) (api.Answe
 	if question.Type == ragserver.QuestionTypeBoolean {
 		apiAnswer.Boolean = api.Boolean(bool(response.Boolean))
 	}
-	apiAnswer.Evidence = make([]api.Evidence, 0, len(response.Documents))
-	for _, doc := range response.Documents {
-		apiAnswer.Evidence = append(apiAnswer.Evidence, api.Evidence{
-			FileId: openapi_types.UUID(doc.FileID.UUID[0:16]),
-			Page:   int32(doc.Page),
-			Text:   doc.Content,
-		})
-	}
+	apiAnswer.Evidence = mapEvidence(response.Documents)
 
 	return apiAnswer, nil
 }
 
+func mapEvidence(documents []ragserver.Document) []api.Evidence {
+	evidence := make([]api.Evidence, 0, len(documents))
+	for _, doc := range documents {
+		anEvidence := api.Evidence{
+			FileId:      openapi_types.UUID(doc.FileID.UUID[0:16]),
+			Page:        int32(doc.Page),
+			Text:        doc.Content,
+			BoundingBox: mapBoundingBox(doc.BoundingBox),
+		}
+		if doc.LayoutType != "" {
+			anEvidence.LayoutType = &doc.LayoutType
+		}
+		evidence = append(evidence, anEvidence)
+	}
+	return evidence
+}
+
+func mapBoundingBox(box *ragserver.BoundingBox) *api.BoundingBox {
+	if box == nil {
+		return nil
+	}
+	return &api.BoundingBox{
+		Left:       box.Left,
+		Top:        box.Top,
+		Width:      box.Width,
+		Height:     box.Height,
+		PageWidth:  box.PageWidth,
+		PageHeight: box.PageHeight,
+	}
+}
+
 // List screenings
 // (GET /screenings)
 func (a *Adapter) ListScreenings(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/api"
+)
+
+// List topic set presets
+// (GET /topic-sets)
+func (a *Adapter) ListTopicSets(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	topicSets, err := a.ragServer.ListTopicSets(ctx, principal)
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("error listing topic sets")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error listing topic sets: %w", err))
+		return
+	}
+
+	apiResponse := api.TopicSets{
+		TopicSets: make([]api.TopicSet, 0, len(topicSets)),
+	}
+	for _, aTopicSet := range topicSets {
+		apiResponse.TopicSets = append(apiResponse.TopicSets, mapTopicSet(aTopicSet))
+	}
+
+	renderJSON(w, apiResponse)
+}
+
+// Get a topic set preset by name
+// (GET /topic-sets/{name})
+func (a *Adapter) GetTopicSet(w http.ResponseWriter, r *http.Request, name string) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	aTopicSet, err := a.ragServer.FindTopicSet(ctx, principal, name)
+	if err != nil {
+		if errors.Is(err, ragserver.ErrNotFound) {
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("topic set not found"))
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error finding topic set")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error finding topic set: %w", err))
+		return
+	}
+
+	renderJSON(w, mapTopicSet(aTopicSet))
+}
+
+// Create a topic set preset or replace topics of an existing one
+// (PUT /topic-sets/{name})
+func (a *Adapter) SaveTopicSet(w http.ResponseWriter, r *http.Request, name string) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	apiRequest := api.TopicSetParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	aTopicSet, err := a.ragServer.SaveTopicSet(ctx, principal, &ragserver.TopicSet{
+		Name:   name,
+		Topics: mapApiTopics(apiRequest.Topics),
+	})
+	if err != nil {
+		if errors.Is(err, ragserver.ErrInvalidTopics) {
+			renderJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error saving topic set")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error saving topic set: %w", err))
+		return
+	}
+
+	renderJSON(w, mapTopicSet(aTopicSet))
+}
+
+// Delete a topic set preset
+// (DELETE /topic-sets/{name})
+func (a *Adapter) DeleteTopicSet(w http.ResponseWriter, r *http.Request, name string) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	if err := a.ragServer.DeleteTopicSet(ctx, principal, name); err != nil {
+		if errors.Is(err, ragserver.ErrNotFound) {
+			renderJSONError(w, http.StatusNotFound, fmt.Errorf("topic set not found"))
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error deleting topic set")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error deleting topic set: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// topicSelectionFromForm reads a topic set preset name or a JSON array of topics from multipart form fields.
+func topicSelectionFromForm(r *http.Request) (ragserver.TopicSelection, error) {
+	selection := ragserver.TopicSelection{
+		Preset: r.FormValue("topic_set"),
+	}
+	if topics := r.FormValue("topics"); topics != "" {
+		var apiTopics []api.Topic
+		if err := json.Unmarshal([]byte(topics), &apiTopics); err != nil {
+			return ragserver.TopicSelection{}, fmt.Errorf("%w: %v", ragserver.ErrInvalidTopics, err)
+		}
+		selection.Topics = mapApiTopics(apiTopics)
+	}
+	return selection, nil
+}
+
+func mapApiTopicSelection(topicSet *string, topics *[]api.Topic) ragserver.TopicSelection {
+	var selection ragserver.TopicSelection
+	if topicSet != nil {
+		selection.Preset = *topicSet
+	}
+	if topics != nil {
+		selection.Topics = mapApiTopics(*topics)
+	}
+	return selection
+}
+
+func mapApiTopics(apiTopics []api.Topic) ragserver.RelevantTopics {
+	topics := make(ragserver.RelevantTopics, 0, len(apiTopics))
+	for _, apiTopic := range apiTopics {
+		aTopic := ragserver.Topic{
+			Name: apiTopic.Name,
+		}
+		if apiTopic.Description != nil {
+			aTopic.Description = *apiTopic.Description
+		}
+		if apiTopic.Keywords != nil {
+			aTopic.Keywords = *apiTopic.Keywords
+		}
+		if apiTopic.Patterns != nil {
+			aTopic.Patterns = *apiTopic.Patterns
+		}
+		if apiTopic.ExcludeKeywords != nil {
+			aTopic.ExcludeKeywords = *apiTopic.ExcludeKeywords
+		}
+		if apiTopic.MinMatches != nil {
+			aTopic.MinMatches = *apiTopic.MinMatches
+		}
+		topics = append(topics, aTopic)
+	}
+	return topics
+}
+
+func mapTopicSet(aTopicSet *ragserver.TopicSet) api.TopicSet {
+	return api.TopicSet{
+		Name:      aTopicSet.Name,
+		Topics:    mapTopics(aTopicSet.Topics),
+		CreatedAt: aTopicSet.Created,
+		UpdatedAt: aTopicSet.Updated,
+	}
+}
+
+func mapFileTopics(aTopicSet *ragserver.TopicSet) *api.FileTopics {
+	if aTopicSet == nil {
+		return nil
+	}
+	apiTopics := &api.FileTopics{
+		Topics: mapTopics(aTopicSet.Topics),
+	}
+	if aTopicSet.Name != "" {
+		apiTopics.Name = &aTopicSet.Name
+	}
+	return apiTopics
+}
+
+func mapTopics(topics ragserver.RelevantTopics) []api.Topic {
+	apiTopics := make([]api.Topic, 0, len(topics))
+	for _, aTopic := range topics {
+		apiTopic := api.Topic{
+			Name: aTopic.Name,
+		}
+		if aTopic.Description != "" {
+			apiTopic.Description = &aTopic.Description
+		}
+		if len(aTopic.Keywords) > 0 {
+			apiTopic.Keywords = &aTopic.Keywords
+		}
+		if len(aTopic.Patterns) > 0 {
+			apiTopic.Patterns = &aTopic.Patterns
+		}
+		if len(aTopic.ExcludeKeywords) > 0 {
+			apiTopic.ExcludeKeywords = &aTopic.ExcludeKeywords
+		}
+		if aTopic.MinMatches > 0 {
+			apiTopic.MinMatches = &aTopic.MinMatches
+		}
+		apiTopics = append(apiTopics, apiTopic)
+	}
+	return apiTopics
+}
@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gofrs/uuid/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/api"
+)
+
+const uploadChunkContentType = "application/offset+octet-stream"
+
+// Start a resumable upload of a file, contents are sent in chunks with PATCH requests
+// (POST /uploads)
+func (a *Adapter) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	apiRequest := api.UploadParams{}
+	if err := readRequestJSON(r, &apiRequest); err != nil {
+		renderJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	anUpload, err := a.ragServer.CreateUpload(ctx, principal, apiRequest.FileName, apiRequest.Size, mapApiFileParams(apiRequest.TopicSet, apiRequest.Topics, apiRequest.Metadata))
+	if err != nil {
+		a.renderUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", r.URL.JoinPath(anUpload.ID.String()).Path)
+	setUploadHeaders(w, anUpload)
+	w.WriteHeader(http.StatusCreated)
+	renderJSON(w, mapUpload(anUpload))
+}
+
+// Get the offset to resume an upload from
+// (HEAD /uploads/{id})
+func (a *Adapter) GetUploadOffset(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	uploadID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid upload ID")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	anUpload, err := a.ragServer.FindUpload(ctx, principal, ragserver.UploadID{UUID: uploadID})
+	if err != nil {
+		if errors.Is(err, ragserver.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		a.logger.Sugar().With("error", err).Error("error finding upload")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Offset must always be fetched from the server
+	w.Header().Set("Cache-Control", "no-store")
+	setUploadHeaders(w, anUpload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Append a chunk to an upload
+// (PATCH /uploads/{id})
+func (a *Adapter) PatchUpload(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params api.PatchUploadParams) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), uploadTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	uploadID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid upload ID")
+		renderJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid upload ID: %w", err))
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != uploadChunkContentType {
+		renderJSONError(w, http.StatusUnsupportedMediaType, fmt.Errorf("expect %s Content-Type", uploadChunkContentType))
+		return
+	}
+
+	anUpload, err := a.ragServer.WriteUpload(ctx, principal, ragserver.UploadID{UUID: uploadID}, params.UploadOffset, r.Body)
+	// Bytes received before an error are kept, the offset tells the client where to resume from
+	if anUpload != nil {
+		setUploadHeaders(w, anUpload)
+	}
+	if err != nil {
+		a.renderUploadError(w, err)
+		return
+	}
+
+	renderJSON(w, mapUpload(anUpload))
+}
+
+// Cancel an upload
+// (DELETE /uploads/{id})
+func (a *Adapter) DeleteUploadById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID) {
+	var (
+		ctx, cancel = context.WithTimeout(r.Context(), defaultTimeout)
+		principal   = a.principalFromRequest(r)
+	)
+	defer cancel()
+
+	uploadID, err := uuid.FromString(id.String())
+	if err != nil {
+		a.logger.Sugar().With("error", err).Error("invalid upload ID")
+		renderJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid upload ID: %w", err))
+		return
+	}
+
+	if err := a.ragServer.DeleteUpload(ctx, principal, ragserver.UploadID{UUID: uploadID}); err != nil {
+		a.renderUploadError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *Adapter) renderUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ragserver.ErrNotFound):
+		renderJSONError(w, http.StatusNotFound, fmt.Errorf("upload not found"))
+	case errors.Is(err, ragserver.ErrInvalidUpload), errors.Is(err, ragserver.ErrInvalidTopics), errors.Is(err, ragserver.ErrInvalidMetadata):
+		renderJSONError(w, http.StatusBadRequest, err)
+	case errors.Is(err, ragserver.ErrUploadOffsetMismatch), errors.Is(err, ragserver.ErrUploadCompleted), errors.Is(err, ragserver.ErrUploadLocked):
+		renderJSONError(w, http.StatusConflict, err)
+	case errors.Is(err, ragserver.ErrUploadExpired):
+		renderJSONError(w, http.StatusGone, err)
+	case errors.Is(err, ragserver.ErrFileTooLarge):
+		renderJSONError(w, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, ragserver.ErrInvalidFileType):
+		renderJSONError(w, http.StatusUnsupportedMediaType, err)
+	default:
+		a.logger.Sugar().With("error", err).Error("error handling upload")
+		renderJSONError(w, http.StatusInternalServerError, fmt.Errorf("error handling upload: %w", err))
+	}
+}
+
+func setUploadHeaders(w http.ResponseWriter, anUpload *ragserver.Upload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(anUpload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(anUpload.Size, 10))
+	if !anUpload.Completed() {
+		w.Header().Set("Upload-Expires", anUpload.Expires.UTC().Format(http.TimeFormat))
+	}
+}
+
+func mapUpload(anUpload *ragserver.Upload) api.Upload {
+	apiUpload := api.Upload{
+		Id:        openapi_types.UUID(anUpload.ID.UUID[0:16]),
+		FileName:  anUpload.FileName,
+		Size:      anUpload.Size,
+		Offset:    anUpload.Offset,
+		CreatedAt: anUpload.Created,
+		UpdatedAt: anUpload.Updated,
+	}
+	if anUpload.Completed() {
+		fileID := openapi_types.UUID(anUpload.FileID.UUID[0:16])
+		apiUpload.FileId = &fileID
+	}
+	return apiUpload
+}
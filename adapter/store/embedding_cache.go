@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// Embeddings are selected and inserted in batches, so large files stay well within the limit
+// of 65535 bind parameters of a Postgres statement
+const embeddingBatchSize = 1000
+
+func (a *Adapter) FindEmbeddings(ctx context.Context, embedder, model string, hashes ...string) (map[string]ragserver.Vector, error) {
+	if len(hashes) < 1 {
+		return nil, nil
+	}
+
+	vectors := make(map[string]ragserver.Vector, len(hashes))
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		for batch := range slices.Chunk(hashes, embeddingBatchSize) {
+			if err := selectEmbeddings(ctx, tx, selectEmbeddingsQuery{embedder: embedder, model: model, hashes: batch}, vectors); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+func selectEmbeddings(ctx context.Context, tx *sql.Tx, q selectEmbeddingsQuery, vectors map[string]ragserver.Vector) error {
+	query, args := q.SQL()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("select embeddings query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			hash   string
+			vector []byte
+		)
+		if err := rows.Scan(&hash, &vector); err != nil {
+			return fmt.Errorf("scan embedding failed: %w", err)
+		}
+		vectors[hash] = bytesToVector(vector)
+	}
+
+	return rows.Err()
+}
+
+type selectEmbeddingsQuery struct {
+	embedder string
+	model    string
+	hashes   []string
+}
+
+func (q selectEmbeddingsQuery) SQL() (string, []any) {
+	query := `
+		select "hash", "vector"
+		from "ragserver"."embedding_cache"
+		where "embedder" = ? and "model" = ? and "hash" in (?`
+	args := make([]any, 0, len(q.hashes)+2)
+	args = append(args, q.embedder, q.model, q.hashes[0])
+	for _, hash := range q.hashes[1:] {
+		query += `, ?`
+		args = append(args, hash)
+	}
+	query += `)`
+
+	return toPostgresParams(query), args
+}
+
+// SaveEmbeddings caches vectors by hash, vectors which are already cached are kept.
+func (a *Adapter) SaveEmbeddings(ctx context.Context, embedder, model string, vectors map[string]ragserver.Vector) error {
+	if len(vectors) < 1 {
+		return nil
+	}
+
+	// Sorted so concurrent inserts of the same hashes lock rows in the same order
+	hashes := slices.Sorted(maps.Keys(vectors))
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		for batch := range slices.Chunk(hashes, embeddingBatchSize) {
+			batchVectors := make(map[string]ragserver.Vector, len(batch))
+			for _, hash := range batch {
+				batchVectors[hash] = vectors[hash]
+			}
+			if err := execQuery(ctx, tx, insertEmbeddingsQuery{embedder: embedder, model: model, vectors: batchVectors}); err != nil {
+				return fmt.Errorf("exec insert embeddings query failed: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type insertEmbeddingsQuery struct {
+	embedder string
+	model    string
+	vectors  map[string]ragserver.Vector
+}
+
+func (q insertEmbeddingsQuery) SQL() (string, []any) {
+	if len(q.vectors) == 0 {
+		return "", nil
+	}
+
+	// Sorted so concurrent inserts of the same hashes lock rows in the same order
+	hashes := slices.Sorted(maps.Keys(q.vectors))
+
+	query := `
+		insert into "ragserver"."embedding_cache" (
+			"embedder",
+			"model",
+			"hash",
+			"vector"
+		)
+		values (?, ?, ?, ?)
+	`
+	args := make([]any, 0, len(hashes)*4)
+	args = append(args, q.embedder, q.model, hashes[0], vectorToBytes(q.vectors[hashes[0]]))
+	for _, hash := range hashes[1:] {
+		query += `, (?, ?, ?, ?)`
+		args = append(args, q.embedder, q.model, hash, vectorToBytes(q.vectors[hash]))
+	}
+	query += ` on conflict ("embedder", "model", "hash") do nothing`
+
+	return toPostgresParams(query), args
+}
+
+func vectorToBytes(vector ragserver.Vector) []byte {
+	buf := make([]byte, len(vector)*4)
+	for i, f := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func bytesToVector(buf []byte) ragserver.Vector {
+	vector := make(ragserver.Vector, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vector
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+func (s *StoreTestSuite) TestEmbeddingCache() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	vectors := map[string]ragserver.Vector{
+		"hash1": {0.1, -0.2, 0.3},
+		"hash2": {1.5, 2.5, -3.5},
+	}
+
+	cached, err := s.adapter.FindEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", "hash1", "hash2")
+	s.Require().NoError(err)
+	s.Empty(cached)
+
+	s.Require().NoError(s.adapter.SaveEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", vectors), "error saving embeddings")
+
+	s.Run("Find cached embeddings", func() {
+		cached, err := s.adapter.FindEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", "hash1", "hash2", "hash3")
+		s.Require().NoError(err)
+		s.Equal(vectors, cached)
+	})
+
+	s.Run("Embeddings of another model are not found", func() {
+		cached, err := s.adapter.FindEmbeddings(ctx, "hugot", "another-model", "hash1")
+		s.Require().NoError(err)
+		s.Empty(cached)
+	})
+
+	s.Run("Cached embeddings are kept", func() {
+		s.Require().NoError(s.adapter.SaveEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", map[string]ragserver.Vector{
+			"hash1": {9, 9, 9},
+			"hash3": {0.5},
+		}), "error saving embeddings")
+
+		cached, err := s.adapter.FindEmbeddings(ctx, "hugot", "all-MiniLM-L6-v2", "hash1", "hash3")
+		s.Require().NoError(err)
+		s.Equal(map[string]ragserver.Vector{
+			"hash1": vectors["hash1"],
+			"hash3": {0.5},
+		}, cached)
+	})
+}
+
+func (s *StoreTestSuite) TestEmbeddingCache_ManyEmbeddings() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	// More hashes than fit the bind parameters of a single statement
+	var (
+		vectors = make(map[string]ragserver.Vector, 20000)
+		hashes  = make([]string, 0, 20000)
+	)
+	for i := range 20000 {
+		hash := fmt.Sprintf("many%d", i)
+		vectors[hash] = ragserver.Vector{float32(i)}
+		hashes = append(hashes, hash)
+	}
+
+	s.Require().NoError(s.adapter.SaveEmbeddings(ctx, "hugot", "many-model", vectors), "error saving embeddings")
+
+	cached, err := s.adapter.FindEmbeddings(ctx, "hugot", "many-model", hashes...)
+	s.Require().NoError(err)
+	s.Equal(vectors, cached)
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/gofrs/uuid/v5"
+
 	"github.com/RichardKnop/ragserver"
 	"github.com/RichardKnop/ragserver/pkg/authz"
 )
@@ -51,15 +53,27 @@ func (q insertFilesQuery) SQL() (string, []any) {
 			"extension",
 			"file_size", 
 			"file_hash",
+			"source_url",
 			"embedder",
 			"retriever",
+			"chunker",
 			"status",
+			"topics",
+			"language",
+			"stage",
+			"pages",
+			"documents",
+			"documents_embedded",
+			"attempts",
+			"next_attempt",
+			"reprocessing",
+			"run",
 			"created",
 			"updated"
 		)
-		values (?, ?, ?, ?, ?, ?, ?, ?, ?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?)			
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)			
 	`
-	args := make([]any, 0, len(q.files)*13)
+	args := make([]any, 0, len(q.files)*24)
 	args = append(
 		args,
 		q.files[0].ID,
@@ -69,14 +83,26 @@ func (q insertFilesQuery) SQL() (string, []any) {
 		q.files[0].Extension,
 		q.files[0].Size,
 		q.files[0].Hash,
+		sql.NullString{String: q.files[0].SourceURL, Valid: q.files[0].SourceURL != ""},
 		q.files[0].Embedder,
 		q.files[0].Retriever,
+		q.files[0].Chunker,
 		q.files[0].Status,
+		nullTopicSetValue{topicSet: q.files[0].Topics},
+		q.files[0].Language,
+		q.files[0].Stage,
+		q.files[0].Progress.Pages,
+		q.files[0].Progress.Documents,
+		q.files[0].Progress.DocumentsEmbedded,
+		q.files[0].Attempts,
+		sql.NullTime{Time: q.files[0].NextAttempt, Valid: !q.files[0].NextAttempt.IsZero()},
+		q.files[0].Reprocessing,
+		q.files[0].Run,
 		q.files[0].Created,
 		q.files[0].Updated,
 	)
 	for i := range q.files[1:] {
-		query += `, (?, ?, ?, ?, ?, ?, ?, ?, ?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?)`
+		query += `, (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 		args = append(
 			args,
 			q.files[i+1].ID,
@@ -86,9 +112,21 @@ func (q insertFilesQuery) SQL() (string, []any) {
 			q.files[i+1].Extension,
 			q.files[i+1].Size,
 			q.files[i+1].Hash,
+			sql.NullString{String: q.files[i+1].SourceURL, Valid: q.files[i+1].SourceURL != ""},
 			q.files[i+1].Embedder,
 			q.files[i+1].Retriever,
+			q.files[i+1].Chunker,
 			q.files[i+1].Status,
+			nullTopicSetValue{topicSet: q.files[i+1].Topics},
+			q.files[i+1].Language,
+			q.files[i+1].Stage,
+			q.files[i+1].Progress.Pages,
+			q.files[i+1].Progress.Documents,
+			q.files[i+1].Progress.DocumentsEmbedded,
+			q.files[i+1].Attempts,
+			sql.NullTime{Time: q.files[i+1].NextAttempt, Valid: !q.files[i+1].NextAttempt.IsZero()},
+			q.files[i+1].Reprocessing,
+			q.files[i+1].Run,
 			q.files[i+1].Created,
 			q.files[i+1].Updated,
 		)
@@ -101,9 +139,21 @@ func (q insertFilesQuery) SQL() (string, []any) {
 			"extension"=excluded."extension",
 			"file_size"=excluded."file_size",
 			"file_hash"=excluded."file_hash",
+			"source_url"=excluded."source_url",
 			"embedder"=excluded."embedder",
 			"retriever"=excluded."retriever",
+			"chunker"=excluded."chunker",
 			"status"=excluded."status",
+			"topics"=excluded."topics",
+			"language"=excluded."language",
+			"stage"=excluded."stage",
+			"pages"=excluded."pages",
+			"documents"=excluded."documents",
+			"documents_embedded"=excluded."documents_embedded",
+			"attempts"=excluded."attempts",
+			"next_attempt"=excluded."next_attempt",
+			"reprocessing"=excluded."reprocessing",
+			"run"=excluded."run",
 			"updated"=excluded."updated"
 	`
 	return toPostgresParams(query), args
@@ -122,29 +172,45 @@ func (q insertFileStatusEventsQuery) SQL() (string, []any) {
 		insert into "ragserver"."file_status_evt" (
 			"file", 
 			"status",
+			"run",
+			"attempt",
+			"stage",
 			"message",
 			"created"
 		)
-		values (?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?)
+		values (?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?, ?, ?, ?)
 	`
-	args := make([]any, 0, len(q.files)*4)
+	args := make([]any, 0, len(q.files)*7)
 	args = append(
 		args,
 		q.files[0].ID,
 		q.files[0].Status,
+		q.files[0].Run,
+		q.files[0].Attempts,
+		q.files[0].Stage,
 		sql.NullString{String: q.files[0].StatusMessage, Valid: q.files[0].StatusMessage != ""},
-		q.files[0].Created,
+		q.files[0].Updated,
 	)
 	for i := range q.files[1:] {
-		query += `, (?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?)`
+		query += `, (?, (select "id" from "ragserver"."file_status" fs where fs."name" = ?), ?, ?, ?, ?, ?)`
 		args = append(
 			args,
 			q.files[i+1].ID,
 			q.files[i+1].Status,
+			q.files[i+1].Run,
+			q.files[i+1].Attempts,
+			q.files[i+1].Stage,
 			sql.NullString{String: q.files[i+1].StatusMessage, Valid: q.files[i+1].StatusMessage != ""},
-			q.files[i+1].Created,
+			q.files[i+1].Updated,
 		)
 	}
+	// Files saved again without changing status or stage, for example to report progress,
+	// replace the event. Reprocessed files start a new run so events of earlier runs are kept.
+	query += `
+		on conflict("file", "run", "status", "attempt", "stage") do update set
+			"message"=excluded."message",
+			"created"=excluded."created"
+	`
 
 	return toPostgresParams(query), args
 }
@@ -152,6 +218,7 @@ func (q insertFileStatusEventsQuery) SQL() (string, []any) {
 var (
 	validFileSortFields = []string{
 		`f."created"`,
+		`f."updated"`,
 	}
 	defaultFileSortParams = ragserver.SortParams{
 		By: `f."created"`, Order: ragserver.SortOrderDesc,
@@ -213,15 +280,29 @@ func (q selectFilesQuery) SQL() (string, []any) {
 			f."extension", 
 			f."file_size", 
 			f."file_hash",
+			f."source_url",
 			f."embedder",
 			f."retriever",
+			f."chunker",
 			fs."name" as "status",
 			fse."message" as "status_message",
+			f."topics",
+			f."language",
+			f."stage",
+			f."pages",
+			f."documents",
+			f."documents_embedded",
+			f."attempts",
+			f."next_attempt",
+			f."reprocessing",
+			f."run",
 			f."created",
-			f."updated"
+			f."updated",
+			` + selectFileMetadataColumn + `,
+			` + selectFileVersionColumns + `
 		from "ragserver"."file" f
 		inner join "file_status" fs on f."status" = fs."id"
-		inner join "file_status_evt" fse on fse."file" = f."id" and fse."status" = fs."id"
+		inner join "file_status_evt" fse on fse."file" = f."id" and fse."status" = fs."id" and fse."run" = f."run" and fse."attempt" = f."attempts" and fse."stage" = f."stage"
 	`
 
 	if !q.filter.ScreeningID.UUID.IsNil() {
@@ -230,6 +311,12 @@ func (q selectFilesQuery) SQL() (string, []any) {
 		`
 	}
 
+	if !q.filter.LogicalDocumentID.UUID.IsNil() {
+		query += `
+			inner join "logical_document_version" ldv on ldv."file" = f."id"
+		`
+	}
+
 	args := []any{}
 
 	// Add where clauses from the filter and/or partial if any
@@ -278,6 +365,23 @@ func fileFilterClauses(filter ragserver.FileFilter) (string, []any) {
 		args = append(args, filter.Retriever)
 	}
 
+	if filter.Chunker != "" {
+		clauses = append(clauses, `f."chunker" = ?`)
+		args = append(args, filter.Chunker)
+	}
+
+	switch {
+	case filter.NotEmbedder != "" && filter.NotRetriever != "":
+		clauses = append(clauses, `(f."embedder" <> ? or f."retriever" <> ?)`)
+		args = append(args, filter.NotEmbedder, filter.NotRetriever)
+	case filter.NotEmbedder != "":
+		clauses = append(clauses, `f."embedder" <> ?`)
+		args = append(args, filter.NotEmbedder)
+	case filter.NotRetriever != "":
+		clauses = append(clauses, `f."retriever" <> ?`)
+		args = append(args, filter.NotRetriever)
+	}
+
 	if filter.Status != "" {
 		clauses = append(clauses, `fs."name" = ?`)
 		args = append(args, filter.Status)
@@ -288,16 +392,32 @@ func fileFilterClauses(filter ragserver.FileFilter) (string, []any) {
 		args = append(args, filter.LastUpdatedBefore)
 	}
 
+	if !filter.NextAttemptBefore.IsZero() {
+		clauses = append(clauses, `f."next_attempt" < ?`)
+		args = append(args, filter.NextAttemptBefore)
+	}
+
 	if !filter.ScreeningID.UUID.IsNil() {
 		clauses = append(clauses, `sf."screening" = ?`)
 		args = append(args, filter.ScreeningID)
 	}
 
+	if !filter.LogicalDocumentID.UUID.IsNil() {
+		clauses = append(clauses, `ldv."document" = ?`)
+		args = append(args, filter.LogicalDocumentID)
+	}
+
 	if filter.Hash != "" {
-		clauses = append(clauses, `f."hash" = ?`)
+		clauses = append(clauses, `f."file_hash" = ?`)
 		args = append(args, filter.Hash)
 	}
 
+	if len(filter.Metadata) > 0 {
+		metadataClauses, metadataArgs := metadataFilterClauses(filter.Metadata)
+		clauses = append(clauses, metadataClauses...)
+		args = append(args, metadataArgs...)
+	}
+
 	if len(clauses) == 0 {
 		return "", nil
 	}
@@ -348,15 +468,29 @@ func (q findFileQuery) SQL() (string, []any) {
 			f."extension", 
 			f."file_size", 
 			f."file_hash",
+			f."source_url",
 			f."embedder",
 			f."retriever",
+			f."chunker",
 			fs."name" as "status",
 			fse."message" as "status_message",
+			f."topics",
+			f."language",
+			f."stage",
+			f."pages",
+			f."documents",
+			f."documents_embedded",
+			f."attempts",
+			f."next_attempt",
+			f."reprocessing",
+			f."run",
 			f."created",
-			f."updated"
+			f."updated",
+			` + selectFileMetadataColumn + `,
+			` + selectFileVersionColumns + `
 		from "ragserver"."file" f
 		inner join "file_status" fs on f."status" = fs."id"	
-		inner join "file_status_evt" fse on fse."file" = f."id" and fse."status" = fs."id" 
+		inner join "file_status_evt" fse on fse."file" = f."id" and fse."status" = fs."id" and fse."run" = f."run" and fse."attempt" = f."attempts" and fse."stage" = f."stage"
 		where f."id" = ?
 	`
 	args := []any{q.id}
@@ -375,7 +509,13 @@ func (q findFileQuery) SQL() (string, []any) {
 func scanFile(row Scannable) (*ragserver.File, error) {
 	var (
 		aFile         = new(ragserver.File)
+		sourceURL     = sql.NullString{}
 		statusMessage = sql.NullString{}
+		topics        nullTopicSetValue
+		metadata      metadataValue
+		document      uuid.NullUUID
+		version       sql.NullInt64
+		nextAttempt   sql.NullTime
 		created       sql.NullTime
 		updated       sql.NullTime
 	)
@@ -388,12 +528,27 @@ func scanFile(row Scannable) (*ragserver.File, error) {
 		&aFile.Extension,
 		&aFile.Size,
 		&aFile.Hash,
+		&sourceURL,
 		&aFile.Embedder,
 		&aFile.Retriever,
+		&aFile.Chunker,
 		&aFile.Status,
 		&statusMessage,
+		&topics,
+		&aFile.Language,
+		&aFile.Stage,
+		&aFile.Progress.Pages,
+		&aFile.Progress.Documents,
+		&aFile.Progress.DocumentsEmbedded,
+		&aFile.Attempts,
+		&nextAttempt,
+		&aFile.Reprocessing,
+		&aFile.Run,
 		&created,
 		&updated,
+		&metadata,
+		&document,
+		&version,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ragserver.ErrNotFound
@@ -401,9 +556,21 @@ func scanFile(row Scannable) (*ragserver.File, error) {
 		return nil, fmt.Errorf("scan file failed: %w", err)
 	}
 
+	if sourceURL.Valid {
+		aFile.SourceURL = sourceURL.String
+	}
 	if statusMessage.Valid {
 		aFile.StatusMessage = statusMessage.String
 	}
+	if nextAttempt.Valid {
+		aFile.NextAttempt = nextAttempt.Time.UTC()
+	}
+	aFile.Topics = topics.topicSet
+	aFile.Metadata = metadata.metadata
+	if document.Valid {
+		aFile.LogicalDocumentID = ragserver.LogicalDocumentID{UUID: document.UUID}
+		aFile.Version = int(version.Int64)
+	}
 
 	aFile.Created = created.Time.UTC()
 	aFile.Updated = updated.Time.UTC()
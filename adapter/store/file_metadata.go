@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// SaveFileMetadata replaces metadata of the files, keys not present on a file anymore are deleted.
+func (a *Adapter) SaveFileMetadata(ctx context.Context, files ...*ragserver.File) error {
+	if len(files) < 1 {
+		return nil
+	}
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQuery(ctx, tx, deleteFileMetadataQuery{files: files}); err != nil {
+			return fmt.Errorf("exec delete file metadata query failed: %w", err)
+		}
+
+		if err := execQuery(ctx, tx, insertFileMetadataQuery{files: files}); err != nil {
+			return fmt.Errorf("exec insert file metadata query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type deleteFileMetadataQuery struct {
+	files []*ragserver.File
+}
+
+func (q deleteFileMetadataQuery) SQL() (string, []any) {
+	if len(q.files) == 0 {
+		return "", nil
+	}
+
+	query := `delete from "ragserver"."file_metadata" where "file" in (?`
+	args := make([]any, 0, len(q.files))
+	args = append(args, q.files[0].ID)
+	for i := range q.files[1:] {
+		query += `, ?`
+		args = append(args, q.files[i+1].ID)
+	}
+	query += `)`
+
+	return toPostgresParams(query), args
+}
+
+type insertFileMetadataQuery struct {
+	files []*ragserver.File
+}
+
+func (q insertFileMetadataQuery) SQL() (string, []any) {
+	var (
+		values = []string{}
+		args   = []any{}
+	)
+	for _, aFile := range q.files {
+		for key, value := range aFile.Metadata {
+			values = append(values, `(?, ?, ?)`)
+			args = append(args, aFile.ID, key, value)
+		}
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	query := `
+		insert into "ragserver"."file_metadata" (
+			"file",
+			"key",
+			"value"
+		)
+		values ` + strings.Join(values, ", ")
+
+	return toPostgresParams(query), args
+}
+
+// selectFileMetadataColumn aggregates metadata of a file into a JSON object, null if it has none.
+const selectFileMetadataColumn = `(
+	select jsonb_object_agg(fm."key", fm."value")
+	from "ragserver"."file_metadata" fm
+	where fm."file" = f."id"
+) as "metadata"`
+
+// numericPattern matches values which can be cast to numeric, other values never match a range.
+const numericPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+func metadataFilterClauses(filter ragserver.MetadataFilter) ([]string, []any) {
+	var (
+		clauses = []string{}
+		args    = []any{}
+	)
+
+	for _, condition := range filter {
+		clause := `exists (select 1 from "ragserver"."file_metadata" fm where fm."file" = f."id" and fm."key" = ?`
+		args = append(args, condition.Key)
+
+		if condition.IsRange() {
+			// Values are cast only if they are numbers, the order of and conditions is not guaranteed
+			const value = `(case when fm."value" ~ ? then fm."value"::numeric end)`
+			if condition.Min != nil {
+				clause += ` and ` + value + ` >= ?`
+				args = append(args, numericPattern, *condition.Min)
+			}
+			if condition.Max != nil {
+				clause += ` and ` + value + ` <= ?`
+				args = append(args, numericPattern, *condition.Max)
+			}
+		} else {
+			clause += ` and fm."value" in (?` + strings.Repeat(`, ?`, len(condition.Values)-1) + `)`
+			for _, value := range condition.Values {
+				args = append(args, value)
+			}
+		}
+
+		clauses = append(clauses, clause+`)`)
+	}
+
+	return clauses, args
+}
+
+// metadataValue stores metadata aggregated by selectFileMetadataColumn or recorded on an upload as JSON.
+type metadataValue struct {
+	metadata ragserver.Metadata
+}
+
+func (v metadataValue) Value() (driver.Value, error) {
+	if v.metadata == nil {
+		return nil, nil
+	}
+	return marshalJSON(v.metadata)
+}
+
+func (v *metadataValue) Scan(src any) error {
+	if src == nil {
+		v.metadata = nil
+		return nil
+	}
+	return scanJSON(src, &v.metadata)
+}
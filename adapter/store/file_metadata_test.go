@@ -0,0 +1,134 @@
+package store
+
+import (
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/pkg/authz"
+	"github.com/RichardKnop/ragserver/ragservertest"
+)
+
+func (s *StoreTestSuite) TestSaveFileMetadata() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		anUpload = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		metadata = ragserver.Metadata{
+			"company":        "Acme",
+			"reporting_year": "2024",
+		}
+	)
+	aFile.Metadata = metadata
+	anUpload.Metadata = metadata
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(s.adapter.SaveFileMetadata(ctx, aFile), "error saving file metadata")
+	s.Require().NoError(s.adapter.SaveUploads(ctx, anUpload), "error saving upload")
+
+	savedFile, err := s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(metadata, savedFile.Metadata)
+
+	savedUpload, err := s.adapter.FindUpload(ctx, anUpload.ID, authz.NilPartial, false)
+	s.Require().NoError(err)
+	s.Equal(metadata, savedUpload.Metadata)
+
+	// Metadata is replaced, keys not present anymore are deleted
+	aFile.Metadata = ragserver.Metadata{"document_type": "annual report"}
+	s.Require().NoError(s.adapter.SaveFileMetadata(ctx, aFile), "error saving file metadata")
+
+	savedFile, err = s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(aFile.Metadata, savedFile.Metadata)
+
+	aFile.Metadata = nil
+	s.Require().NoError(s.adapter.SaveFileMetadata(ctx, aFile), "error saving file metadata")
+
+	savedFile, err = s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Nil(savedFile.Metadata)
+
+	// Files with metadata can be deleted, metadata is deleted along with them
+	aFile.Metadata = metadata
+	s.Require().NoError(s.adapter.SaveFileMetadata(ctx, aFile), "error saving file metadata")
+	s.Require().NoError(s.adapter.DeleteFiles(ctx, aFile), "error deleting file")
+}
+
+func (s *StoreTestSuite) TestListFiles_Metadata() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		file1 = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		file2 = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		file3 = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		min2023 = float64(2023)
+		max2023 = float64(2023)
+	)
+	file1.Metadata = ragserver.Metadata{"company": "Acme", "reporting_year": "2023"}
+	file2.Metadata = ragserver.Metadata{"company": "Globex", "reporting_year": "2024"}
+	file3.Metadata = ragserver.Metadata{"company": "Acme", "reporting_year": "unknown"}
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, file1, file2, file3), "error saving files")
+	s.Require().NoError(s.adapter.SaveFileMetadata(ctx, file1, file2, file3), "error saving file metadata")
+
+	testCases := []struct {
+		Name     string
+		Filter   ragserver.MetadataFilter
+		Expected []ragserver.FileID
+	}{
+		{
+			"Values of a key",
+			ragserver.MetadataFilter{{Key: "company", Values: []string{"Acme"}}},
+			[]ragserver.FileID{file1.ID, file3.ID},
+		},
+		{
+			"Any of the values",
+			ragserver.MetadataFilter{{Key: "company", Values: []string{"Acme", "Globex"}}},
+			[]ragserver.FileID{file1.ID, file2.ID, file3.ID},
+		},
+		{
+			"Range skips values which are not numbers",
+			ragserver.MetadataFilter{{Key: "reporting_year", Min: &min2023}},
+			[]ragserver.FileID{file1.ID, file2.ID},
+		},
+		{
+			"All conditions",
+			ragserver.MetadataFilter{
+				{Key: "company", Values: []string{"Acme"}},
+				{Key: "reporting_year", Min: &min2023, Max: &max2023},
+			},
+			[]ragserver.FileID{file1.ID},
+		},
+		{
+			"Missing key",
+			ragserver.MetadataFilter{{Key: "document_type", Values: []string{"annual report"}}},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.Name, func() {
+			files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{Metadata: tc.Filter}, authz.NilPartial, ragserver.SortParams{})
+			s.Require().NoError(err)
+
+			var ids []ragserver.FileID
+			for _, aFile := range files {
+				ids = append(ids, aFile.ID)
+			}
+			s.ElementsMatch(tc.Expected, ids)
+		})
+	}
+}
@@ -22,6 +22,7 @@ func (s *StoreTestSuite) TestFindFile() {
 			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
 			ragservertest.WithFileEmbedder("google-genai"),
 			ragservertest.WithFileRetriever("redis"),
+			ragservertest.WithFileSourceURL("https://example.com/report.pdf"),
 		)
 	)
 
@@ -103,6 +104,177 @@ func (s *StoreTestSuite) TestSaveFiles_Upsert() {
 	s.Greater(savedFile2.Updated, savedFile1.Updated)
 }
 
+func (s *StoreTestSuite) TestSaveFiles_Reprocess() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now   = time.Now().UTC()
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+			ragservertest.WithFileStatus(ragserver.FileStatusUploaded),
+			ragservertest.WithFileCreated(now),
+			ragservertest.WithFileUpdated(now),
+		)
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+
+	// Go through the lifecycle once and then back to the uploaded status
+	for i, status := range []ragserver.FileStatus{
+		ragserver.FileStatusUploaded,
+		ragserver.FileStatusProcessing,
+		ragserver.FileStatusProcessedSuccessfully,
+		ragserver.FileStatusUploaded,
+	} {
+		aFile.Status = status
+		if i == 3 {
+			aFile.Reprocessing = true
+			aFile.Run = 1
+		}
+		aFile.Updated = now.Add(time.Duration(i) * time.Minute).UTC()
+		s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	}
+
+	savedFile, err := s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(aFile, savedFile)
+	s.Equal(ragserver.FileStatusUploaded, savedFile.Status)
+	s.True(savedFile.Reprocessing)
+
+	// Events of the first run are kept
+	var events int
+	err = s.db.QueryRowContext(ctx, `select count(*) from "ragserver"."file_status_evt" where "file" = $1`, aFile.ID).Scan(&events)
+	s.Require().NoError(err)
+	s.Equal(4, events)
+}
+
+func (s *StoreTestSuite) TestSaveFiles_Retry() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now   = time.Now().UTC().Truncate(time.Microsecond)
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+			ragservertest.WithFileStatus(ragserver.FileStatusUploaded),
+			ragservertest.WithFileCreated(now),
+			ragservertest.WithFileUpdated(now),
+		)
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	// First attempt fails and a retry is scheduled
+	s.Require().NoError(aFile.StartProcessing(now.Add(time.Minute)))
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(aFile.CompleteWithStatus(ragserver.FileStatusProcessingFailed, "503 Service Unavailable", now.Add(2*time.Minute)))
+	aFile.NextAttempt = now.Add(3 * time.Minute)
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	savedFile, err := s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(aFile, savedFile)
+	s.Equal(1, savedFile.Attempts)
+	s.Equal("503 Service Unavailable", savedFile.StatusMessage)
+
+	s.Run("Filter by next attempt before", func() {
+		files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			Status:            ragserver.FileStatusProcessingFailed,
+			NextAttemptBefore: now.Add(2 * time.Minute),
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Empty(files)
+
+		files, err = s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			Status:            ragserver.FileStatusProcessingFailed,
+			NextAttemptBefore: now.Add(4 * time.Minute),
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Len(files, 1)
+	})
+
+	// Second attempt fails permanently, events of both attempts are kept
+	s.Require().NoError(aFile.StartProcessing(now.Add(4 * time.Minute)))
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(aFile.CompleteWithStatus(ragserver.FileStatusProcessingFailed, "invalid PDF", now.Add(5*time.Minute)))
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	savedFile, err = s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(aFile, savedFile)
+	s.Equal(2, savedFile.Attempts)
+	s.Equal("invalid PDF", savedFile.StatusMessage)
+	s.True(savedFile.NextAttempt.IsZero())
+
+	var events int
+	err = s.db.QueryRowContext(ctx, `select count(*) from "ragserver"."file_status_evt" where "file" = $1`, aFile.ID).Scan(&events)
+	s.Require().NoError(err)
+	s.Equal(5, events)
+}
+
+func (s *StoreTestSuite) TestSaveFiles_Progress() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now   = time.Now().UTC().Truncate(time.Microsecond)
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+			ragservertest.WithFileStatus(ragserver.FileStatusUploaded),
+			ragservertest.WithFileCreated(now),
+			ragservertest.WithFileUpdated(now),
+		)
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	s.Require().NoError(aFile.StartProcessing(now.Add(time.Minute)))
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(aFile.StartStage(ragserver.FileStageExtracting, now.Add(2*time.Minute)))
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(aFile.StartStage(ragserver.FileStageEmbedding, now.Add(3*time.Minute)))
+	aFile.Progress = ragserver.FileProgress{Pages: 3, Documents: 200}
+	aFile.Language = ragserver.LanguageGerman
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	// Saving progress within a stage replaces the stage event
+	aFile.Progress.DocumentsEmbedded = 100
+	aFile.Updated = now.Add(4 * time.Minute)
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	savedFile, err := s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(aFile, savedFile)
+	s.Equal(ragserver.FileStageEmbedding, savedFile.Stage)
+	s.Equal(ragserver.FileProgress{Pages: 3, Documents: 200, DocumentsEmbedded: 100}, savedFile.Progress)
+	s.Equal(ragserver.LanguageGerman, savedFile.Language)
+
+	s.Require().NoError(aFile.StartStage(ragserver.FileStageIndexing, now.Add(5*time.Minute)))
+	aFile.Progress.DocumentsEmbedded = 200
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(aFile.CompleteWithStatus(ragserver.FileStatusProcessedSuccessfully, "", now.Add(6*time.Minute)))
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	savedFile, err = s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(aFile, savedFile)
+
+	var stages []string
+	rows, err := s.db.QueryContext(ctx, `select "stage" from "ragserver"."file_status_evt" where "file" = $1 order by "created"`, aFile.ID)
+	s.Require().NoError(err)
+	defer rows.Close()
+	for rows.Next() {
+		var stage string
+		s.Require().NoError(rows.Scan(&stage))
+		stages = append(stages, stage)
+	}
+	s.Require().NoError(rows.Err())
+	s.Equal([]string{"", "READING", "EXTRACTING", "EMBEDDING", "INDEXING", "INDEXING"}, stages)
+}
+
 func (s *StoreTestSuite) TestListFiles() {
 	ctx, cancel := testContext()
 	defer cancel()
@@ -120,6 +292,8 @@ func (s *StoreTestSuite) TestListFiles() {
 			ragservertest.WithFileUpdated(now.Add(-1*time.Hour).UTC()),
 			ragservertest.WithFileEmbedder("google-genai"),
 			ragservertest.WithFileRetriever("weaviate"),
+			ragservertest.WithFileChunker("sentence"),
+			ragservertest.WithFileHash("same-hash"),
 		)
 		file2 = gen.File(
 			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
@@ -128,6 +302,8 @@ func (s *StoreTestSuite) TestListFiles() {
 			ragservertest.WithFileUpdated(now),
 			ragservertest.WithFileEmbedder("google-genai"),
 			ragservertest.WithFileRetriever("redis"),
+			ragservertest.WithFileChunker("sentence-window:3"),
+			ragservertest.WithFileHash("same-hash"),
 		)
 	)
 
@@ -158,6 +334,44 @@ func (s *StoreTestSuite) TestListFiles() {
 		s.Equal(file1, files[0])
 	})
 
+	s.Run("Filter by different embedder or retriever", func() {
+		files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			NotEmbedder:  "google-genai",
+			NotRetriever: "redis",
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Len(files, 1)
+		s.Equal(file1, files[0])
+
+		files, err = s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			NotEmbedder: "google-genai",
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Empty(files)
+	})
+
+	s.Run("Filter by hash and chunker", func() {
+		files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			Hash: "same-hash",
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Len(files, 2)
+
+		files, err = s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			Hash:    "same-hash",
+			Chunker: "sentence",
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Len(files, 1)
+		s.Equal(file1, files[0])
+
+		files, err = s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			Hash: "other-hash",
+		}, authz.NilPartial, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.Empty(files)
+	})
+
 	s.Run("Filter by status", func() {
 		files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{
 			Status: ragserver.FileStatusProcessedSuccessfully,
@@ -184,6 +398,29 @@ func (s *StoreTestSuite) TestListFiles() {
 		s.Equal(file1, files[0])
 	})
 
+	s.Run("Sort by last updated", func() {
+		// Processed files with the same hash are listed like this to reuse their documents
+		files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{
+			Hash:    "same-hash",
+			Chunker: "sentence",
+			Status:  ragserver.FileStatusProcessedSuccessfully,
+		}, authz.NilPartial, ragserver.SortParams{
+			Limit: 10,
+			Order: ragserver.SortOrderDesc,
+			By:    `f."updated"`,
+		})
+		s.Require().NoError(err)
+		s.Len(files, 1)
+		s.Equal(file1, files[0])
+
+		files, err = s.adapter.ListFiles(ctx, ragserver.FileFilter{}, authz.NilPartial, ragserver.SortParams{
+			Order: ragserver.SortOrderAsc,
+			By:    `f."updated"`,
+		})
+		s.Require().NoError(err)
+		s.Equal([]*ragserver.File{file1, file2}, files)
+	})
+
 	s.Run("For update skip locked", func() {
 		files, err := s.adapter.ListFiles(ctx, ragserver.FileFilter{
 			Status: ragserver.FileStatusUploaded,
@@ -0,0 +1,313 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+// selectFileVersionColumns selects the logical document a file is a version of and its version,
+// both are null for files which are not versions of a logical document.
+const selectFileVersionColumns = `(
+	select v."document"
+	from "ragserver"."logical_document_version" v
+	where v."file" = f."id"
+) as "logical_document",
+(
+	select v."version"
+	from "ragserver"."logical_document_version" v
+	where v."file" = f."id"
+) as "version"`
+
+func (a *Adapter) SaveLogicalDocument(ctx context.Context, aDocument *ragserver.LogicalDocument) error {
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQueryCheckRowsAffected(ctx, tx, insertLogicalDocumentQuery{aDocument: aDocument}); err != nil {
+			return fmt.Errorf("exec insert logical document query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type insertLogicalDocumentQuery struct {
+	aDocument *ragserver.LogicalDocument
+}
+
+func (q insertLogicalDocumentQuery) SQL() (string, []any) {
+	query := `
+		insert into "ragserver"."logical_document" (
+			"id",
+			"author",
+			"name",
+			"last_version",
+			"created",
+			"updated"
+		)
+		values (?, ?, ?, ?, ?, ?)
+		on conflict("id") do update set
+			"name"=excluded."name",
+			"last_version"=excluded."last_version",
+			"updated"=excluded."updated"
+	`
+	args := []any{
+		q.aDocument.ID,
+		q.aDocument.AuthorID,
+		q.aDocument.Name,
+		q.aDocument.LastVersion,
+		q.aDocument.Created,
+		q.aDocument.Updated,
+	}
+	return toPostgresParams(query), args
+}
+
+func (a *Adapter) SaveLogicalDocumentVersions(ctx context.Context, aDocument *ragserver.LogicalDocument, files ...*ragserver.File) error {
+	if len(files) < 1 {
+		return nil
+	}
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQueryCheckRowsAffected(ctx, tx, insertLogicalDocumentVersionsQuery{aDocument: aDocument, files: files}); err != nil {
+			return fmt.Errorf("exec insert logical document versions query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type insertLogicalDocumentVersionsQuery struct {
+	aDocument *ragserver.LogicalDocument
+	files     []*ragserver.File
+}
+
+func (q insertLogicalDocumentVersionsQuery) SQL() (string, []any) {
+	if len(q.files) == 0 {
+		return "", nil
+	}
+
+	query := `
+		insert into "ragserver"."logical_document_version" (
+			"document",
+			"file",
+			"version",
+			"created"
+		)
+		values (?, ?, ?, ?)
+	`
+	args := make([]any, 0, len(q.files)*4)
+	args = append(
+		args,
+		q.aDocument.ID,
+		q.files[0].ID,
+		q.files[0].Version,
+		q.aDocument.Updated,
+	)
+	for i := range q.files[1:] {
+		query += `, (?, ?, ?, ?)`
+		args = append(
+			args,
+			q.aDocument.ID,
+			q.files[i+1].ID,
+			q.files[i+1].Version,
+			q.aDocument.Updated,
+		)
+	}
+
+	return toPostgresParams(query), args
+}
+
+var (
+	validLogicalDocumentSortFields = []string{
+		`d."created"`,
+		`d."name"`,
+	}
+	defaultLogicalDocumentSortParams = ragserver.SortParams{
+		By: `d."created"`, Order: ragserver.SortOrderDesc,
+		Limit: 100,
+	}
+)
+
+func (a *Adapter) ListLogicalDocuments(ctx context.Context, params ragserver.SortParams) ([]*ragserver.LogicalDocument, error) {
+	var documents []*ragserver.LogicalDocument
+
+	// Validate params
+	if !params.Empty() && !params.Valid(validLogicalDocumentSortFields) {
+		return nil, fmt.Errorf("invalid sort params: %v", params)
+	}
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := selectLogicalDocumentsQuery{params: params}.SQL()
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("select logical documents query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			aDocument, err := scanLogicalDocument(rows)
+			if err != nil {
+				return err
+			}
+			documents = append(documents, aDocument)
+		}
+
+		rows.Close()
+
+		for _, aDocument := range documents {
+			aDocument.Versions, err = selectLogicalDocumentVersions(ctx, tx, aDocument.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (a *Adapter) FindLogicalDocument(ctx context.Context, id ragserver.LogicalDocumentID, lock bool) (*ragserver.LogicalDocument, error) {
+	var aDocument *ragserver.LogicalDocument
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := selectLogicalDocumentsQuery{id: id, lock: lock}.SQL()
+
+		var err error
+		aDocument, err = scanLogicalDocument(tx.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			return err
+		}
+
+		aDocument.Versions, err = selectLogicalDocumentVersions(ctx, tx, aDocument.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return aDocument, nil
+}
+
+// selectLogicalDocumentVersions selects files of all versions of the logical document from the oldest,
+// including files processed by a different embedder or retriever.
+func selectLogicalDocumentVersions(ctx context.Context, tx *sql.Tx, id ragserver.LogicalDocumentID) ([]*ragserver.File, error) {
+	query, args := selectFilesQuery{
+		filter:  ragserver.FileFilter{LogicalDocumentID: id},
+		partial: authz.NilPartial,
+		params:  ragserver.SortParams{By: `ldv."version"`, Order: ragserver.SortOrderAsc},
+	}.SQL()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select logical document versions query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*ragserver.File
+	for rows.Next() {
+		aFile, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, aFile)
+	}
+
+	return files, rows.Err()
+}
+
+type selectLogicalDocumentsQuery struct {
+	id     ragserver.LogicalDocumentID
+	lock   bool
+	params ragserver.SortParams
+}
+
+func (q selectLogicalDocumentsQuery) SQL() (string, []any) {
+	query := `
+		select
+			d."id",
+			d."author",
+			d."name",
+			d."last_version",
+			d."created",
+			d."updated"
+		from "ragserver"."logical_document" d
+	`
+	var args []any
+	if !q.id.UUID.IsNil() {
+		query += ` where d."id" = ?`
+		args = append(args, q.id)
+	} else {
+		if q.params.Empty() {
+			q.params = defaultLogicalDocumentSortParams
+		}
+		query += q.params.SQL()
+	}
+
+	if q.lock {
+		query += " for update"
+	}
+
+	return toPostgresParams(query), args
+}
+
+func scanLogicalDocument(row Scannable) (*ragserver.LogicalDocument, error) {
+	var (
+		aDocument = new(ragserver.LogicalDocument)
+		created   sql.NullTime
+		updated   sql.NullTime
+	)
+
+	if err := row.Scan(
+		&aDocument.ID,
+		&aDocument.AuthorID,
+		&aDocument.Name,
+		&aDocument.LastVersion,
+		&created,
+		&updated,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ragserver.ErrNotFound
+		}
+		return nil, fmt.Errorf("scan logical document failed: %w", err)
+	}
+
+	aDocument.Created = created.Time.UTC()
+	aDocument.Updated = updated.Time.UTC()
+
+	return aDocument, nil
+}
+
+// DeleteLogicalDocument deletes the logical document and its versions, files are kept.
+func (a *Adapter) DeleteLogicalDocument(ctx context.Context, aDocument *ragserver.LogicalDocument) error {
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQuery(ctx, tx, deleteLogicalDocumentQuery{id: aDocument.ID}); err != nil {
+			return fmt.Errorf("exec delete logical document query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type deleteLogicalDocumentQuery struct {
+	id ragserver.LogicalDocumentID
+}
+
+func (q deleteLogicalDocumentQuery) SQL() (string, []any) {
+	return toPostgresParams(`delete from "ragserver"."logical_document" where "id" = ?`), []any{q.id}
+}
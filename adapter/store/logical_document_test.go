@@ -0,0 +1,136 @@
+package store
+
+import (
+	"time"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/pkg/authz"
+	"github.com/RichardKnop/ragserver/ragservertest"
+)
+
+func (s *StoreTestSuite) TestSaveLogicalDocumentVersions() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now   = time.Now().UTC().Truncate(time.Microsecond)
+		file1 = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		file2 = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		aDocument = &ragserver.LogicalDocument{
+			ID:       ragserver.NewLogicalDocumentID(),
+			AuthorID: ragserver.AuthorID(testPrincipal.ID()),
+			Name:     "Annual report",
+			Created:  now,
+			Updated:  now,
+		}
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, file1, file2), "error saving files")
+	s.Require().NoError(aDocument.AddVersion(file1, now))
+	s.Require().NoError(s.adapter.SaveLogicalDocument(ctx, aDocument), "error saving logical document")
+	s.Require().NoError(s.adapter.SaveLogicalDocumentVersions(ctx, aDocument, file1), "error saving logical document versions")
+
+	s.Run("Find logical document with a single version", func() {
+		savedDocument, err := s.adapter.FindLogicalDocument(ctx, aDocument.ID, false)
+		s.Require().NoError(err)
+		s.Equal(aDocument, savedDocument)
+		s.Equal(1, savedDocument.LatestVersion().Version)
+	})
+
+	s.Run("Add a newer version", func() {
+		s.Require().NoError(aDocument.AddVersion(file2, now.Add(time.Minute)))
+		s.Require().NoError(s.adapter.SaveLogicalDocument(ctx, aDocument), "error saving logical document")
+		s.Require().NoError(s.adapter.SaveLogicalDocumentVersions(ctx, aDocument, file2), "error saving logical document versions")
+
+		savedDocument, err := s.adapter.FindLogicalDocument(ctx, aDocument.ID, true)
+		s.Require().NoError(err)
+		s.Equal(aDocument, savedDocument)
+		s.Equal(file2.ID, savedDocument.LatestVersion().ID)
+		s.Equal(2, savedDocument.LatestVersion().Version)
+
+		savedFile, err := s.adapter.FindFile(ctx, file2.ID, authz.NilPartial)
+		s.Require().NoError(err)
+		s.Equal(aDocument.ID, savedFile.LogicalDocumentID)
+		s.Equal(2, savedFile.Version)
+	})
+
+	s.Run("Version numbers are unique", func() {
+		file3 := gen.File(ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())))
+		file3.Version = 2
+		s.Require().NoError(s.adapter.SaveFiles(ctx, file3), "error saving file")
+
+		err := s.adapter.SaveLogicalDocumentVersions(ctx, aDocument, file3)
+		s.Require().Error(err)
+	})
+
+	s.Run("Deleting a file removes its version", func() {
+		s.Require().NoError(s.adapter.DeleteFiles(ctx, file2))
+
+		savedDocument, err := s.adapter.FindLogicalDocument(ctx, aDocument.ID, false)
+		s.Require().NoError(err)
+		s.Require().Len(savedDocument.Versions, 1)
+		s.Equal(file1.ID, savedDocument.LatestVersion().ID)
+		s.Equal(2, savedDocument.LastVersion)
+	})
+}
+
+func (s *StoreTestSuite) TestListLogicalDocuments() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now   = time.Now().UTC().Truncate(time.Microsecond)
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		document1 = &ragserver.LogicalDocument{
+			ID:       ragserver.NewLogicalDocumentID(),
+			AuthorID: ragserver.AuthorID(testPrincipal.ID()),
+			Name:     "Annual report",
+			Created:  now,
+			Updated:  now,
+		}
+		document2 = &ragserver.LogicalDocument{
+			ID:       ragserver.NewLogicalDocumentID(),
+			AuthorID: ragserver.AuthorID(testPrincipal.ID()),
+			Name:     "Climate disclosure",
+			Created:  now.Add(time.Minute),
+			Updated:  now.Add(time.Minute),
+		}
+	)
+
+	documents, err := s.adapter.ListLogicalDocuments(ctx, ragserver.SortParams{})
+	s.Require().NoError(err)
+	s.Empty(documents)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(document1.AddVersion(aFile, now))
+	s.Require().NoError(s.adapter.SaveLogicalDocument(ctx, document1), "error saving logical document")
+	s.Require().NoError(s.adapter.SaveLogicalDocumentVersions(ctx, document1, aFile), "error saving logical document versions")
+	s.Require().NoError(s.adapter.SaveLogicalDocument(ctx, document2), "error saving logical document")
+
+	documents, err = s.adapter.ListLogicalDocuments(ctx, ragserver.SortParams{})
+	s.Require().NoError(err)
+	s.Equal([]*ragserver.LogicalDocument{document2, document1}, documents)
+
+	// Deleting a logical document keeps files of its versions
+	s.Require().NoError(s.adapter.DeleteLogicalDocument(ctx, document1), "error deleting logical document")
+
+	documents, err = s.adapter.ListLogicalDocuments(ctx, ragserver.SortParams{})
+	s.Require().NoError(err)
+	s.Equal([]*ragserver.LogicalDocument{document2}, documents)
+
+	savedFile, err := s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.True(savedFile.LogicalDocumentID.IsNil())
+	s.Zero(savedFile.Version)
+
+	_, err = s.adapter.FindLogicalDocument(ctx, document1.ID, false)
+	s.Require().ErrorIs(err, ragserver.ErrNotFound)
+}
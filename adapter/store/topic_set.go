@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+func (a *Adapter) SaveTopicSet(ctx context.Context, aTopicSet *ragserver.TopicSet) error {
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQueryCheckRowsAffected(ctx, tx, insertTopicSetQuery{aTopicSet: aTopicSet}); err != nil {
+			return fmt.Errorf("exec insert topic set query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type insertTopicSetQuery struct {
+	aTopicSet *ragserver.TopicSet
+}
+
+func (q insertTopicSetQuery) SQL() (string, []any) {
+	query := `
+		insert into "ragserver"."topic_set" (
+			"name",
+			"topics",
+			"created",
+			"updated"
+		)
+		values (?, ?, ?, ?)
+		on conflict("name") do update set
+			"topics"=excluded."topics",
+			"updated"=excluded."updated"
+	`
+	args := []any{
+		q.aTopicSet.Name,
+		topicsValue{topics: q.aTopicSet.Topics},
+		q.aTopicSet.Created,
+		q.aTopicSet.Updated,
+	}
+	return toPostgresParams(query), args
+}
+
+func (a *Adapter) ListTopicSets(ctx context.Context) ([]*ragserver.TopicSet, error) {
+	var topicSets []*ragserver.TopicSet
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := selectTopicSetsQuery{}.SQL()
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("select topic sets query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			aTopicSet, err := scanTopicSet(rows)
+			if err != nil {
+				return err
+			}
+			topicSets = append(topicSets, aTopicSet)
+		}
+
+		return rows.Err()
+	}); err != nil {
+		return nil, err
+	}
+
+	return topicSets, nil
+}
+
+func (a *Adapter) FindTopicSet(ctx context.Context, name string) (*ragserver.TopicSet, error) {
+	var aTopicSet *ragserver.TopicSet
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := selectTopicSetsQuery{name: name}.SQL()
+
+		var err error
+		aTopicSet, err = scanTopicSet(tx.QueryRowContext(ctx, query, args...))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return aTopicSet, nil
+}
+
+type selectTopicSetsQuery struct {
+	name string
+}
+
+func (q selectTopicSetsQuery) SQL() (string, []any) {
+	query := `
+		select
+			ts."name",
+			ts."topics",
+			ts."created",
+			ts."updated"
+		from "ragserver"."topic_set" ts
+	`
+	var args []any
+	if q.name != "" {
+		query += ` where ts."name" = ?`
+		args = append(args, q.name)
+	}
+	query += ` order by ts."name"`
+
+	return toPostgresParams(query), args
+}
+
+func scanTopicSet(row Scannable) (*ragserver.TopicSet, error) {
+	var (
+		aTopicSet = new(ragserver.TopicSet)
+		topics    topicsValue
+		created   sql.NullTime
+		updated   sql.NullTime
+	)
+
+	if err := row.Scan(
+		&aTopicSet.Name,
+		&topics,
+		&created,
+		&updated,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ragserver.ErrNotFound
+		}
+		return nil, fmt.Errorf("scan topic set failed: %w", err)
+	}
+
+	aTopicSet.Topics = topics.topics
+	aTopicSet.Created = created.Time.UTC()
+	aTopicSet.Updated = updated.Time.UTC()
+
+	return aTopicSet, nil
+}
+
+func (a *Adapter) DeleteTopicSet(ctx context.Context, name string) error {
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQuery(ctx, tx, deleteTopicSetQuery{name: name}); err != nil {
+			return fmt.Errorf("exec delete topic set query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type deleteTopicSetQuery struct {
+	name string
+}
+
+func (q deleteTopicSetQuery) SQL() (string, []any) {
+	return toPostgresParams(`delete from "ragserver"."topic_set" where "name" = ?`), []any{q.name}
+}
+
+// topicsValue stores topics as a JSON array.
+type topicsValue struct {
+	topics ragserver.RelevantTopics
+}
+
+func (v topicsValue) Value() (driver.Value, error) {
+	if v.topics == nil {
+		return "[]", nil
+	}
+	return marshalJSON(v.topics)
+}
+
+func (v *topicsValue) Scan(src any) error {
+	return scanJSON(src, &v.topics)
+}
+
+// nullTopicSetValue stores a topic set recorded on a file or upload as JSON, nil is stored as null.
+type nullTopicSetValue struct {
+	topicSet *ragserver.TopicSet
+}
+
+func (v nullTopicSetValue) Value() (driver.Value, error) {
+	if v.topicSet == nil {
+		return nil, nil
+	}
+	return marshalJSON(v.topicSet)
+}
+
+func (v *nullTopicSetValue) Scan(src any) error {
+	if src == nil {
+		v.topicSet = nil
+		return nil
+	}
+	v.topicSet = new(ragserver.TopicSet)
+	return scanJSON(src, v.topicSet)
+}
+
+// marshalJSON returns JSON as a string, the driver would send bytes as bytea which is not valid jsonb.
+func marshalJSON(v any) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanJSON(src any, dst any) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, dst)
+	case string:
+		return json.Unmarshal([]byte(data), dst)
+	default:
+		return fmt.Errorf("unexpected type %T of JSON value", src)
+	}
+}
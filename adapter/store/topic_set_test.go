@@ -0,0 +1,126 @@
+package store
+
+import (
+	"time"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/pkg/authz"
+	"github.com/RichardKnop/ragserver/ragservertest"
+)
+
+func (s *StoreTestSuite) TestSaveTopicSet_Upsert() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	aTopicSet := &ragserver.TopicSet{
+		Name: "emissions",
+		Topics: ragserver.RelevantTopics{
+			{
+				Name:            "emissions",
+				Keywords:        []string{"emissions"},
+				Patterns:        []string{`\bghg\b`},
+				ExcludeKeywords: []string{"table of contents"},
+				MinMatches:      2,
+			},
+		},
+		Created: now,
+		Updated: now,
+	}
+
+	s.Require().NoError(s.adapter.SaveTopicSet(ctx, aTopicSet), "error saving topic set")
+
+	savedTopicSet, err := s.adapter.FindTopicSet(ctx, aTopicSet.Name)
+	s.Require().NoError(err)
+	s.Equal(aTopicSet, savedTopicSet)
+
+	// Replace topics of the existing topic set
+	aTopicSet.Topics = ragserver.RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}}}
+	aTopicSet.Updated = now.Add(time.Minute)
+	s.Require().NoError(s.adapter.SaveTopicSet(ctx, aTopicSet), "error updating topic set")
+
+	savedTopicSet, err = s.adapter.FindTopicSet(ctx, aTopicSet.Name)
+	s.Require().NoError(err)
+	s.Equal(aTopicSet, savedTopicSet)
+
+	_, err = s.adapter.FindTopicSet(ctx, "bogus")
+	s.Require().ErrorIs(err, ragserver.ErrNotFound)
+}
+
+func (s *StoreTestSuite) TestListTopicSets() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now       = time.Now().UTC().Truncate(time.Microsecond)
+		topicSet1 = &ragserver.TopicSet{
+			Name:    "scope",
+			Topics:  ragserver.RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}}},
+			Created: now,
+			Updated: now,
+		}
+		topicSet2 = &ragserver.TopicSet{
+			Name:    "net-zero",
+			Topics:  ragserver.RelevantTopics{{Name: "net-zero", Keywords: []string{"net zero"}}},
+			Created: now,
+			Updated: now,
+		}
+	)
+
+	topicSets, err := s.adapter.ListTopicSets(ctx)
+	s.Require().NoError(err)
+	s.Empty(topicSets)
+
+	s.Require().NoError(s.adapter.SaveTopicSet(ctx, topicSet1), "error saving topic set")
+	s.Require().NoError(s.adapter.SaveTopicSet(ctx, topicSet2), "error saving topic set")
+
+	topicSets, err = s.adapter.ListTopicSets(ctx)
+	s.Require().NoError(err)
+	s.Equal([]*ragserver.TopicSet{topicSet2, topicSet1}, topicSets)
+
+	s.Require().NoError(s.adapter.DeleteTopicSet(ctx, topicSet2.Name), "error deleting topic set")
+
+	topicSets, err = s.adapter.ListTopicSets(ctx)
+	s.Require().NoError(err)
+	s.Equal([]*ragserver.TopicSet{topicSet1}, topicSets)
+}
+
+func (s *StoreTestSuite) TestSaveFiles_Topics() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		anUpload = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		topics = &ragserver.TopicSet{
+			Name:   "scope",
+			Topics: ragserver.RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}}},
+		}
+	)
+	aFile.Topics = topics
+	anUpload.Topics = topics
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(s.adapter.SaveUploads(ctx, anUpload), "error saving upload")
+
+	savedFile, err := s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Equal(topics, savedFile.Topics)
+
+	savedUpload, err := s.adapter.FindUpload(ctx, anUpload.ID, authz.NilPartial, false)
+	s.Require().NoError(err)
+	s.Equal(topics, savedUpload.Topics)
+
+	// Files uploaded without topics use topics configured on the server
+	aFile.Topics = nil
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+
+	savedFile, err = s.adapter.FindFile(ctx, aFile.ID, authz.NilPartial)
+	s.Require().NoError(err)
+	s.Nil(savedFile.Topics)
+}
@@ -0,0 +1,361 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+func (a *Adapter) SaveUploads(ctx context.Context, uploads ...*ragserver.Upload) error {
+	if len(uploads) < 1 {
+		return nil
+	}
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQueryCheckRowsAffected(ctx, tx, insertUploadsQuery{uploads: uploads}); err != nil {
+			return fmt.Errorf("exec insert uploads query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type insertUploadsQuery struct {
+	uploads []*ragserver.Upload
+}
+
+func (q insertUploadsQuery) SQL() (string, []any) {
+	if len(q.uploads) == 0 {
+		return "", nil
+	}
+
+	query := `
+		insert into "ragserver"."upload" (
+			"id",
+			"author",
+			"file_name",
+			"upload_size",
+			"upload_offset",
+			"temp_file",
+			"hash_state",
+			"file",
+			"topics",
+			"metadata",
+			"expires",
+			"lock_id",
+			"locked_until",
+			"created",
+			"updated"
+		)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	args := make([]any, 0, len(q.uploads)*15)
+	args = append(args, uploadArgs(q.uploads[0])...)
+	for i := range q.uploads[1:] {
+		query += `, (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		args = append(args, uploadArgs(q.uploads[i+1])...)
+	}
+	query += `
+		on conflict("id") do update set
+			"file_name"=excluded."file_name",
+			"upload_size"=excluded."upload_size",
+			"upload_offset"=excluded."upload_offset",
+			"temp_file"=excluded."temp_file",
+			"hash_state"=excluded."hash_state",
+			"file"=excluded."file",
+			"expires"=excluded."expires",
+			"lock_id"=excluded."lock_id",
+			"locked_until"=excluded."locked_until",
+			"updated"=excluded."updated"
+	`
+	return toPostgresParams(query), args
+}
+
+func uploadArgs(anUpload *ragserver.Upload) []any {
+	return []any{
+		anUpload.ID,
+		anUpload.AuthorID,
+		anUpload.FileName,
+		anUpload.Size,
+		anUpload.Offset,
+		anUpload.TempFile,
+		anUpload.HashState,
+		uuid.NullUUID{UUID: anUpload.FileID.UUID, Valid: !anUpload.FileID.UUID.IsNil()},
+		nullTopicSetValue{topicSet: anUpload.Topics},
+		metadataValue{metadata: anUpload.Metadata},
+		anUpload.Expires,
+		uuid.NullUUID{UUID: anUpload.LockID, Valid: !anUpload.LockID.IsNil()},
+		sql.NullTime{Time: anUpload.LockedUntil, Valid: !anUpload.LockedUntil.IsZero()},
+		anUpload.Created,
+		anUpload.Updated,
+	}
+}
+
+var (
+	validUploadSortFields = []string{
+		`u."created"`,
+		`u."expires"`,
+	}
+	defaultUploadSortParams = ragserver.SortParams{
+		By: `u."created"`, Order: ragserver.SortOrderDesc,
+		Limit: 100,
+	}
+)
+
+func (a *Adapter) ListUploads(ctx context.Context, filter ragserver.UploadFilter, params ragserver.SortParams) ([]*ragserver.Upload, error) {
+	var uploads []*ragserver.Upload
+
+	// Validate params
+	if !params.Empty() && !params.Valid(validUploadSortFields) {
+		return nil, fmt.Errorf("invalid sort params: %v", params)
+	}
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := selectUploadsQuery{
+			filter: filter,
+			params: params,
+		}.SQL()
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("select uploads query failed: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			anUpload, err := scanUpload(rows)
+			if err != nil {
+				return err
+			}
+			uploads = append(uploads, anUpload)
+		}
+
+		return rows.Err()
+	}); err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+type selectUploadsQuery struct {
+	filter ragserver.UploadFilter
+	params ragserver.SortParams
+}
+
+func (q selectUploadsQuery) SQL() (string, []any) {
+	query := `
+		select
+			u."id",
+			u."author",
+			u."file_name",
+			u."upload_size",
+			u."upload_offset",
+			u."temp_file",
+			u."hash_state",
+			u."file",
+			u."topics",
+			u."metadata",
+			u."expires",
+			u."lock_id",
+			u."locked_until",
+			u."created",
+			u."updated"
+		from "ragserver"."upload" u
+	`
+	args := []any{}
+
+	if !q.filter.ExpiresBefore.IsZero() {
+		query += ` where u."expires" < ?`
+		args = append(args, q.filter.ExpiresBefore)
+	}
+
+	// Add order by clause and/or limit if any
+	if q.params.Empty() {
+		q.params = defaultUploadSortParams
+	}
+	query += q.params.SQL()
+
+	if q.filter.Lock {
+		query += " for update skip locked"
+	}
+
+	return toPostgresParams(query), args
+}
+
+func (a *Adapter) FindUpload(ctx context.Context, id ragserver.UploadID, partial authz.Partial, lock bool) (*ragserver.Upload, error) {
+	var anUpload *ragserver.Upload
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := findUploadQuery{
+			id:      id,
+			partial: partial,
+			lock:    lock,
+		}.SQL()
+
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return fmt.Errorf("prepare find upload statement failed: %w", err)
+		}
+		defer stmt.Close()
+
+		row := stmt.QueryRowContext(ctx, args...)
+		anUpload, err = scanUpload(row)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return anUpload, nil
+}
+
+type findUploadQuery struct {
+	id      ragserver.UploadID
+	partial authz.Partial
+	lock    bool
+}
+
+func (q findUploadQuery) SQL() (string, []any) {
+	query := `
+		select
+			u."id",
+			u."author",
+			u."file_name",
+			u."upload_size",
+			u."upload_offset",
+			u."temp_file",
+			u."hash_state",
+			u."file",
+			u."topics",
+			u."metadata",
+			u."expires",
+			u."lock_id",
+			u."locked_until",
+			u."created",
+			u."updated"
+		from "ragserver"."upload" u
+		where u."id" = ?
+	`
+	args := []any{q.id}
+
+	// Add where clauses from the partial if any
+	partialClauses, partialArgs := q.partial.SQL()
+	if partialClauses != "" {
+		query += " and " + partialClauses
+
+		args = append(args, partialArgs...)
+	}
+
+	if q.lock {
+		query += " for update"
+	}
+
+	return toPostgresParams(query), args
+}
+
+func scanUpload(row Scannable) (*ragserver.Upload, error) {
+	var (
+		anUpload    = new(ragserver.Upload)
+		fileID      uuid.NullUUID
+		topics      nullTopicSetValue
+		metadata    metadataValue
+		expires     sql.NullTime
+		lockID      uuid.NullUUID
+		lockedUntil sql.NullTime
+		created     sql.NullTime
+		updated     sql.NullTime
+	)
+
+	if err := row.Scan(
+		&anUpload.ID,
+		&anUpload.AuthorID,
+		&anUpload.FileName,
+		&anUpload.Size,
+		&anUpload.Offset,
+		&anUpload.TempFile,
+		&anUpload.HashState,
+		&fileID,
+		&topics,
+		&metadata,
+		&expires,
+		&lockID,
+		&lockedUntil,
+		&created,
+		&updated,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ragserver.ErrNotFound
+		}
+		return nil, fmt.Errorf("scan upload failed: %w", err)
+	}
+
+	if fileID.Valid {
+		anUpload.FileID = ragserver.FileID{UUID: fileID.UUID}
+	}
+
+	anUpload.Topics = topics.topicSet
+	anUpload.Metadata = metadata.metadata
+	anUpload.Expires = expires.Time.UTC()
+	if lockID.Valid {
+		anUpload.LockID = lockID.UUID
+	}
+	if lockedUntil.Valid {
+		anUpload.LockedUntil = lockedUntil.Time.UTC()
+	}
+	anUpload.Created = created.Time.UTC()
+	anUpload.Updated = updated.Time.UTC()
+
+	return anUpload, nil
+}
+
+func (a *Adapter) DeleteUploads(ctx context.Context, uploads ...*ragserver.Upload) error {
+	if len(uploads) < 1 {
+		return nil
+	}
+
+	if err := a.inTxDo(ctx, &sql.TxOptions{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := execQuery(ctx, tx, deleteUploadsQuery{uploads: uploads}); err != nil {
+			return fmt.Errorf("exec delete uploads query failed: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type deleteUploadsQuery struct {
+	uploads []*ragserver.Upload
+}
+
+func (q deleteUploadsQuery) SQL() (string, []any) {
+	if len(q.uploads) == 0 {
+		return "", nil
+	}
+
+	query := `delete from "ragserver"."upload" where "id" in (?`
+	args := make([]any, 0, len(q.uploads))
+	args = append(args, q.uploads[0].ID)
+	for i := range q.uploads[1:] {
+		query += `, ?`
+		args = append(args, q.uploads[i+1].ID)
+	}
+	query += `)`
+
+	return toPostgresParams(query), args
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/pkg/authz"
+	"github.com/RichardKnop/ragserver/ragservertest"
+)
+
+func (s *StoreTestSuite) TestFindUpload() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	anUpload := gen.Upload(
+		ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveUploads(ctx, anUpload), "error saving upload")
+
+	s.Run("Find upload without partial", func() {
+		savedUpload, err := s.adapter.FindUpload(ctx, anUpload.ID, authz.NilPartial, false)
+		s.Require().NoError(err)
+		s.Equal(anUpload, savedUpload)
+	})
+
+	s.Run("Find upload with lock", func() {
+		savedUpload, err := s.adapter.FindUpload(ctx, anUpload.ID, authz.FilterBy("author", testPrincipal.ID()), true)
+		s.Require().NoError(err)
+		s.Equal(anUpload, savedUpload)
+	})
+
+	s.Run("Find upload of another author", func() {
+		_, err := s.adapter.FindUpload(ctx, anUpload.ID, authz.FilterBy("author", ragserver.NewAuthorID()), false)
+		s.Require().ErrorIs(err, ragserver.ErrNotFound)
+	})
+}
+
+func (s *StoreTestSuite) TestListUploads() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		now     = time.Now().UTC().Truncate(time.Millisecond)
+		expired = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+			ragservertest.WithUploadExpires(now.Add(-time.Hour)),
+		)
+		active = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+			ragservertest.WithUploadExpires(now.Add(time.Hour)),
+		)
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveUploads(ctx, expired, active), "error saving uploads")
+
+	s.Run("List all uploads", func() {
+		uploads, err := s.adapter.ListUploads(ctx, ragserver.UploadFilter{}, ragserver.SortParams{})
+		s.Require().NoError(err)
+		s.ElementsMatch([]*ragserver.Upload{expired, active}, uploads)
+	})
+
+	s.Run("List expired uploads with lock", func() {
+		uploads, err := s.adapter.ListUploads(ctx, ragserver.UploadFilter{
+			ExpiresBefore: now,
+			Lock:          true,
+		}, ragserver.SortParams{
+			Limit: 10,
+			Order: ragserver.SortOrderAsc,
+			By:    `u."expires"`,
+		})
+		s.Require().NoError(err)
+		s.Equal([]*ragserver.Upload{expired}, uploads)
+	})
+
+	s.Run("Invalid sort params", func() {
+		_, err := s.adapter.ListUploads(ctx, ragserver.UploadFilter{}, ragserver.SortParams{
+			Limit: 10,
+			Order: ragserver.SortOrderAsc,
+			By:    `u."file_name"`,
+		})
+		s.Require().Error(err)
+	})
+}
+
+func (s *StoreTestSuite) TestSaveUploads_Upsert() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		aFile = gen.File(
+			ragservertest.WithFileAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		anUpload = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveFiles(ctx, aFile), "error saving file")
+	s.Require().NoError(s.adapter.SaveUploads(ctx, anUpload), "error saving upload")
+
+	// Complete the upload
+	anUpload.Offset = anUpload.Size
+	anUpload.HashState = []byte("final state")
+	anUpload.FileID = aFile.ID
+	anUpload.LockID = uuid.Must(uuid.NewV4())
+	anUpload.LockedUntil = time.Now().UTC().Truncate(time.Millisecond)
+	anUpload.Expires = time.Now().UTC().Add(time.Hour).Truncate(time.Millisecond)
+	anUpload.Updated = time.Now().UTC().Truncate(time.Millisecond)
+	s.Require().NoError(s.adapter.SaveUploads(ctx, anUpload), "error updating upload")
+
+	savedUpload, err := s.adapter.FindUpload(ctx, anUpload.ID, authz.NilPartial, false)
+	s.Require().NoError(err)
+	s.Equal(anUpload, savedUpload)
+	s.True(savedUpload.Completed())
+
+	// Deleting the file deletes the upload as well
+	s.Require().NoError(s.adapter.DeleteFiles(ctx, aFile), "error deleting file")
+	_, err = s.adapter.FindUpload(ctx, anUpload.ID, authz.NilPartial, false)
+	s.Require().ErrorIs(err, ragserver.ErrNotFound)
+}
+
+func (s *StoreTestSuite) TestDeleteUploads() {
+	ctx, cancel := testContext()
+	defer cancel()
+
+	var (
+		upload1 = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+		upload2 = gen.Upload(
+			ragservertest.WithUploadAuthorID(ragserver.AuthorID(testPrincipal.ID())),
+		)
+	)
+
+	s.Require().NoError(s.adapter.SavePrincipal(ctx, testPrincipal), "error saving principal")
+	s.Require().NoError(s.adapter.SaveUploads(ctx, upload1, upload2), "error saving uploads")
+
+	s.Require().NoError(s.adapter.DeleteUploads(ctx, upload1), "error deleting upload")
+
+	_, err := s.adapter.FindUpload(ctx, upload1.ID, authz.NilPartial, false)
+	s.Require().ErrorIs(err, ragserver.ErrNotFound)
+
+	_, err = s.adapter.FindUpload(ctx, upload2.ID, authz.NilPartial, false)
+	s.Require().NoError(err)
+}
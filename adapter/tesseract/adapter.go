@@ -0,0 +1,77 @@
+//go:build tesseract
+
+// Package tesseract implements ragserver.OCR using tesseract via gosseract. It requires
+// tesseract and leptonica libraries to be installed and is only built with the tesseract
+// build tag, e.g. go build -tags tesseract.
+package tesseract
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/otiai10/gosseract/v2"
+	"go.uber.org/zap"
+)
+
+type Adapter struct {
+	languages []string
+	logger    *zap.Logger
+}
+
+type Option func(*Adapter)
+
+// WithLanguages sets languages used for recognition, e.g. "eng", "deu".
+func WithLanguages(languages ...string) Option {
+	return func(a *Adapter) {
+		a.languages = languages
+	}
+}
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(a *Adapter) {
+		a.logger = logger
+	}
+}
+
+func New(options ...Option) *Adapter {
+	a := &Adapter{
+		languages: []string{"eng"},
+		logger:    zap.NewNop(),
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	a.logger.Sugar().With(
+		"languages", a.languages,
+	).Info("init tesseract adapter")
+
+	return a
+}
+
+func (a *Adapter) Recognize(ctx context.Context, image io.Reader) (string, error) {
+	data, err := io.ReadAll(image)
+	if err != nil {
+		return "", err
+	}
+
+	// Client is not safe for concurrent use, so we create a new one for each image
+	client := gosseract.NewClient()
+	defer client.Close()
+
+	if err := client.SetLanguage(a.languages...); err != nil {
+		return "", fmt.Errorf("setting languages: %w", err)
+	}
+	if err := client.SetImageFromBytes(data); err != nil {
+		return "", fmt.Errorf("setting image: %w", err)
+	}
+
+	text, err := client.Text()
+	if err != nil {
+		return "", fmt.Errorf("recognizing text: %w", err)
+	}
+
+	return text, nil
+}
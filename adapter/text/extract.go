@@ -0,0 +1,121 @@
+package text
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+type format int
+
+const (
+	formatPlain format = iota
+	formatMarkdown
+	formatHTML
+)
+
+// block is a piece of text which should not be merged with its neighbours, e.g. a paragraph,
+// a list item or a heading.
+type block struct {
+	Text    string
+	Heading bool
+}
+
+// Extract splits the file into blocks (paragraphs, list items, headings) and returns a document
+// per block. Headings are not returned as documents themselves but are recorded as the section
+// of the blocks under them.
+func (a *Adapter) Extract(ctx context.Context, fileName string, contents io.ReadSeeker) ([]ragserver.Document, error) {
+	data, err := io.ReadAll(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []block
+	switch detectFormat(fileName, data) {
+	case formatMarkdown:
+		blocks = parseMarkdown(string(data))
+	case formatHTML:
+		blocks, err = parseHTML(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		blocks = parsePlain(string(data))
+	}
+
+	var (
+		documents = make([]ragserver.Document, 0, len(blocks))
+		heading   string
+	)
+
+	for _, aBlock := range blocks {
+		content := strings.TrimSpace(aBlock.Text)
+		if content == "" {
+			continue
+		}
+
+		if aBlock.Heading {
+			heading = content
+			continue
+		}
+
+		documents = append(documents, ragserver.Document{
+			Content: content,
+			Page:    1,
+			Section: heading,
+		})
+	}
+
+	a.logger.Sugar().Infof("number of documents: %d", len(documents))
+
+	return documents, nil
+}
+
+func detectFormat(fileName string, data []byte) format {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".md", ".markdown":
+		return formatMarkdown
+	case ".html", ".htm":
+		return formatHTML
+	}
+	if strings.HasPrefix(http.DetectContentType(data), "text/html") {
+		return formatHTML
+	}
+	return formatPlain
+}
+
+// parsePlain splits plain text into paragraphs separated by blank lines.
+func parsePlain(data string) []block {
+	var (
+		blocks    []block
+		paragraph []string
+	)
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, block{Text: strings.Join(paragraph, " ")})
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(normalizeNewlines(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+
+	return blocks
+}
+
+func normalizeNewlines(data string) string {
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data)
+}
@@ -0,0 +1,135 @@
+package text
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+const testMarkdown = `# Sustainability report
+
+Our **total** scope 1 emissions were 100 tCO2e. See [the methodology](https://example.com) for details.
+
+## Targets
+
+- We aim to reach net zero by 2050.
+- Interim target is set for 2030.
+
+` + "```go\nfmt.Println(\"not a sentence\")\n```" + `
+
+| Year | Emissions |
+| ---- | --------- |
+| 2022 | 100 |
+`
+
+const testHTML = `<!DOCTYPE html>
+<html>
+<head><title>Report</title><style>body { color: red; }</style></head>
+<body>
+<nav><a href="/">Home</a> <a href="/about">About us</a></nav>
+<main>
+  <h1>Emissions</h1>
+  <p>Our total scope 1 emissions were 100 tCO2e.<br>This is a <b>decrease</b> from last year.</p>
+  <h2>Targets</h2>
+  <ul><li>We aim to reach net zero by 2050.</li></ul>
+  <table><tr><th>Year</th><th>Emissions</th></tr><tr><td>2022</td><td>100</td></tr></table>
+  <script>console.log("ignored")</script>
+</main>
+<footer>Copyright 2025. All rights reserved.</footer>
+</body>
+</html>`
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	adapter := New()
+
+	t.Run("Markdown", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "report.md", bytes.NewReader([]byte(testMarkdown)))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Our total scope 1 emissions were 100 tCO2e. See the methodology for details.", Page: 1, Section: "Sustainability report"},
+			{Content: "We aim to reach net zero by 2050.", Page: 1, Section: "Targets"},
+			{Content: "Interim target is set for 2030.", Page: 1, Section: "Targets"},
+			{Content: "Year, Emissions", Page: 1, Section: "Targets"},
+			{Content: "2022, 100", Page: 1, Section: "Targets"},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("HTML strips boilerplate", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "report.html", bytes.NewReader([]byte(testHTML)))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "Our total scope 1 emissions were 100 tCO2e. This is a decrease from last year.", Page: 1, Section: "Emissions"},
+			{Content: "We aim to reach net zero by 2050.", Page: 1, Section: "Targets"},
+			{Content: "Year, Emissions", Page: 1, Section: "Targets"},
+			{Content: "2022, 100", Page: 1, Section: "Targets"},
+		}
+		assert.Equal(t, expected, documents)
+	})
+
+	t.Run("HTML is detected without extension", func(t *testing.T) {
+		documents, err := adapter.Extract(context.Background(), "report", bytes.NewReader([]byte(testHTML)))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"Our total scope 1 emissions were 100 tCO2e. This is a decrease from last year.",
+			"We aim to reach net zero by 2050.",
+			"Year, Emissions",
+			"2022, 100",
+		}, contents(documents))
+	})
+
+	t.Run("Plain text", func(t *testing.T) {
+		data := "First paragraph spans\ntwo lines. Second sentence.\r\n\r\nAnother paragraph."
+		documents, err := adapter.Extract(context.Background(), "notes.txt", bytes.NewReader([]byte(data)))
+		require.NoError(t, err)
+
+		expected := []ragserver.Document{
+			{Content: "First paragraph spans two lines. Second sentence.", Page: 1},
+			{Content: "Another paragraph.", Page: 1},
+		}
+		assert.Equal(t, expected, documents)
+	})
+}
+
+func TestStripInlineMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"strong", "This is **bold** and __bold__", "This is bold and bold"},
+		{"emphasis", "This is *italic* and _italic_", "This is italic and italic"},
+		{"snake case is kept", "Use snake_case_names here", "Use snake_case_names here"},
+		{"link", "Read [the report](https://example.com/report.pdf)", "Read the report"},
+		{"reference link", "Read [the report][1]", "Read the report"},
+		{"image", "![Chart of emissions](chart.png)", "Chart of emissions"},
+		{"inline code", "Run `go test`", "Run go test"},
+		{"strikethrough", "~~old~~ new", "old new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stripInlineMarkdown(tt.text))
+		})
+	}
+}
+
+func contents(documents []ragserver.Document) []string {
+	result := make([]string, 0, len(documents))
+	for _, aDocument := range documents {
+		result = append(result, aDocument.Content)
+	}
+	return result
+}
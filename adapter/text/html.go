@@ -0,0 +1,114 @@
+package text
+
+import (
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// boilerplateSelector matches elements which do not contain main content of a web page,
+// such as scripts, navigation, cookie banners and footers.
+const boilerplateSelector = "script, style, noscript, template, iframe, svg, canvas, form, button, nav, header, footer, aside, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [aria-hidden=true], [hidden]"
+
+var (
+	headingTags = map[string]struct{}{
+		"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	}
+	blockTags = map[string]struct{}{
+		"address": {}, "article": {}, "blockquote": {}, "body": {}, "caption": {}, "dd": {}, "details": {},
+		"div": {}, "dl": {}, "dt": {}, "figcaption": {}, "figure": {}, "html": {}, "li": {}, "main": {},
+		"ol": {}, "p": {}, "pre": {}, "section": {}, "summary": {}, "table": {}, "tbody": {}, "td": {},
+		"tfoot": {}, "th": {}, "thead": {}, "tr": {}, "ul": {},
+	}
+)
+
+// parseHTML strips boilerplate from a web page and splits the remaining content into headings
+// and text blocks. If the page has a main or article element, only its content is used.
+func parseHTML(r io.Reader) ([]block, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Find(boilerplateSelector).Remove()
+
+	root := doc.Find("main").First()
+	if root.Length() == 0 {
+		root = doc.Find("article").First()
+	}
+	if root.Length() == 0 {
+		root = doc.Find("body").First()
+	}
+	if root.Length() == 0 {
+		root = doc.Selection
+	}
+
+	p := new(htmlParser)
+	p.walk(root)
+	p.flush(false)
+
+	return p.blocks, nil
+}
+
+type htmlParser struct {
+	blocks []block
+	buf    strings.Builder
+}
+
+func (p *htmlParser) walk(s *goquery.Selection) {
+	s.Contents().Each(func(_ int, node *goquery.Selection) {
+		name := goquery.NodeName(node)
+		switch {
+		case name == "#text":
+			p.buf.WriteString(node.Text())
+		case name == "br":
+			p.buf.WriteString(" ")
+		case isHeading(name):
+			p.flush(false)
+			p.buf.WriteString(node.Text())
+			p.flush(true)
+		case name == "tr":
+			// Table rows become a single block with cells separated by commas
+			p.flush(false)
+			cells := make([]string, 0, 4)
+			node.Find("td, th").Each(func(_ int, cell *goquery.Selection) {
+				if text := collapseSpaces(cell.Text()); text != "" {
+					cells = append(cells, text)
+				}
+			})
+			p.buf.WriteString(strings.Join(cells, ", "))
+			p.flush(false)
+		case isBlock(name):
+			p.flush(false)
+			p.walk(node)
+			p.flush(false)
+		default:
+			p.walk(node)
+		}
+	})
+}
+
+func (p *htmlParser) flush(heading bool) {
+	text := collapseSpaces(p.buf.String())
+	p.buf.Reset()
+	if text == "" {
+		return
+	}
+	p.blocks = append(p.blocks, block{Text: text, Heading: heading})
+}
+
+func isHeading(name string) bool {
+	_, ok := headingTags[name]
+	return ok
+}
+
+func isBlock(name string) bool {
+	_, ok := blockTags[name]
+	return ok
+}
+
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
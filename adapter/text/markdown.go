@@ -0,0 +1,129 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	atxHeadingRegex   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	setextRegex       = regexp.MustCompile(`^(=+|-+)\s*$`)
+	horizontalRegex   = regexp.MustCompile(`^([-*_]\s*){3,}$`)
+	listItemRegex     = regexp.MustCompile(`^([-*+]|\d+[.)])\s+(.*)$`)
+	tableSepRegex     = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	linkDefRegex      = regexp.MustCompile(`^\[[^\]]+\]:\s+\S+`)
+	imageRegex        = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRegex         = regexp.MustCompile(`\[([^\]]+)\](\([^)]*\)|\[[^\]]*\])`)
+	autolinkRegex     = regexp.MustCompile(`<((?:https?|mailto):[^>]+)>`)
+	inlineCodeRegex   = regexp.MustCompile("`([^`]*)`")
+	strongRegex       = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	emphasisStarRegex = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	emphasisUndRegex  = regexp.MustCompile(`(^|\W)_(\S(?:[^_]*?\S)?)_(\W|$)`)
+	strikeRegex       = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// parseMarkdown splits Markdown into headings, paragraphs, list items and table rows, stripping
+// inline formatting. Fenced code blocks are skipped as they rarely contain useful sentences.
+func parseMarkdown(data string) []block {
+	var (
+		blocks    []block
+		paragraph []string
+		fence     string
+	)
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, block{Text: stripInlineMarkdown(strings.Join(paragraph, " "))})
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(normalizeNewlines(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Skip fenced code blocks
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			fence = trimmed[:3]
+			continue
+		}
+
+		// Strip blockquote markers, quotes are treated as regular text
+		for strings.HasPrefix(trimmed, ">") {
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+
+		// Setext headings are a paragraph underlined with = or -
+		if len(paragraph) > 0 && setextRegex.MatchString(trimmed) {
+			blocks = append(blocks, block{
+				Text:    stripInlineMarkdown(strings.Join(paragraph, " ")),
+				Heading: true,
+			})
+			paragraph = nil
+			continue
+		}
+
+		if horizontalRegex.MatchString(trimmed) || linkDefRegex.MatchString(trimmed) || tableSepRegex.MatchString(trimmed) && strings.Contains(trimmed, "-") && strings.Contains(trimmed, "|") {
+			flush()
+			continue
+		}
+
+		if matches := atxHeadingRegex.FindStringSubmatch(trimmed); matches != nil {
+			flush()
+			blocks = append(blocks, block{
+				Text:    stripInlineMarkdown(matches[2]),
+				Heading: true,
+			})
+			continue
+		}
+
+		if matches := listItemRegex.FindStringSubmatch(trimmed); matches != nil {
+			flush()
+			paragraph = append(paragraph, matches[2])
+			continue
+		}
+
+		// Each table row is a separate block with cells separated by commas
+		if strings.HasPrefix(trimmed, "|") {
+			flush()
+			cells := make([]string, 0, 4)
+			for _, cell := range strings.Split(strings.Trim(trimmed, "|"), "|") {
+				if cell = strings.TrimSpace(cell); cell != "" {
+					cells = append(cells, cell)
+				}
+			}
+			if len(cells) > 0 {
+				blocks = append(blocks, block{Text: stripInlineMarkdown(strings.Join(cells, ", "))})
+			}
+			continue
+		}
+
+		paragraph = append(paragraph, trimmed)
+	}
+	flush()
+
+	return blocks
+}
+
+func stripInlineMarkdown(text string) string {
+	text = imageRegex.ReplaceAllString(text, "$1")
+	text = linkRegex.ReplaceAllString(text, "$1")
+	text = autolinkRegex.ReplaceAllString(text, "$1")
+	text = inlineCodeRegex.ReplaceAllString(text, "$1")
+	text = strongRegex.ReplaceAllString(text, "$2")
+	text = emphasisStarRegex.ReplaceAllString(text, "$1")
+	text = emphasisUndRegex.ReplaceAllString(text, "$1$2$3")
+	text = strikeRegex.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(text)
+}
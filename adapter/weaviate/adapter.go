@@ -6,14 +6,26 @@ import (
 
 	"github.com/weaviate/weaviate-go-client/v5/weaviate"
 	"github.com/weaviate/weaviate/entities/models"
+
+	"github.com/RichardKnop/ragserver"
 )
 
 type Adapter struct {
-	client *weaviate.Client
+	client         *weaviate.Client
+	metadataFields []ragserver.MetadataField
 }
 
 type Option func(*Adapter)
 
+// WithMetadataFields stores metadata copied to documents as properties so they can be filtered by it,
+// tag fields are matched by exact values and numeric fields by ranges. Use the same fields as
+// ragserver.WithIndexedMetadata.
+func WithMetadataFields(fields ...ragserver.MetadataField) Option {
+	return func(a *Adapter) {
+		a.metadataFields = fields
+	}
+}
+
 func New(ctx context.Context, client *weaviate.Client, options ...Option) (*Adapter, error) {
 	a := &Adapter{
 		client: client,
@@ -51,5 +63,42 @@ func (a *Adapter) init(ctx context.Context) error {
 		}
 	}
 
+	// Properties are created automatically when objects are saved, but search queries
+	// fail for properties which don't exist yet, so make sure optional ones do.
+	existing, err := a.client.Schema().ClassGetter().WithClassName(className).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("weaviate error: %w", err)
+	}
+	properties := append([]*models.Property{}, optionalProperties...)
+	for _, field := range a.metadataFields {
+		properties = append(properties, metadataProperty(field))
+	}
+	for _, aProperty := range properties {
+		if hasProperty(existing, aProperty.Name) {
+			continue
+		}
+		err = a.client.Schema().PropertyCreator().WithClassName(className).WithProperty(aProperty).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("weaviate error: %w", err)
+		}
+	}
+
 	return nil
 }
+
+var optionalProperties = []*models.Property{
+	{Name: "section", DataType: []string{"text"}},
+	{Name: "language", DataType: []string{"text"}, Tokenization: models.PropertyTokenizationField},
+	{Name: "layout_type", DataType: []string{"text"}},
+	// Left, top, width, height, page width and page height
+	{Name: "bounding_box", DataType: []string{"number[]"}},
+}
+
+func hasProperty(cls *models.Class, name string) bool {
+	for _, aProperty := range cls.Properties {
+		if aProperty.Name == name {
+			return true
+		}
+	}
+	return false
+}
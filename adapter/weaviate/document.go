@@ -3,6 +3,7 @@ package weaviate
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/weaviate/weaviate-go-client/v5/weaviate/filters"
@@ -20,12 +21,9 @@ func (a *Adapter) SaveDocuments(ctx context.Context, documents []ragserver.Docum
 		if len(vectors[i]) == 0 {
 			return fmt.Errorf("empty vector")
 		}
-		properties := map[string]any{
-			"content": doc.Content,
-			"page":    doc.Page,
-		}
-		if !doc.FileID.IsNil() {
-			properties["file_id"] = doc.FileID.String()
+		properties, err := a.documentProperties(doc)
+		if err != nil {
+			return err
 		}
 		objects[i] = &models.Object{
 			Class:      className,
@@ -43,6 +41,57 @@ func (a *Adapter) SaveDocuments(ctx context.Context, documents []ragserver.Docum
 	return err
 }
 
+func (a *Adapter) documentProperties(doc ragserver.Document) (map[string]any, error) {
+	properties := map[string]any{
+		"content": doc.Content,
+		"page":    doc.Page,
+	}
+	if doc.Section != "" {
+		properties["section"] = doc.Section
+	}
+	if doc.Language != "" {
+		properties["language"] = string(doc.Language)
+	}
+	if doc.LayoutType != "" {
+		properties["layout_type"] = doc.LayoutType
+	}
+	if doc.BoundingBox != nil {
+		properties["bounding_box"] = encodeBoundingBox(doc.BoundingBox)
+	}
+	if !doc.FileID.IsNil() {
+		properties["file_id"] = doc.FileID.String()
+	}
+	for _, field := range a.metadataFields {
+		value, ok := doc.Metadata[field.Key]
+		if !ok {
+			continue
+		}
+		propertyValue, err := metadataPropertyValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		properties[metadataFieldName(field.Key)] = propertyValue
+	}
+	return properties, nil
+}
+
+// documentFields are fields decoded by decodeGetDocumentResults.
+func (a *Adapter) documentFields() []graphql.Field {
+	fields := []graphql.Field{
+		{Name: "content"},
+		{Name: "page"},
+		{Name: "section"},
+		{Name: "language"},
+		{Name: "layout_type"},
+		{Name: "bounding_box"},
+		{Name: "file_id"},
+	}
+	for _, field := range a.metadataFields {
+		fields = append(fields, graphql.Field{Name: metadataFieldName(field.Key)})
+	}
+	return fields
+}
+
 func (a *Adapter) ListFileDocuments(ctx context.Context, id ragserver.FileID, limit int) ([]ragserver.Document, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -58,19 +107,31 @@ func (a *Adapter) SearchDocuments(ctx context.Context, filter ragserver.Document
 	builder := gql.Get().
 		WithNearVector(nearVector).
 		WithClassName("Document").
-		WithFields(
-			graphql.Field{Name: "content"},
-			graphql.Field{Name: "page"},
-			graphql.Field{Name: "file_id"},
-		).
+		WithFields(a.documentFields()...).
 		WithLimit(limit)
 
+	var operands []*filters.WhereBuilder
 	if len(filter.FileIDs) > 0 {
 		where := filters.Where()
 		where.WithOperator(filters.ContainsAny)
 		where.WithPath([]string{"file_id"})
 		where.WithValueString(fileIDsToStrings(filter.FileIDs)...)
-		builder = builder.WithWhere(where)
+		operands = append(operands, where)
+	}
+	if filter.Language != "" {
+		where := filters.Where()
+		where.WithOperator(filters.Equal)
+		where.WithPath([]string{"language"})
+		where.WithValueString(string(filter.Language))
+		operands = append(operands, where)
+	}
+	operands = append(operands, metadataWhere(filter.Metadata)...)
+	switch len(operands) {
+	case 0:
+	case 1:
+		builder = builder.WithWhere(operands[0])
+	default:
+		builder = builder.WithWhere(filters.Where().WithOperator(filters.And).WithOperands(operands))
 	}
 
 	graphqlResponse, err := builder.Do(ctx)
@@ -82,7 +143,158 @@ func (a *Adapter) SearchDocuments(ctx context.Context, filter ragserver.Document
 }
 
 func (a *Adapter) DeleteFileDocuments(ctx context.Context, id ragserver.FileID) error {
-	return fmt.Errorf("not implemented")
+	where := filters.Where()
+	where.WithOperator(filters.Equal)
+	where.WithPath([]string{"file_id"})
+	where.WithValueString(id.String())
+
+	resp, err := a.client.Batch().ObjectsBatchDeleter().
+		WithClassName(className).
+		WithWhere(where).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if resp.Results != nil && resp.Results.Failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d documents", resp.Results.Failed, resp.Results.Matches)
+	}
+
+	return nil
+}
+
+const copyBatchSize = 100
+
+func (a *Adapter) CopyFileDocuments(ctx context.Context, from, to ragserver.FileID) error {
+	// IDs are collected before copying so new documents are not visited by the cursor
+	ids, err := a.fileDocumentIDs(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	for batch := range slices.Chunk(ids, copyBatchSize) {
+		graphqlResponse, err := a.client.GraphQL().Get().
+			WithClassName(className).
+			WithFields(append(
+				a.documentFields(),
+				graphql.Field{Name: "_additional", Fields: []graphql.Field{{Name: "vector"}}},
+			)...).
+			WithWhere(documentIDsWhere(batch)).
+			WithLimit(len(batch)).
+			Do(ctx)
+		if err := combinedWeaviateError(graphqlResponse, err); err != nil {
+			return err
+		}
+
+		documents, err := decodeGetDocumentResults(graphqlResponse)
+		if err != nil {
+			return err
+		}
+		vectors, err := decodeGetDocumentVectors(graphqlResponse)
+		if err != nil {
+			return err
+		}
+
+		for i := range documents {
+			documents[i].FileID = to
+		}
+		if len(documents) > 0 {
+			if err := a.SaveDocuments(ctx, documents, vectors); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fileDocumentIDs returns IDs of all documents of the file. Paging filtered results by offset
+// is capped at QUERY_MAXIMUM_RESULTS and the cursor API can't be combined with filters, so all
+// documents are listed with the cursor and matched by file ID here.
+func (a *Adapter) fileDocumentIDs(ctx context.Context, id ragserver.FileID) ([]string, error) {
+	var (
+		ids   []string
+		after string
+	)
+
+	for {
+		builder := a.client.GraphQL().Get().
+			WithClassName(className).
+			WithFields(
+				graphql.Field{Name: "file_id"},
+				graphql.Field{Name: "_additional", Fields: []graphql.Field{{Name: "id"}}},
+			).
+			WithLimit(cursorBatchSize)
+		if after != "" {
+			builder = builder.WithAfter(after)
+		}
+		graphqlResponse, err := builder.Do(ctx)
+		if err := combinedWeaviateError(graphqlResponse, err); err != nil {
+			return nil, err
+		}
+
+		documentIDs, err := decodeGetDocumentIDs(graphqlResponse)
+		if err != nil {
+			return nil, err
+		}
+		fileIDs, err := decodeGetDocumentFileIDs(graphqlResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, fileID := range fileIDs {
+			if fileID == id.String() {
+				ids = append(ids, documentIDs[i])
+			}
+		}
+
+		if len(documentIDs) < cursorBatchSize {
+			return ids, nil
+		}
+		after = documentIDs[len(documentIDs)-1]
+	}
+}
+
+// cursorBatchSize is how many documents are listed per cursor request.
+const cursorBatchSize = 1000
+
+// documentIDsWhere matches documents by their object IDs.
+func documentIDsWhere(ids []string) *filters.WhereBuilder {
+	return filters.Where().
+		WithOperator(filters.ContainsAny).
+		WithPath([]string{"id"}).
+		WithValueText(ids...)
+}
+
+// decodeGetDocumentFileIDs decodes file IDs of documents returned by Weaviate's GraphQL Get
+// query, in the same order as decodeGetDocumentIDs.
+func decodeGetDocumentFileIDs(graphqlResponse *models.GraphQLResponse) ([]string, error) {
+	data, ok := graphqlResponse.Data["Get"]
+	if !ok {
+		return nil, fmt.Errorf("get key not found in result")
+	}
+	doc, ok := data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("get key unexpected type")
+	}
+	slc, ok := doc["Document"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("document is not a list of results")
+	}
+
+	out := make([]string, 0, len(slc))
+	for _, s := range slc {
+		smap, ok := s.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid element in list of documents")
+		}
+		fileID, ok := smap["file_id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected file_id in document")
+		}
+		out = append(out, fileID)
+	}
+	return out, nil
 }
 
 func fileIDsToStrings(fileIDs []ragserver.FileID) []string {
@@ -125,6 +337,18 @@ func decodeGetDocumentResults(graphqlResponse *models.GraphQLResponse) ([]ragser
 		if !ok {
 			return nil, fmt.Errorf("expected page in document")
 		}
+		// Section is optional, documents saved before sections were introduced don't have it
+		section, _ := smap["section"].(string)
+		language, _ := smap["language"].(string)
+		layoutType, _ := smap["layout_type"].(string)
+		box, err := decodeBoundingBox(smap["bounding_box"])
+		if err != nil {
+			return nil, err
+		}
+		metadata, err := decodeMetadata(smap)
+		if err != nil {
+			return nil, err
+		}
 		id, ok := smap["file_id"].(string)
 		if !ok {
 			return nil, fmt.Errorf("expected file_id in document")
@@ -134,14 +358,96 @@ func decodeGetDocumentResults(graphqlResponse *models.GraphQLResponse) ([]ragser
 			return nil, fmt.Errorf("invalid file_id in document: %w", err)
 		}
 		out = append(out, ragserver.Document{
-			Content: content,
-			Page:    int(page),
-			FileID:  ragserver.FileID{UUID: fileID},
+			Content:     content,
+			Page:        int(page),
+			Section:     section,
+			Language:    ragserver.Language(language),
+			LayoutType:  layoutType,
+			BoundingBox: box,
+			Metadata:    metadata,
+			FileID:      ragserver.FileID{UUID: fileID},
 		})
 	}
 	return out, nil
 }
 
+// decodeGetDocumentVectors decodes vectors of documents returned by Weaviate's GraphQL Get
+// query with the _additional { vector } field, in the same order as decodeGetDocumentResults.
+func decodeGetDocumentVectors(graphqlResponse *models.GraphQLResponse) ([]ragserver.Vector, error) {
+	data, ok := graphqlResponse.Data["Get"]
+	if !ok {
+		return nil, fmt.Errorf("get key not found in result")
+	}
+	doc, ok := data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("get key unexpected type")
+	}
+	slc, ok := doc["Document"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("document is not a list of results")
+	}
+
+	out := make([]ragserver.Vector, 0, len(slc))
+	for _, s := range slc {
+		smap, ok := s.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid element in list of documents")
+		}
+		additional, ok := smap["_additional"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected _additional in document")
+		}
+		values, ok := additional["vector"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected vector in document")
+		}
+		vector := make(ragserver.Vector, 0, len(values))
+		for _, value := range values {
+			f, ok := value.(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid value in vector")
+			}
+			vector = append(vector, float32(f))
+		}
+		out = append(out, vector)
+	}
+	return out, nil
+}
+
+// encodeBoundingBox stores the bounding box as a number array, nested objects would require
+// declaring their properties in the schema.
+func encodeBoundingBox(box *ragserver.BoundingBox) []float64 {
+	return []float64{box.Left, box.Top, box.Width, box.Height, box.PageWidth, box.PageHeight}
+}
+
+// decodeBoundingBox decodes a bounding box stored by encodeBoundingBox, it returns nil for
+// documents without one.
+func decodeBoundingBox(value any) (*ragserver.BoundingBox, error) {
+	if value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]any)
+	if !ok || len(values) != 6 {
+		return nil, fmt.Errorf("invalid bounding_box in document")
+	}
+	numbers := make([]float64, 0, len(values))
+	for _, v := range values {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid value in bounding_box")
+		}
+		numbers = append(numbers, f)
+	}
+	return &ragserver.BoundingBox{
+		Left:       numbers[0],
+		Top:        numbers[1],
+		Width:      numbers[2],
+		Height:     numbers[3],
+		PageWidth:  numbers[4],
+		PageHeight: numbers[5],
+	}, nil
+}
+
 // combinedWeaviateError generates an error if err is non-nil or result has
 // errors, and returns an error (or nil if there's no error). It's useful for
 // the results of the Weaviate GraphQL API's "Do" calls.
@@ -46,9 +46,14 @@ func TestDecodeGetDocumentResults(t *testing.T) {
 								"file_id": fileID1.String(),
 							},
 							map[string]any{
-								"content": "bar",
-								"page":    float64(43),
-								"file_id": fileID2.String(),
+								"content":             "bar",
+								"page":                float64(43),
+								"file_id":             fileID2.String(),
+								"language":            "de",
+								"layout_type":         "Table",
+								"bounding_box":        []any{float64(50), float64(200), float64(500), float64(120), float64(612), float64(792)},
+								"meta_company":        "Acme",
+								"meta_reporting_year": float64(2024),
 							},
 						},
 					},
@@ -61,12 +66,42 @@ func TestDecodeGetDocumentResults(t *testing.T) {
 					FileID:  ragserver.FileID{UUID: fileID1},
 				},
 				{
-					Content: "bar",
-					Page:    43,
-					FileID:  ragserver.FileID{UUID: fileID2},
+					Content:    "bar",
+					Page:       43,
+					FileID:     ragserver.FileID{UUID: fileID2},
+					Language:   ragserver.LanguageGerman,
+					LayoutType: "Table",
+					BoundingBox: &ragserver.BoundingBox{
+						Left:       50,
+						Top:        200,
+						Width:      500,
+						Height:     120,
+						PageWidth:  612,
+						PageHeight: 792,
+					},
+					Metadata: ragserver.Metadata{"company": "Acme", "reporting_year": "2024"},
+				},
+			},
+			nil,
+		},
+		{
+			"Invalid bounding box",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{
+					"Get": map[string]any{
+						"Document": []any{
+							map[string]any{
+								"content":      "foo",
+								"page":         float64(5),
+								"file_id":      fileID1.String(),
+								"bounding_box": []any{float64(50), float64(200)},
+							},
+						},
+					},
 				},
 			},
 			nil,
+			fmt.Errorf("invalid bounding_box in document"),
 		},
 	}
 
@@ -83,3 +118,154 @@ func TestDecodeGetDocumentResults(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeGetDocumentVectors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title       string
+		given       *models.GraphQLResponse
+		expected    []ragserver.Vector
+		expectedErr error
+	}{
+		{
+			"Missing Get key",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{},
+			},
+			nil,
+			fmt.Errorf("get key not found in result"),
+		},
+		{
+			"Missing vector",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{
+					"Get": map[string]any{
+						"Document": []any{
+							map[string]any{
+								"content":     "foo",
+								"_additional": map[string]any{},
+							},
+						},
+					},
+				},
+			},
+			nil,
+			fmt.Errorf("expected vector in document"),
+		},
+		{
+			"Valid results",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{
+					"Get": map[string]any{
+						"Document": []any{
+							map[string]any{
+								"content": "foo",
+								"_additional": map[string]any{
+									"vector": []any{float64(0.5), float64(-0.25)},
+								},
+							},
+							map[string]any{
+								"content": "bar",
+								"_additional": map[string]any{
+									"vector": []any{float64(1), float64(0)},
+								},
+							},
+						},
+					},
+				},
+			},
+			[]ragserver.Vector{
+				{0.5, -0.25},
+				{1, 0},
+			},
+			nil,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("#%v_%v", i, tc.title), func(t *testing.T) {
+			actual, err := decodeGetDocumentVectors(tc.given)
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestDecodeGetDocumentFileIDs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title       string
+		given       *models.GraphQLResponse
+		expected    []string
+		expectedErr error
+	}{
+		{
+			"Missing Get key",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{},
+			},
+			nil,
+			fmt.Errorf("get key not found in result"),
+		},
+		{
+			"Missing file_id",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{
+					"Get": map[string]any{
+						"Document": []any{
+							map[string]any{
+								"_additional": map[string]any{"id": "6a8d3c0e-5b0e-4c43-9f6e-2d1c1f5f3b7a"},
+							},
+						},
+					},
+				},
+			},
+			nil,
+			fmt.Errorf("expected file_id in document"),
+		},
+		{
+			"Valid results",
+			&models.GraphQLResponse{
+				Data: map[string]models.JSONObject{
+					"Get": map[string]any{
+						"Document": []any{
+							map[string]any{
+								"file_id":     "9ea0b16a-7f4a-4a22-8ea1-ca2d932bafa8",
+								"_additional": map[string]any{"id": "6a8d3c0e-5b0e-4c43-9f6e-2d1c1f5f3b7a"},
+							},
+							map[string]any{
+								"file_id":     "1ad113d9-38f9-42d1-b205-4383250a4dfd",
+								"_additional": map[string]any{"id": "0f4b7c1e-2f3a-4d5b-8c6d-7e8f9a0b1c2d"},
+							},
+						},
+					},
+				},
+			},
+			[]string{
+				"9ea0b16a-7f4a-4a22-8ea1-ca2d932bafa8",
+				"1ad113d9-38f9-42d1-b205-4383250a4dfd",
+			},
+			nil,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("#%v_%v", i, tc.title), func(t *testing.T) {
+			actual, err := decodeGetDocumentFileIDs(tc.given)
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
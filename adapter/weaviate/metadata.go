@@ -0,0 +1,190 @@
+package weaviate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/filters"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/graphql"
+	"github.com/weaviate/weaviate/entities/models"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// metadataFieldPrefix keeps metadata properties apart from other properties of documents.
+const metadataFieldPrefix = "meta_"
+
+func metadataFieldName(key string) string {
+	return metadataFieldPrefix + key
+}
+
+// metadataProperty returns the property of a metadata field, tag values are not tokenized
+// so they are matched exactly.
+func metadataProperty(field ragserver.MetadataField) *models.Property {
+	if field.Type == ragserver.MetadataFieldNumeric {
+		return &models.Property{Name: metadataFieldName(field.Key), DataType: []string{"number"}}
+	}
+	return &models.Property{
+		Name:         metadataFieldName(field.Key),
+		DataType:     []string{"text"},
+		Tokenization: models.PropertyTokenizationField,
+	}
+}
+
+func metadataPropertyValue(field ragserver.MetadataField, value string) (any, error) {
+	if field.Type != ragserver.MetadataFieldNumeric {
+		return value, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of numeric metadata %s: %w", field.Key, err)
+	}
+	return number, nil
+}
+
+// decodeMetadata decodes metadata properties of a document, numbers are formatted
+// the shortest way which parses back to the same value.
+func decodeMetadata(smap map[string]any) (ragserver.Metadata, error) {
+	var metadata ragserver.Metadata
+	for name, value := range smap {
+		key, ok := strings.CutPrefix(name, metadataFieldPrefix)
+		if !ok || value == nil {
+			continue
+		}
+		if metadata == nil {
+			metadata = ragserver.Metadata{}
+		}
+		switch v := value.(type) {
+		case string:
+			metadata[key] = v
+		case float64:
+			metadata[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("invalid %s in document", name)
+		}
+	}
+	return metadata, nil
+}
+
+// metadataWhere returns where filters matching documents with all conditions of the filter.
+func metadataWhere(filter ragserver.MetadataFilter) []*filters.WhereBuilder {
+	var operands []*filters.WhereBuilder
+	for _, condition := range filter {
+		path := []string{metadataFieldName(condition.Key)}
+		if !condition.IsRange() {
+			operands = append(operands, filters.Where().
+				WithOperator(filters.ContainsAny).
+				WithPath(path).
+				WithValueString(condition.Values...))
+			continue
+		}
+		if condition.Min != nil {
+			operands = append(operands, filters.Where().
+				WithOperator(filters.GreaterThanEqual).
+				WithPath(path).
+				WithValueNumber(*condition.Min))
+		}
+		if condition.Max != nil {
+			operands = append(operands, filters.Where().
+				WithOperator(filters.LessThanEqual).
+				WithPath(path).
+				WithValueNumber(*condition.Max))
+		}
+	}
+	return operands
+}
+
+// UpdateFileMetadata replaces metadata properties of all documents of the file. Objects are
+// replaced rather than merged, merging can't remove properties of keys missing from the metadata.
+func (a *Adapter) UpdateFileMetadata(ctx context.Context, id ragserver.FileID, metadata ragserver.Metadata) error {
+	// IDs are collected before updating, see fileDocumentIDs
+	ids, err := a.fileDocumentIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for batch := range slices.Chunk(ids, copyBatchSize) {
+		graphqlResponse, err := a.client.GraphQL().Get().
+			WithClassName(className).
+			WithFields(append(
+				a.documentFields(),
+				graphql.Field{Name: "_additional", Fields: []graphql.Field{{Name: "id"}, {Name: "vector"}}},
+			)...).
+			WithWhere(documentIDsWhere(batch)).
+			WithLimit(len(batch)).
+			Do(ctx)
+		if err := combinedWeaviateError(graphqlResponse, err); err != nil {
+			return err
+		}
+
+		documents, err := decodeGetDocumentResults(graphqlResponse)
+		if err != nil {
+			return err
+		}
+		vectors, err := decodeGetDocumentVectors(graphqlResponse)
+		if err != nil {
+			return err
+		}
+		documentIDs, err := decodeGetDocumentIDs(graphqlResponse)
+		if err != nil {
+			return err
+		}
+
+		for i, doc := range documents {
+			doc.Metadata = metadata
+			properties, err := a.documentProperties(doc)
+			if err != nil {
+				return err
+			}
+
+			if err := a.client.Data().Updater().
+				WithClassName(className).
+				WithID(documentIDs[i]).
+				WithProperties(properties).
+				WithVector(vectors[i]).
+				Do(ctx); err != nil {
+				return fmt.Errorf("error updating document %s: %w", documentIDs[i], err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeGetDocumentIDs decodes IDs of documents returned by Weaviate's GraphQL Get
+// query with the _additional { id } field, in the same order as decodeGetDocumentResults.
+func decodeGetDocumentIDs(graphqlResponse *models.GraphQLResponse) ([]string, error) {
+	data, ok := graphqlResponse.Data["Get"]
+	if !ok {
+		return nil, fmt.Errorf("get key not found in result")
+	}
+	doc, ok := data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("get key unexpected type")
+	}
+	slc, ok := doc["Document"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("document is not a list of results")
+	}
+
+	out := make([]string, 0, len(slc))
+	for _, s := range slc {
+		smap, ok := s.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid element in list of documents")
+		}
+		additional, ok := smap["_additional"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected _additional in document")
+		}
+		id, ok := additional["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected id in document")
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
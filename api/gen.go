@@ -14,6 +14,14 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for FileStage.
+const (
+	EMBEDDING  FileStage = "EMBEDDING"
+	EXTRACTING FileStage = "EXTRACTING"
+	INDEXING   FileStage = "INDEXING"
+	READING    FileStage = "READING"
+)
+
 // Defines values for FileStatus.
 const (
 	PROCESSEDSUCCESSFULLY FileStatus = "PROCESSED_SUCCESSFULLY"
@@ -22,6 +30,24 @@ const (
 	UPLOADED              FileStatus = "UPLOADED"
 )
 
+// Defines values for Language.
+const (
+	De Language = "de"
+	En Language = "en"
+	Es Language = "es"
+	Fr Language = "fr"
+	It Language = "it"
+	Nl Language = "nl"
+	Pt Language = "pt"
+)
+
+// Defines values for QueryParamsType.
+const (
+	QueryParamsTypeBOOLEAN QueryParamsType = "BOOLEAN"
+	QueryParamsTypeMETRIC  QueryParamsType = "METRIC"
+	QueryParamsTypeTEXT    QueryParamsType = "TEXT"
+)
+
 // Defines values for QuestionType.
 const (
 	QuestionTypeBOOLEAN QuestionType = "BOOLEAN"
@@ -31,9 +57,9 @@ const (
 
 // Defines values for QuestionParamsType.
 const (
-	QuestionParamsTypeBOOLEAN QuestionParamsType = "BOOLEAN"
-	QuestionParamsTypeMETRIC  QuestionParamsType = "METRIC"
-	QuestionParamsTypeTEXT    QuestionParamsType = "TEXT"
+	BOOLEAN QuestionParamsType = "BOOLEAN"
+	METRIC  QuestionParamsType = "METRIC"
+	TEXT    QuestionParamsType = "TEXT"
 )
 
 // Defines values for ScreeningStatus.
@@ -44,6 +70,12 @@ const (
 	SUCCESSFUL ScreeningStatus = "SUCCESSFUL"
 )
 
+// Defines values for GetFileTextParamsFormat.
+const (
+	Markdown GetFileTextParamsFormat = "markdown"
+	Text     GetFileTextParamsFormat = "text"
+)
+
 // Answer defines model for Answer.
 type Answer struct {
 	Boolean    *bool              `json:"boolean,omitempty"`
@@ -53,11 +85,30 @@ type Answer struct {
 	Text       string             `json:"text"`
 }
 
+// BoundingBox Region of the page the content was extracted from, in the units of the page size measured from the top left corner
+type BoundingBox struct {
+	Height     float64 `json:"height"`
+	Left       float64 `json:"left"`
+	PageHeight float64 `json:"page_height"`
+	PageWidth  float64 `json:"page_width"`
+	Top        float64 `json:"top"`
+	Width      float64 `json:"width"`
+}
+
 // Document defines model for Document.
 type Document struct {
-	Content  string   `json:"content"`
-	Distance *float64 `json:"distance,omitempty"`
-	Page     int32    `json:"page"`
+	// BoundingBox Region of the page the content was extracted from, in the units of the page size measured from the top left corner
+	BoundingBox *BoundingBox `json:"bounding_box,omitempty"`
+	Content     string       `json:"content"`
+	Distance    *float64     `json:"distance,omitempty"`
+
+	// Language ISO 639-1 code of a language detected in file contents
+	Language *Language `json:"language,omitempty"`
+
+	// LayoutType Type of the layout item the content was extracted from, e.g. Text or Table
+	LayoutType *string `json:"layout_type,omitempty"`
+	Page       int32   `json:"page"`
+	Section    *string `json:"section,omitempty"`
 }
 
 // Documents defines model for Documents.
@@ -67,39 +118,199 @@ type Documents struct {
 
 // Evidence defines model for Evidence.
 type Evidence struct {
-	FileId openapi_types.UUID `json:"file_id"`
-	Page   int32              `json:"page"`
-	Text   string             `json:"text"`
+	// BoundingBox Region of the page the content was extracted from, in the units of the page size measured from the top left corner
+	BoundingBox *BoundingBox       `json:"bounding_box,omitempty"`
+	FileId      openapi_types.UUID `json:"file_id"`
+
+	// LayoutType Type of the layout item the content was extracted from, e.g. Text or Table
+	LayoutType *string `json:"layout_type,omitempty"`
+	Page       int32   `json:"page"`
+	Text       string  `json:"text"`
 }
 
 // File defines model for File.
 type File struct {
-	ContentType   string             `json:"content_type"`
-	CreatedAt     time.Time          `json:"created_at"`
-	Extension     string             `json:"extension"`
-	FileName      string             `json:"file_name"`
-	Hash          string             `json:"hash"`
-	Id            openapi_types.UUID `json:"id"`
-	Size          int64              `json:"size"`
-	Status        FileStatus         `json:"status"`
-	StatusMessage string             `json:"status_message"`
-	UpdatedAt     time.Time          `json:"updated_at"`
+	// Attempts Number of times processing of the file has started
+	Attempts int `json:"attempts"`
+
+	// Chunker Chunking strategy used to split documents extracted from the file
+	Chunker     string             `json:"chunker"`
+	ContentType string             `json:"content_type"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Extension   string             `json:"extension"`
+	FileName    string             `json:"file_name"`
+	Hash        string             `json:"hash"`
+	Id          openapi_types.UUID `json:"id"`
+
+	// Language ISO 639-1 code of a language detected in file contents
+	Language *Language `json:"language,omitempty"`
+
+	// LogicalDocumentId Logical document the file is a version of, only set for versions of logical documents
+	LogicalDocumentId *openapi_types.UUID `json:"logical_document_id,omitempty"`
+
+	// Metadata Key/value pairs describing a file such as company name, reporting year or document type. Keys are lowercase letters, digits and underscores starting with a letter.
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// NextAttemptAt When processing of a failed file is retried, only set if a retry is scheduled
+	NextAttemptAt *time.Time   `json:"next_attempt_at,omitempty"`
+	Progress      FileProgress `json:"progress"`
+	Size          int64        `json:"size"`
+
+	// SourceUrl URL the file was downloaded from, only set for imported files
+	SourceUrl *string `json:"source_url,omitempty"`
+
+	// Stage Stage of the processing pipeline the file is in or last reached, not set before processing starts
+	Stage         *FileStage `json:"stage,omitempty"`
+	Status        FileStatus `json:"status"`
+	StatusMessage string     `json:"status_message"`
+
+	// Topics Topics selected when the file was uploaded, not set for topics configured on the server
+	Topics    *FileTopics `json:"topics,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at"`
+
+	// Version Version of the logical document starting from 1, only set for versions of logical documents
+	Version *int `json:"version,omitempty"`
 }
 
+// FileStage Stage of the processing pipeline the file is in or last reached, not set before processing starts
+type FileStage string
+
 // FileStatus defines model for File.Status.
 type FileStatus string
 
+// FileBatch defines model for FileBatch.
+type FileBatch struct {
+	Results []FileBatchResult `json:"results"`
+}
+
+// FileBatchResult defines model for FileBatchResult.
+type FileBatchResult struct {
+	// Error Reason the file could not be created, file is not set in that case
+	Error    *string `json:"error,omitempty"`
+	File     *File   `json:"file,omitempty"`
+	FileName string  `json:"file_name"`
+}
+
+// FileProgress defines model for FileProgress.
+type FileProgress struct {
+	// Documents Number of documents extracted from the file
+	Documents int `json:"documents"`
+
+	// DocumentsEmbedded Number of extracted documents embedded so far
+	DocumentsEmbedded int `json:"documents_embedded"`
+
+	// Pages Number of pages extracted from the file
+	Pages int `json:"pages"`
+}
+
+// FileTopics Topics selected when the file was uploaded, not set for topics configured on the server
+type FileTopics struct {
+	// Name Name of the topic set preset, not set for topics sent with the upload
+	Name   *string `json:"name,omitempty"`
+	Topics []Topic `json:"topics"`
+}
+
+// FileUpdateParams defines model for FileUpdateParams.
+type FileUpdateParams struct {
+	// Metadata Key/value pairs describing a file such as company name, reporting year or document type. Keys are lowercase letters, digits and underscores starting with a letter.
+	Metadata Metadata `json:"metadata"`
+}
+
 // Files defines model for Files.
 type Files struct {
 	Files []File `json:"files"`
 }
 
+// ImportFileParams defines model for ImportFileParams.
+type ImportFileParams struct {
+	// Metadata Key/value pairs describing a file such as company name, reporting year or document type. Keys are lowercase letters, digits and underscores starting with a letter.
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// TopicSet Name of a topic set preset documents of the file are filtered by
+	TopicSet *string `json:"topic_set,omitempty"`
+
+	// Topics Topics documents of the file are filtered by, instead of a preset
+	Topics *[]Topic `json:"topics,omitempty"`
+
+	// Url HTTP or HTTPS URL of the file to download
+	Url string `json:"url"`
+}
+
+// Language ISO 639-1 code of a language detected in file contents
+type Language string
+
+// LogicalDocument defines model for LogicalDocument.
+type LogicalDocument struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// LatestFileId File of the most recently added version, not set if the document has no versions
+	LatestFileId *openapi_types.UUID `json:"latest_file_id,omitempty"`
+	Name         string              `json:"name"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+
+	// Versions Files of all versions from the oldest
+	Versions []File `json:"versions"`
+}
+
+// LogicalDocumentParams defines model for LogicalDocumentParams.
+type LogicalDocumentParams struct {
+	// FileIds Files added as versions from the oldest
+	FileIds *[]openapi_types.UUID `json:"file_ids,omitempty"`
+	Name    string                `json:"name"`
+}
+
+// LogicalDocumentVersionParams defines model for LogicalDocumentVersionParams.
+type LogicalDocumentVersionParams struct {
+	FileId openapi_types.UUID `json:"file_id"`
+}
+
+// LogicalDocuments defines model for LogicalDocuments.
+type LogicalDocuments struct {
+	LogicalDocuments []LogicalDocument `json:"logical_documents"`
+}
+
+// Metadata Key/value pairs describing a file such as company name, reporting year or document type. Keys are lowercase letters, digits and underscores starting with a letter.
+type Metadata map[string]string
+
+// MetadataCondition Matches metadata with any of the values of the key, or for numeric keys a value between min and max inclusive. Either values or a range can be set, not both.
+type MetadataCondition struct {
+	Key    string    `json:"key"`
+	Max    *float64  `json:"max,omitempty"`
+	Min    *float64  `json:"min,omitempty"`
+	Values *[]string `json:"values,omitempty"`
+}
+
 // MetricValue defines model for MetricValue.
 type MetricValue struct {
 	Unit  *string `json:"unit,omitempty"`
 	Value float64 `json:"value"`
 }
 
+// QueryParams defines model for QueryParams.
+type QueryParams struct {
+	Content string               `json:"content"`
+	FileIds []openapi_types.UUID `json:"file_ids"`
+
+	// Language ISO 639-1 code of a language detected in file contents
+	Language *Language `json:"language,omitempty"`
+
+	// Metadata Only use documents of files with matching indexed metadata, all conditions must match
+	Metadata *[]MetadataCondition `json:"metadata,omitempty"`
+	Type     QueryParamsType      `json:"type"`
+}
+
+// QueryParamsType defines model for QueryParams.Type.
+type QueryParamsType string
+
+// QueryResponse defines model for QueryResponse.
+type QueryResponse struct {
+	Boolean  *bool        `json:"boolean,omitempty"`
+	Evidence []Evidence   `json:"evidence"`
+	Metric   *MetricValue `json:"metric,omitempty"`
+	Text     string       `json:"text"`
+}
+
 // Question defines model for Question.
 type Question struct {
 	Content string             `json:"content"`
@@ -134,11 +345,14 @@ type Screening struct {
 // ScreeningStatus defines model for Screening.Status.
 type ScreeningStatus string
 
-// ScreeningParams defines model for ScreeningParams.
+// ScreeningParams At least one file or logical document is required.
 type ScreeningParams struct {
-	FileIds   []openapi_types.UUID `json:"file_ids"`
-	Id        *openapi_types.UUID  `json:"id,omitempty"`
-	Questions []QuestionParams     `json:"questions"`
+	FileIds *[]openapi_types.UUID `json:"file_ids,omitempty"`
+	Id      *openapi_types.UUID   `json:"id,omitempty"`
+
+	// LogicalDocumentIds Logical documents resolved to their latest processed versions, the screening records files of the exact versions it used.
+	LogicalDocumentIds *[]openapi_types.UUID `json:"logical_document_ids,omitempty"`
+	Questions          []QuestionParams      `json:"questions"`
 }
 
 // Screenings defines model for Screenings.
@@ -146,9 +360,107 @@ type Screenings struct {
 	Screenings []Screening `json:"screenings"`
 }
 
+// Topic Content is relevant to a topic if it contains at least min_matches of the keywords and patterns, and none of the exclude keywords. Matching is case insensitive, patterns are regular expressions.
+type Topic struct {
+	// Description Describes the topic for semantic relevance filters which don't match keywords and patterns
+	Description     *string   `json:"description,omitempty"`
+	ExcludeKeywords *[]string `json:"exclude_keywords,omitempty"`
+	Keywords        *[]string `json:"keywords,omitempty"`
+
+	// MinMatches Minimum number of keywords and patterns which must match, defaults to 1
+	MinMatches *int      `json:"min_matches,omitempty"`
+	Name       string    `json:"name"`
+	Patterns   *[]string `json:"patterns,omitempty"`
+}
+
+// TopicSet defines model for TopicSet.
+type TopicSet struct {
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	Topics    []Topic   `json:"topics"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TopicSetParams defines model for TopicSetParams.
+type TopicSetParams struct {
+	Topics []Topic `json:"topics"`
+}
+
+// TopicSets defines model for TopicSets.
+type TopicSets struct {
+	TopicSets []TopicSet `json:"topic_sets"`
+}
+
+// Upload defines model for Upload.
+type Upload struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// FileId File created from the upload, only set once all bytes are received
+	FileId   *openapi_types.UUID `json:"file_id,omitempty"`
+	FileName string              `json:"file_name"`
+	Id       openapi_types.UUID  `json:"id"`
+
+	// Offset Number of bytes received so far
+	Offset    int64     `json:"offset"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UploadParams defines model for UploadParams.
+type UploadParams struct {
+	FileName string `json:"file_name"`
+
+	// Metadata Key/value pairs describing a file such as company name, reporting year or document type. Keys are lowercase letters, digits and underscores starting with a letter.
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Size Total size of the file in bytes
+	Size int64 `json:"size"`
+
+	// TopicSet Name of a topic set preset documents of the file are filtered by
+	TopicSet *string `json:"topic_set,omitempty"`
+
+	// Topics Topics documents of the file are filtered by, instead of a preset
+	Topics *[]Topic `json:"topics,omitempty"`
+}
+
+// ListFilesParams defines parameters for ListFiles.
+type ListFilesParams struct {
+	// Metadata Only list files with matching metadata, conditions are written as key:value, key>=number or key<=number. Alternative values are separated by a pipe, e.g. company:Acme|Globex. Files must match all conditions.
+	Metadata *[]string `form:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
 // UploadFileMultipartBody defines parameters for UploadFile.
 type UploadFileMultipartBody struct {
+	File *[]openapi_types.File `json:"file,omitempty"`
+
+	// Metadata JSON object of metadata recorded on the files, e.g. {"company":"Acme","reporting_year":"2024"}
+	Metadata *string `json:"metadata,omitempty"`
+
+	// TopicSet Name of a topic set preset documents of the files are filtered by
+	TopicSet *string `json:"topic_set,omitempty"`
+
+	// Topics JSON array of topics documents of the files are filtered by, instead of a preset
+	Topics *string `json:"topics,omitempty"`
+}
+
+// UploadArchiveMultipartBody defines parameters for UploadArchive.
+type UploadArchiveMultipartBody struct {
 	File *openapi_types.File `json:"file,omitempty"`
+
+	// Metadata JSON object of metadata recorded on the files, e.g. {"company":"Acme","reporting_year":"2024"}
+	Metadata *string `json:"metadata,omitempty"`
+
+	// TopicSet Name of a topic set preset documents of the files are filtered by
+	TopicSet *string `json:"topic_set,omitempty"`
+
+	// Topics JSON array of topics documents of the files are filtered by, instead of a preset
+	Topics *string `json:"topics,omitempty"`
+}
+
+// ReindexFilesParams defines parameters for ReindexFiles.
+type ReindexFilesParams struct {
+	// All Reprocess all processed files, including those processed with the current embedder and retriever
+	All *bool `form:"all,omitempty" json:"all,omitempty"`
 }
 
 // ListFileDocumentsParams defines parameters for ListFileDocuments.
@@ -156,33 +468,120 @@ type ListFileDocumentsParams struct {
 	// SimilarTo Return documents similar to this text (using vector search)
 	SimilarTo *string `form:"similar_to,omitempty" json:"similar_to,omitempty"`
 
+	// Language Only return similar documents in this language, used together with similar_to
+	Language *Language `form:"language,omitempty" json:"language,omitempty"`
+
 	// Limit Max number of documents to return
 	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// GetFileTextParams defines parameters for GetFileText.
+type GetFileTextParams struct {
+	// Format Return plain text or Markdown with pages and sections as headings
+	Format *GetFileTextParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetFileTextParamsFormat defines parameters for GetFileText.
+type GetFileTextParamsFormat string
+
+// PatchUploadParams defines parameters for PatchUpload.
+type PatchUploadParams struct {
+	// UploadOffset Number of bytes received so far, as returned by HEAD request
+	UploadOffset int64 `json:"Upload-Offset"`
+}
+
 // UploadFileMultipartRequestBody defines body for UploadFile for multipart/form-data ContentType.
 type UploadFileMultipartRequestBody UploadFileMultipartBody
 
+// UploadArchiveMultipartRequestBody defines body for UploadArchive for multipart/form-data ContentType.
+type UploadArchiveMultipartRequestBody UploadArchiveMultipartBody
+
+// ImportFileJSONRequestBody defines body for ImportFile for application/json ContentType.
+type ImportFileJSONRequestBody = ImportFileParams
+
+// UpdateFileByIdJSONRequestBody defines body for UpdateFileById for application/json ContentType.
+type UpdateFileByIdJSONRequestBody = FileUpdateParams
+
+// CreateLogicalDocumentJSONRequestBody defines body for CreateLogicalDocument for application/json ContentType.
+type CreateLogicalDocumentJSONRequestBody = LogicalDocumentParams
+
+// AddLogicalDocumentVersionJSONRequestBody defines body for AddLogicalDocumentVersion for application/json ContentType.
+type AddLogicalDocumentVersionJSONRequestBody = LogicalDocumentVersionParams
+
+// QueryJSONRequestBody defines body for Query for application/json ContentType.
+type QueryJSONRequestBody = QueryParams
+
+// StreamQueryJSONRequestBody defines body for StreamQuery for application/json ContentType.
+type StreamQueryJSONRequestBody = QueryParams
+
 // CreateScreeningJSONRequestBody defines body for CreateScreening for application/json ContentType.
 type CreateScreeningJSONRequestBody = ScreeningParams
 
+// SaveTopicSetJSONRequestBody defines body for SaveTopicSet for application/json ContentType.
+type SaveTopicSetJSONRequestBody = TopicSetParams
+
+// CreateUploadJSONRequestBody defines body for CreateUpload for application/json ContentType.
+type CreateUploadJSONRequestBody = UploadParams
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// List uploaded files
 	// (GET /files)
-	ListFiles(w http.ResponseWriter, r *http.Request)
-	// Upload a file and add documents extracted from it to the knowledge base
+	ListFiles(w http.ResponseWriter, r *http.Request, params ListFilesParams)
+	// Upload one or more files and add documents extracted from them to the knowledge base. Multiple files are uploaded by sending multiple file parts.
 	// (POST /files)
 	UploadFile(w http.ResponseWriter, r *http.Request)
+	// Upload a ZIP archive and create a file from each entry. Entries which can't be created, for example because their content type is not supported, are reported in the results.
+	// (POST /files/archive)
+	UploadArchive(w http.ResponseWriter, r *http.Request)
+	// Download a file from a URL and add documents extracted from it to the knowledge base
+	// (POST /files/import)
+	ImportFile(w http.ResponseWriter, r *http.Request)
+	// Reprocess files embedded with a different embedder or stored by a different retriever than the current ones. Old documents are deleted and files are processed again.
+	// (POST /files/reindex)
+	ReindexFiles(w http.ResponseWriter, r *http.Request, params ReindexFilesParams)
 	// Delete a file by ID
 	// (DELETE /files/{id})
 	DeleteFileById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
 	// Get a single file by ID
 	// (GET /files/{id})
 	GetFileById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Update metadata of a file. Metadata is replaced, documents of processed files are updated with indexed metadata so retrieval can be filtered by it.
+	// (PATCH /files/{id})
+	UpdateFileById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Download original contents of a file, Range requests are supported to download only part of the contents
+	// (GET /files/{id}/content)
+	GetFileContent(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
 	// List file documents
 	// (GET /files/{id}/documents)
 	ListFileDocuments(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params ListFileDocumentsParams)
+	// Delete documents of a processed file and process it again with the current extractor, embedder and retriever
+	// (POST /files/{id}/reprocess)
+	ReprocessFile(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Get text extracted from a file, grouped by page. Documents are saved as extracted when the file is processed before they are chunked, so this is the text the extractor saw.
+	// (GET /files/{id}/text)
+	GetFileText(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params GetFileTextParams)
+	// List logical documents with their versions
+	// (GET /logical-documents)
+	ListLogicalDocuments(w http.ResponseWriter, r *http.Request)
+	// Create a logical document grouping files which are versions of the same document, files are added as versions in the given order.
+	// (POST /logical-documents)
+	CreateLogicalDocument(w http.ResponseWriter, r *http.Request)
+	// Delete a logical document, files of its versions are kept
+	// (DELETE /logical-documents/{id})
+	DeleteLogicalDocumentById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Get a logical document by ID
+	// (GET /logical-documents/{id})
+	GetLogicalDocumentById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Add a file as the latest version of a logical document. Screenings created with the logical document use the latest version once it has been processed.
+	// (POST /logical-documents/{id}/versions)
+	AddLogicalDocumentVersion(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Answer a single question using the given files, without creating a screening.
+	// (POST /query)
+	Query(w http.ResponseWriter, r *http.Request)
+	// Answer a single question using the given files, streaming the answer as Server-Sent Events.
+	// (POST /query/stream)
+	StreamQuery(w http.ResponseWriter, r *http.Request)
 	// List screenings
 	// (GET /screenings)
 	ListScreenings(w http.ResponseWriter, r *http.Request)
@@ -195,6 +594,30 @@ type ServerInterface interface {
 	// Get a single screening by ID
 	// (GET /screenings/{id})
 	GetScreeningById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// List topic set presets
+	// (GET /topic-sets)
+	ListTopicSets(w http.ResponseWriter, r *http.Request)
+	// Delete a topic set preset
+	// (DELETE /topic-sets/{name})
+	DeleteTopicSet(w http.ResponseWriter, r *http.Request, name string)
+	// Get a topic set preset by name
+	// (GET /topic-sets/{name})
+	GetTopicSet(w http.ResponseWriter, r *http.Request, name string)
+	// Create a topic set preset or replace topics of an existing one. Files already uploaded with the preset keep the topics they were uploaded with.
+	// (PUT /topic-sets/{name})
+	SaveTopicSet(w http.ResponseWriter, r *http.Request, name string)
+	// Start a resumable upload of a file, contents are sent in chunks with PATCH requests
+	// (POST /uploads)
+	CreateUpload(w http.ResponseWriter, r *http.Request)
+	// Cancel an upload
+	// (DELETE /uploads/{id})
+	DeleteUploadById(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Get the offset to resume an upload from
+	// (HEAD /uploads/{id})
+	GetUploadOffset(w http.ResponseWriter, r *http.Request, id openapi_types.UUID)
+	// Append a chunk to an upload. Once all bytes are received, a file is created and processed the same way as an uploaded file.
+	// (PATCH /uploads/{id})
+	PatchUpload(w http.ResponseWriter, r *http.Request, id openapi_types.UUID, params PatchUploadParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -209,8 +632,21 @@ type MiddlewareFunc func(http.Handler) http.Handler
 // ListFiles operation middleware
 func (siw *ServerInterfaceWrapper) ListFiles(w http.ResponseWriter, r *http.Request) {
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListFilesParams
+
+	// ------------- Optional query parameter "metadata" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "metadata", r.URL.Query(), &params.Metadata)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "metadata", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ListFiles(w, r)
+		siw.Handler.ListFiles(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -234,6 +670,61 @@ func (siw *ServerInterfaceWrapper) UploadFile(w http.ResponseWriter, r *http.Req
 	handler.ServeHTTP(w, r)
 }
 
+// UploadArchive operation middleware
+func (siw *ServerInterfaceWrapper) UploadArchive(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UploadArchive(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ImportFile operation middleware
+func (siw *ServerInterfaceWrapper) ImportFile(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ImportFile(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ReindexFiles operation middleware
+func (siw *ServerInterfaceWrapper) ReindexFiles(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ReindexFilesParams
+
+	// ------------- Optional query parameter "all" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "all", r.URL.Query(), &params.All)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "all", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ReindexFiles(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // DeleteFileById operation middleware
 func (siw *ServerInterfaceWrapper) DeleteFileById(w http.ResponseWriter, r *http.Request) {
 
@@ -284,6 +775,56 @@ func (siw *ServerInterfaceWrapper) GetFileById(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
+// UpdateFileById operation middleware
+func (siw *ServerInterfaceWrapper) UpdateFileById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateFileById(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFileContent operation middleware
+func (siw *ServerInterfaceWrapper) GetFileContent(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFileContent(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // ListFileDocuments operation middleware
 func (siw *ServerInterfaceWrapper) ListFileDocuments(w http.ResponseWriter, r *http.Request) {
 
@@ -309,6 +850,14 @@ func (siw *ServerInterfaceWrapper) ListFileDocuments(w http.ResponseWriter, r *h
 		return
 	}
 
+	// ------------- Optional query parameter "language" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "language", r.URL.Query(), &params.Language)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "language", Err: err})
+		return
+	}
+
 	// ------------- Optional query parameter "limit" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
@@ -328,25 +877,22 @@ func (siw *ServerInterfaceWrapper) ListFileDocuments(w http.ResponseWriter, r *h
 	handler.ServeHTTP(w, r)
 }
 
-// ListScreenings operation middleware
-func (siw *ServerInterfaceWrapper) ListScreenings(w http.ResponseWriter, r *http.Request) {
-
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ListScreenings(w, r)
-	}))
+// ReprocessFile operation middleware
+func (siw *ServerInterfaceWrapper) ReprocessFile(w http.ResponseWriter, r *http.Request) {
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
-	}
+	var err error
 
-	handler.ServeHTTP(w, r)
-}
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
 
-// CreateScreening operation middleware
-func (siw *ServerInterfaceWrapper) CreateScreening(w http.ResponseWriter, r *http.Request) {
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.CreateScreening(w, r)
+		siw.Handler.ReprocessFile(w, r, id)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -356,8 +902,8 @@ func (siw *ServerInterfaceWrapper) CreateScreening(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r)
 }
 
-// DeleteScreeningById operation middleware
-func (siw *ServerInterfaceWrapper) DeleteScreeningById(w http.ResponseWriter, r *http.Request) {
+// GetFileText operation middleware
+func (siw *ServerInterfaceWrapper) GetFileText(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
@@ -370,8 +916,19 @@ func (siw *ServerInterfaceWrapper) DeleteScreeningById(w http.ResponseWriter, r
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFileTextParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteScreeningById(w, r, id)
+		siw.Handler.GetFileText(w, r, id, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -381,22 +938,412 @@ func (siw *ServerInterfaceWrapper) DeleteScreeningById(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
-// GetScreeningById operation middleware
-func (siw *ServerInterfaceWrapper) GetScreeningById(w http.ResponseWriter, r *http.Request) {
-
-	var err error
+// ListLogicalDocuments operation middleware
+func (siw *ServerInterfaceWrapper) ListLogicalDocuments(w http.ResponseWriter, r *http.Request) {
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListLogicalDocuments(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
+	handler.ServeHTTP(w, r)
+}
+
+// CreateLogicalDocument operation middleware
+func (siw *ServerInterfaceWrapper) CreateLogicalDocument(w http.ResponseWriter, r *http.Request) {
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetScreeningById(w, r, id)
+		siw.Handler.CreateLogicalDocument(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteLogicalDocumentById operation middleware
+func (siw *ServerInterfaceWrapper) DeleteLogicalDocumentById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteLogicalDocumentById(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetLogicalDocumentById operation middleware
+func (siw *ServerInterfaceWrapper) GetLogicalDocumentById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetLogicalDocumentById(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AddLogicalDocumentVersion operation middleware
+func (siw *ServerInterfaceWrapper) AddLogicalDocumentVersion(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AddLogicalDocumentVersion(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Query operation middleware
+func (siw *ServerInterfaceWrapper) Query(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Query(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// StreamQuery operation middleware
+func (siw *ServerInterfaceWrapper) StreamQuery(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StreamQuery(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListScreenings operation middleware
+func (siw *ServerInterfaceWrapper) ListScreenings(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListScreenings(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateScreening operation middleware
+func (siw *ServerInterfaceWrapper) CreateScreening(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateScreening(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteScreeningById operation middleware
+func (siw *ServerInterfaceWrapper) DeleteScreeningById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteScreeningById(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetScreeningById operation middleware
+func (siw *ServerInterfaceWrapper) GetScreeningById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetScreeningById(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListTopicSets operation middleware
+func (siw *ServerInterfaceWrapper) ListTopicSets(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListTopicSets(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteTopicSet operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTopicSet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", r.PathValue("name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteTopicSet(w, r, name)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTopicSet operation middleware
+func (siw *ServerInterfaceWrapper) GetTopicSet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", r.PathValue("name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTopicSet(w, r, name)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// SaveTopicSet operation middleware
+func (siw *ServerInterfaceWrapper) SaveTopicSet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", r.PathValue("name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SaveTopicSet(w, r, name)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateUpload operation middleware
+func (siw *ServerInterfaceWrapper) CreateUpload(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateUpload(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteUploadById operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUploadById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteUploadById(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetUploadOffset operation middleware
+func (siw *ServerInterfaceWrapper) GetUploadOffset(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUploadOffset(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PatchUpload operation middleware
+func (siw *ServerInterfaceWrapper) PatchUpload(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchUploadParams
+
+	headers := r.Header
+
+	// ------------- Required header parameter "Upload-Offset" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Upload-Offset")]; found {
+		var UploadOffset int64
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Upload-Offset", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Upload-Offset", valueList[0], &UploadOffset, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: true})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Upload-Offset", Err: err})
+			return
+		}
+
+		params.UploadOffset = UploadOffset
+
+	} else {
+		err := fmt.Errorf("Header parameter Upload-Offset is required, but not found")
+		siw.ErrorHandlerFunc(w, r, &RequiredHeaderError{ParamName: "Upload-Offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchUpload(w, r, id, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -528,13 +1475,35 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 
 	m.HandleFunc("GET "+options.BaseURL+"/files", wrapper.ListFiles)
 	m.HandleFunc("POST "+options.BaseURL+"/files", wrapper.UploadFile)
+	m.HandleFunc("POST "+options.BaseURL+"/files/archive", wrapper.UploadArchive)
+	m.HandleFunc("POST "+options.BaseURL+"/files/import", wrapper.ImportFile)
+	m.HandleFunc("POST "+options.BaseURL+"/files/reindex", wrapper.ReindexFiles)
 	m.HandleFunc("DELETE "+options.BaseURL+"/files/{id}", wrapper.DeleteFileById)
 	m.HandleFunc("GET "+options.BaseURL+"/files/{id}", wrapper.GetFileById)
+	m.HandleFunc("PATCH "+options.BaseURL+"/files/{id}", wrapper.UpdateFileById)
+	m.HandleFunc("GET "+options.BaseURL+"/files/{id}/content", wrapper.GetFileContent)
 	m.HandleFunc("GET "+options.BaseURL+"/files/{id}/documents", wrapper.ListFileDocuments)
+	m.HandleFunc("POST "+options.BaseURL+"/files/{id}/reprocess", wrapper.ReprocessFile)
+	m.HandleFunc("GET "+options.BaseURL+"/files/{id}/text", wrapper.GetFileText)
+	m.HandleFunc("GET "+options.BaseURL+"/logical-documents", wrapper.ListLogicalDocuments)
+	m.HandleFunc("POST "+options.BaseURL+"/logical-documents", wrapper.CreateLogicalDocument)
+	m.HandleFunc("DELETE "+options.BaseURL+"/logical-documents/{id}", wrapper.DeleteLogicalDocumentById)
+	m.HandleFunc("GET "+options.BaseURL+"/logical-documents/{id}", wrapper.GetLogicalDocumentById)
+	m.HandleFunc("POST "+options.BaseURL+"/logical-documents/{id}/versions", wrapper.AddLogicalDocumentVersion)
+	m.HandleFunc("POST "+options.BaseURL+"/query", wrapper.Query)
+	m.HandleFunc("POST "+options.BaseURL+"/query/stream", wrapper.StreamQuery)
 	m.HandleFunc("GET "+options.BaseURL+"/screenings", wrapper.ListScreenings)
 	m.HandleFunc("POST "+options.BaseURL+"/screenings", wrapper.CreateScreening)
 	m.HandleFunc("DELETE "+options.BaseURL+"/screenings/{id}", wrapper.DeleteScreeningById)
 	m.HandleFunc("GET "+options.BaseURL+"/screenings/{id}", wrapper.GetScreeningById)
+	m.HandleFunc("GET "+options.BaseURL+"/topic-sets", wrapper.ListTopicSets)
+	m.HandleFunc("DELETE "+options.BaseURL+"/topic-sets/{name}", wrapper.DeleteTopicSet)
+	m.HandleFunc("GET "+options.BaseURL+"/topic-sets/{name}", wrapper.GetTopicSet)
+	m.HandleFunc("PUT "+options.BaseURL+"/topic-sets/{name}", wrapper.SaveTopicSet)
+	m.HandleFunc("POST "+options.BaseURL+"/uploads", wrapper.CreateUpload)
+	m.HandleFunc("DELETE "+options.BaseURL+"/uploads/{id}", wrapper.DeleteUploadById)
+	m.HandleFunc("HEAD "+options.BaseURL+"/uploads/{id}", wrapper.GetUploadOffset)
+	m.HandleFunc("PATCH "+options.BaseURL+"/uploads/{id}", wrapper.PatchUpload)
 
 	return m
 }
@@ -0,0 +1,213 @@
+package ragserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neurosnap/sentences"
+)
+
+type sentenceChunker struct {
+	training *sentences.Storage
+}
+
+// NewSentenceChunker returns a chunker which splits each document into sentences, table rows are
+// kept whole. If training is nil, embedded training data of the language of each document is used.
+func NewSentenceChunker(training *sentences.Storage) Chunker {
+	return &sentenceChunker{training: training}
+}
+
+func (c *sentenceChunker) Name() string {
+	return "sentence"
+}
+
+func (c *sentenceChunker) Chunk(documents []Document) ([]Document, error) {
+	var (
+		tokenizers = map[Language]*sentences.DefaultSentenceTokenizer{}
+		chunks     = make([]Document, 0, len(documents))
+	)
+	for _, aDocument := range documents {
+		// Rows such as "Scope 1: 2022: 1.2. 2023: 1.1" would be split at the numbers
+		if aDocument.LayoutType == LayoutTypeTable {
+			if strings.TrimSpace(aDocument.Content) != "" {
+				chunks = append(chunks, aDocument)
+			}
+			continue
+		}
+
+		tokenizer, ok := tokenizers[aDocument.Language]
+		if !ok {
+			training := c.training
+			if training == nil {
+				var err error
+				training, err = loadTraining(aDocument.Language)
+				if err != nil {
+					return nil, fmt.Errorf("loading training: %w", err)
+				}
+			}
+			tokenizer = sentences.NewSentenceTokenizer(training)
+			tokenizers[aDocument.Language] = tokenizer
+		}
+
+		for _, aSentence := range tokenizer.Tokenize(aDocument.Content) {
+			content := strings.TrimSpace(aSentence.Text)
+			if content == "" {
+				continue
+			}
+			aChunk := aDocument
+			aChunk.Content = content
+			chunks = append(chunks, aChunk)
+		}
+	}
+
+	return chunks, nil
+}
+
+type paragraphChunker struct{}
+
+// NewParagraphChunker returns a chunker which keeps documents as they were extracted,
+// one chunk per paragraph, layout item or table row.
+func NewParagraphChunker() Chunker {
+	return paragraphChunker{}
+}
+
+func (c paragraphChunker) Name() string {
+	return "paragraph"
+}
+
+func (c paragraphChunker) Chunk(documents []Document) ([]Document, error) {
+	chunks := make([]Document, 0, len(documents))
+	for _, aDocument := range documents {
+		if strings.TrimSpace(aDocument.Content) == "" {
+			continue
+		}
+		chunks = append(chunks, aDocument)
+	}
+	return chunks, nil
+}
+
+type windowChunker struct {
+	size    int
+	overlap int
+}
+
+// NewWindowChunker returns a chunker which slides a window of size tokens over all documents,
+// each chunk shares overlap tokens with the previous one. Tokens are whitespace separated words.
+// Page and section of a chunk are those of its first token.
+func NewWindowChunker(size, overlap int) (Chunker, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("window size must be positive")
+	}
+	if overlap < 0 || overlap >= size {
+		return nil, fmt.Errorf("window overlap must be between 0 and size")
+	}
+	return windowChunker{size: size, overlap: overlap}, nil
+}
+
+func (c windowChunker) Name() string {
+	return fmt.Sprintf("window(size=%d,overlap=%d)", c.size, c.overlap)
+}
+
+func (c windowChunker) Chunk(documents []Document) ([]Document, error) {
+	type token struct {
+		text string
+		doc  int
+	}
+
+	var tokens []token
+	for i, aDocument := range documents {
+		for _, word := range strings.Fields(aDocument.Content) {
+			tokens = append(tokens, token{text: word, doc: i})
+		}
+	}
+
+	var (
+		chunks = make([]Document, 0, len(tokens)/(c.size-c.overlap)+1)
+		step   = c.size - c.overlap
+	)
+	for start := 0; start < len(tokens); start += step {
+		end := min(start+c.size, len(tokens))
+
+		words := make([]string, 0, end-start)
+		for _, aToken := range tokens[start:end] {
+			words = append(words, aToken.text)
+		}
+
+		aChunk := documents[tokens[start].doc]
+		aChunk.Content = strings.Join(words, " ")
+		chunks = append(chunks, aChunk)
+
+		if end == len(tokens) {
+			break
+		}
+	}
+
+	return chunks, nil
+}
+
+type sectionChunker struct {
+	maxSize int
+}
+
+// NewSectionChunker returns a chunker which merges consecutive documents under the same heading
+// into a single chunk prefixed with the heading. Chunks are split at document boundaries so they
+// do not exceed maxSize tokens, unless a single document is longer. Zero maxSize means no limit.
+// Bounding box of a chunk covers its documents on the same page as the first one.
+func NewSectionChunker(maxSize int) (Chunker, error) {
+	if maxSize < 0 {
+		return nil, fmt.Errorf("max size must not be negative")
+	}
+	return sectionChunker{maxSize: maxSize}, nil
+}
+
+func (c sectionChunker) Name() string {
+	return fmt.Sprintf("section(max_size=%d)", c.maxSize)
+}
+
+func (c sectionChunker) Chunk(documents []Document) ([]Document, error) {
+	var (
+		chunks   = make([]Document, 0, len(documents))
+		current  *Document
+		box      *BoundingBox
+		contents []string
+		size     int
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		aChunk := *current
+		aChunk.Content = strings.Join(contents, "\n")
+		aChunk.BoundingBox = box
+		if aChunk.Section != "" {
+			aChunk.Content = aChunk.Section + "\n" + aChunk.Content
+		}
+		chunks = append(chunks, aChunk)
+		current, box, contents, size = nil, nil, nil, 0
+	}
+
+	for i, aDocument := range documents {
+		if strings.TrimSpace(aDocument.Content) == "" {
+			continue
+		}
+		docSize := len(strings.Fields(aDocument.Content))
+
+		if current != nil && (aDocument.Section != current.Section || c.maxSize > 0 && size+docSize > c.maxSize) {
+			flush()
+		}
+		if current == nil {
+			current = &documents[i]
+			box = aDocument.BoundingBox
+		} else if box != nil && aDocument.BoundingBox != nil && aDocument.Page == current.Page {
+			// Highlight the region of all documents of the chunk on its page
+			merged := box.Union(*aDocument.BoundingBox)
+			box = &merged
+		}
+		contents = append(contents, aDocument.Content)
+		size += docSize
+	}
+	flush()
+
+	return chunks, nil
+}
@@ -0,0 +1,162 @@
+package ragserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testChunkerDocuments = []Document{
+	{Content: "Our total scope 1 emissions were 100 tCO2e. This is a decrease.", Page: 1, Section: "Emissions"},
+	{Content: "Scope 2 emissions were 50 tCO2e.", Page: 2, Section: "Emissions"},
+	{Content: " ", Page: 2, Section: "Emissions"},
+	{Content: "We aim to reach net zero by 2050.", Page: 3, Section: "Targets"},
+}
+
+func TestChunkers(t *testing.T) {
+	t.Parallel()
+
+	window, err := NewWindowChunker(6, 2)
+	require.NoError(t, err)
+
+	section, err := NewSectionChunker(0)
+	require.NoError(t, err)
+
+	smallSection, err := NewSectionChunker(12)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		chunker      Chunker
+		expectedName string
+		expected     []Document
+	}{
+		{
+			"sentence",
+			NewSentenceChunker(nil),
+			"sentence",
+			[]Document{
+				{Content: "Our total scope 1 emissions were 100 tCO2e.", Page: 1, Section: "Emissions"},
+				{Content: "This is a decrease.", Page: 1, Section: "Emissions"},
+				{Content: "Scope 2 emissions were 50 tCO2e.", Page: 2, Section: "Emissions"},
+				{Content: "We aim to reach net zero by 2050.", Page: 3, Section: "Targets"},
+			},
+		},
+		{
+			"paragraph",
+			NewParagraphChunker(),
+			"paragraph",
+			[]Document{
+				{Content: "Our total scope 1 emissions were 100 tCO2e. This is a decrease.", Page: 1, Section: "Emissions"},
+				{Content: "Scope 2 emissions were 50 tCO2e.", Page: 2, Section: "Emissions"},
+				{Content: "We aim to reach net zero by 2050.", Page: 3, Section: "Targets"},
+			},
+		},
+		{
+			"window",
+			window,
+			"window(size=6,overlap=2)",
+			[]Document{
+				{Content: "Our total scope 1 emissions were", Page: 1, Section: "Emissions"},
+				{Content: "emissions were 100 tCO2e. This is", Page: 1, Section: "Emissions"},
+				{Content: "This is a decrease. Scope 2", Page: 1, Section: "Emissions"},
+				{Content: "Scope 2 emissions were 50 tCO2e.", Page: 2, Section: "Emissions"},
+				{Content: "50 tCO2e. We aim to reach", Page: 2, Section: "Emissions"},
+				{Content: "to reach net zero by 2050.", Page: 3, Section: "Targets"},
+			},
+		},
+		{
+			"section",
+			section,
+			"section(max_size=0)",
+			[]Document{
+				{Content: "Emissions\nOur total scope 1 emissions were 100 tCO2e. This is a decrease.\nScope 2 emissions were 50 tCO2e.", Page: 1, Section: "Emissions"},
+				{Content: "Targets\nWe aim to reach net zero by 2050.", Page: 3, Section: "Targets"},
+			},
+		},
+		{
+			"section with max size",
+			smallSection,
+			"section(max_size=12)",
+			[]Document{
+				{Content: "Emissions\nOur total scope 1 emissions were 100 tCO2e. This is a decrease.", Page: 1, Section: "Emissions"},
+				{Content: "Emissions\nScope 2 emissions were 50 tCO2e.", Page: 2, Section: "Emissions"},
+				{Content: "Targets\nWe aim to reach net zero by 2050.", Page: 3, Section: "Targets"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedName, tt.chunker.Name())
+
+			chunks, err := tt.chunker.Chunk(testChunkerDocuments)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, chunks)
+		})
+	}
+}
+
+func TestSentenceChunker_TableRows(t *testing.T) {
+	t.Parallel()
+
+	documents := []Document{
+		{Content: "Emissions decreased. Targets were met.", Page: 1},
+		{Content: "Scope 1: 2022: 1.2. 2023: 1.1", Page: 1, LayoutType: LayoutTypeTable},
+		{Content: " ", Page: 1, LayoutType: LayoutTypeTable},
+	}
+
+	chunks, err := NewSentenceChunker(nil).Chunk(documents)
+	require.NoError(t, err)
+
+	expected := []Document{
+		{Content: "Emissions decreased.", Page: 1},
+		{Content: "Targets were met.", Page: 1},
+		{Content: "Scope 1: 2022: 1.2. 2023: 1.1", Page: 1, LayoutType: LayoutTypeTable},
+	}
+	assert.Equal(t, expected, chunks)
+}
+
+func TestNewWindowChunker_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewWindowChunker(0, 0)
+	assert.Error(t, err)
+
+	_, err = NewWindowChunker(10, 10)
+	assert.Error(t, err)
+
+	_, err = NewWindowChunker(10, -1)
+	assert.Error(t, err)
+}
+
+func TestNewSectionChunker_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSectionChunker(-1)
+	assert.Error(t, err)
+}
+
+func TestSectionChunker_BoundingBox(t *testing.T) {
+	t.Parallel()
+
+	chunker, err := NewSectionChunker(0)
+	require.NoError(t, err)
+
+	documents := []Document{
+		{Content: "Scope 1", Page: 1, Section: "Emissions", BoundingBox: &BoundingBox{Left: 50, Top: 100, Width: 200, Height: 20, PageWidth: 595, PageHeight: 842}},
+		{Content: "Scope 2", Page: 1, Section: "Emissions", BoundingBox: &BoundingBox{Left: 40, Top: 130, Width: 300, Height: 30, PageWidth: 595, PageHeight: 842}},
+		{Content: "Scope 3", Page: 2, Section: "Emissions", BoundingBox: &BoundingBox{Left: 0, Top: 0, Width: 100, Height: 100, PageWidth: 595, PageHeight: 842}},
+	}
+
+	chunks, err := chunker.Chunk(documents)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+
+	// Documents on other pages than the first one are not covered
+	expected := &BoundingBox{Left: 40, Top: 100, Width: 300, Height: 60, PageWidth: 595, PageHeight: 842}
+	assert.Equal(t, expected, chunks[0].BoundingBox)
+	// Bounding boxes of the chunked documents are not modified
+	assert.Equal(t, 50.0, documents[0].BoundingBox.Left)
+}
@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."file" drop column if exists "chunker";
+
+commit;
\ No newline at end of file
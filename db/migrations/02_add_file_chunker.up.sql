@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."file" add column "chunker" text not null default 'sentence';
+
+commit;
\ No newline at end of file
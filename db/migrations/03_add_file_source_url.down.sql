@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."file" drop column if exists "source_url";
+
+commit;
\ No newline at end of file
@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."file" add column "source_url" text;
+
+commit;
\ No newline at end of file
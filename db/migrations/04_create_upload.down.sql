@@ -0,0 +1,5 @@
+begin;
+
+drop table if exists "ragserver"."upload";
+
+commit;
\ No newline at end of file
@@ -0,0 +1,16 @@
+begin;
+
+create table "ragserver"."upload" (
+  "id" uuid primary key,
+  "author" uuid not null references "ragserver"."principal"("id"),
+  "file_name" text not null,
+  "upload_size" bigint not null,
+  "upload_offset" bigint not null,
+  "temp_file" text not null,
+  "hash_state" bytea not null,
+  "file" uuid references "ragserver"."file"("id") on delete cascade,
+  "created" timestamp not null default now(),
+  "updated" timestamp not null default now()
+);
+
+commit;
\ No newline at end of file
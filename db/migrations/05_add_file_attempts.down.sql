@@ -0,0 +1,16 @@
+begin;
+
+-- Keep only the last attempt of each status
+delete from "ragserver"."file_status_evt" e where exists (
+  select 1 from "ragserver"."file_status_evt" e2
+  where e2."file" = e."file" and e2."status" = e."status" and e2."attempt" > e."attempt"
+);
+alter table "ragserver"."file_status_evt" drop constraint "file_status_evt_pkey";
+alter table "ragserver"."file_status_evt" drop column if exists "attempt";
+alter table "ragserver"."file_status_evt" add primary key ("file", "status");
+
+drop index if exists "ragserver"."file_next_attempt_idx";
+alter table "ragserver"."file" drop column if exists "next_attempt";
+alter table "ragserver"."file" drop column if exists "attempts";
+
+commit;
\ No newline at end of file
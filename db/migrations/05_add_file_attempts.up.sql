@@ -0,0 +1,15 @@
+begin;
+
+alter table "ragserver"."file" add column "attempts" integer not null default 0;
+alter table "ragserver"."file" add column "next_attempt" timestamp;
+update "ragserver"."file" set "attempts" = 1 where "status" <> 1;
+
+create index "file_next_attempt_idx" on "ragserver"."file" using btree("next_attempt");
+
+-- Each processing attempt records its own status events
+alter table "ragserver"."file_status_evt" add column "attempt" integer not null default 0;
+update "ragserver"."file_status_evt" set "attempt" = 1 where "status" <> 1;
+alter table "ragserver"."file_status_evt" drop constraint "file_status_evt_pkey";
+alter table "ragserver"."file_status_evt" add primary key ("file", "status", "attempt");
+
+commit;
\ No newline at end of file
@@ -0,0 +1,18 @@
+begin;
+
+-- Keep only the last stage of each status
+delete from "ragserver"."file_status_evt" e where exists (
+  select 1 from "ragserver"."file_status_evt" e2
+  where e2."file" = e."file" and e2."status" = e."status" and e2."attempt" = e."attempt" 
+    and (e2."created" > e."created" or (e2."created" = e."created" and e2."stage" > e."stage"))
+);
+alter table "ragserver"."file_status_evt" drop constraint "file_status_evt_pkey";
+alter table "ragserver"."file_status_evt" drop column if exists "stage";
+alter table "ragserver"."file_status_evt" add primary key ("file", "status", "attempt");
+
+alter table "ragserver"."file" drop column if exists "documents_embedded";
+alter table "ragserver"."file" drop column if exists "documents";
+alter table "ragserver"."file" drop column if exists "pages";
+alter table "ragserver"."file" drop column if exists "stage";
+
+commit;
\ No newline at end of file
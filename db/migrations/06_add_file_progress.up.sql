@@ -0,0 +1,13 @@
+begin;
+
+alter table "ragserver"."file" add column "stage" text not null default '';
+alter table "ragserver"."file" add column "pages" integer not null default 0;
+alter table "ragserver"."file" add column "documents" integer not null default 0;
+alter table "ragserver"."file" add column "documents_embedded" integer not null default 0;
+
+-- Each stage of processing records its own status event
+alter table "ragserver"."file_status_evt" add column "stage" text not null default '';
+alter table "ragserver"."file_status_evt" drop constraint "file_status_evt_pkey";
+alter table "ragserver"."file_status_evt" add primary key ("file", "status", "attempt", "stage");
+
+commit;
\ No newline at end of file
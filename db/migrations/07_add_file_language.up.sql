@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."file" add column "language" text not null default '';
+
+commit;
\ No newline at end of file
@@ -0,0 +1,8 @@
+begin;
+
+alter table "ragserver"."upload" drop column "topics";
+alter table "ragserver"."file" drop column "topics";
+
+drop table "ragserver"."topic_set";
+
+commit;
\ No newline at end of file
@@ -0,0 +1,14 @@
+begin;
+
+create table "ragserver"."topic_set" (
+  "name" text primary key,
+  "topics" jsonb not null,
+  "created" timestamp not null default now(),
+  "updated" timestamp not null default now()
+);
+
+-- Topics selected when a file was uploaded, null for topics configured on the server
+alter table "ragserver"."file" add column "topics" jsonb;
+alter table "ragserver"."upload" add column "topics" jsonb;
+
+commit;
\ No newline at end of file
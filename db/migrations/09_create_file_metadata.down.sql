@@ -0,0 +1,7 @@
+begin;
+
+alter table "ragserver"."upload" drop column "metadata";
+
+drop table "ragserver"."file_metadata";
+
+commit;
\ No newline at end of file
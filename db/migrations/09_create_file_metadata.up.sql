@@ -0,0 +1,15 @@
+begin;
+
+create table "ragserver"."file_metadata" (
+  "file" uuid not null references "ragserver"."file"("id") on delete cascade,
+  "key" text not null,
+  "value" text not null,
+  primary key ("file", "key")
+);
+
+create index "file_metadata_key_value_idx" on "ragserver"."file_metadata" ("key", "value");
+
+-- Metadata recorded on the file once the upload completes
+alter table "ragserver"."upload" add column "metadata" jsonb;
+
+commit;
\ No newline at end of file
@@ -0,0 +1,6 @@
+begin;
+
+drop table "ragserver"."logical_document_version";
+drop table "ragserver"."logical_document";
+
+commit;
\ No newline at end of file
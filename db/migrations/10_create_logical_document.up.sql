@@ -0,0 +1,22 @@
+begin;
+
+-- A logical document groups files which are versions of the same document, such as a report
+-- republished with corrections
+create table "ragserver"."logical_document" (
+  "id" uuid primary key,
+  "author" uuid not null references "ragserver"."principal"("id"),
+  "name" text not null,
+  "created" timestamp not null default now(),
+  "updated" timestamp not null default now()
+);
+
+-- A file is a version of at most one logical document, versions are numbered from 1
+create table "ragserver"."logical_document_version" (
+  "document" uuid not null references "ragserver"."logical_document"("id") on delete cascade,
+  "file" uuid not null unique references "ragserver"."file"("id") on delete cascade,
+  "version" integer not null,
+  "created" timestamp not null default now(),
+  primary key ("document", "version")
+);
+
+commit;
\ No newline at end of file
@@ -0,0 +1,5 @@
+begin;
+
+drop table "ragserver"."embedding_cache";
+
+commit;
\ No newline at end of file
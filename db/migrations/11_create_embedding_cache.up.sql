@@ -0,0 +1,13 @@
+begin;
+
+-- Vectors of embedded contents by the embedder, its model and hex encoded SHA-256 hash of the content
+create table "ragserver"."embedding_cache" (
+  "embedder" text not null,
+  "model" text not null,
+  "hash" text not null,
+  "vector" bytea not null,
+  "created" timestamp not null default now(),
+  primary key ("embedder", "model", "hash")
+);
+
+commit;
\ No newline at end of file
@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."file" add column "reprocessing" boolean not null default false;
+
+commit;
\ No newline at end of file
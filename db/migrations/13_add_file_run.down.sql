@@ -0,0 +1,14 @@
+begin;
+
+-- Keep only events of the last run
+delete from "ragserver"."file_status_evt" e where exists (
+  select 1 from "ragserver"."file_status_evt" e2
+  where e2."file" = e."file" and e2."run" > e."run"
+);
+alter table "ragserver"."file_status_evt" drop constraint "file_status_evt_pkey";
+alter table "ragserver"."file_status_evt" drop column if exists "run";
+alter table "ragserver"."file_status_evt" add primary key ("file", "status", "attempt", "stage");
+
+alter table "ragserver"."file" drop column if exists "run";
+
+commit;
\ No newline at end of file
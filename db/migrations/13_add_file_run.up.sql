@@ -0,0 +1,10 @@
+begin;
+
+alter table "ragserver"."file" add column "run" integer not null default 0;
+
+-- Reprocessed files start a new run, status events of earlier runs are kept
+alter table "ragserver"."file_status_evt" add column "run" integer not null default 0;
+alter table "ragserver"."file_status_evt" drop constraint "file_status_evt_pkey";
+alter table "ragserver"."file_status_evt" add primary key ("file", "run", "status", "attempt", "stage");
+
+commit;
\ No newline at end of file
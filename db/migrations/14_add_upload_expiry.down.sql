@@ -0,0 +1,9 @@
+begin;
+
+drop index if exists "ragserver"."upload_expires_idx";
+
+alter table "ragserver"."upload" drop column "locked_until";
+alter table "ragserver"."upload" drop column "lock_id";
+alter table "ragserver"."upload" drop column "expires";
+
+commit;
\ No newline at end of file
@@ -0,0 +1,10 @@
+begin;
+
+alter table "ragserver"."upload" add column "expires" timestamp not null default now() + interval '1 day';
+alter table "ragserver"."upload" alter column "expires" drop default;
+alter table "ragserver"."upload" add column "lock_id" uuid;
+alter table "ragserver"."upload" add column "locked_until" timestamp;
+
+create index "upload_expires_idx" on "ragserver"."upload" using btree("expires");
+
+commit;
\ No newline at end of file
@@ -0,0 +1,5 @@
+begin;
+
+alter table "ragserver"."logical_document" drop column "last_version";
+
+commit;
\ No newline at end of file
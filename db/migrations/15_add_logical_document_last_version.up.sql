@@ -0,0 +1,11 @@
+begin;
+
+alter table "ragserver"."logical_document" add column "last_version" integer not null default 0;
+
+update "ragserver"."logical_document" d set "last_version" = coalesce((
+	select max(v."version")
+	from "ragserver"."logical_document_version" v
+	where v."document" = d."id"
+), 0);
+
+commit;
\ No newline at end of file
@@ -11,46 +11,103 @@ import (
 
 type Vector []float32
 
+// LayoutTypeTable is the layout type of documents extracted from table rows, each row is a document
+// of its own and chunkers keep it whole.
+const LayoutTypeTable = "Table"
+
 type Document struct {
-	FileID   FileID   `json:"file_id"`
-	Content  string   `json:"content"`
-	Page     int      `json:"page"`
-	Distance *float64 `json:"distance,omitempty"`
+	FileID      FileID       `json:"file_id"`
+	Content     string       `json:"content"`
+	Page        int          `json:"page"`
+	Section     string       `json:"section,omitempty"`      // heading the content belongs to, if known
+	Language    Language     `json:"language,omitempty"`     // detected language, empty if unknown
+	LayoutType  string       `json:"layout_type,omitempty"`  // type of the layout item, e.g. Text or Table
+	BoundingBox *BoundingBox `json:"bounding_box,omitempty"` // region of the page, only known for layout analysis
+	Metadata    Metadata     `json:"metadata,omitempty"`     // indexed metadata of the file, see MetadataField
+	Distance    *float64     `json:"distance,omitempty"`
+}
+
+// BoundingBox is the region of a page content was extracted from. Coordinates are in the same
+// units as the page size, measured from the top left corner of the page.
+type BoundingBox struct {
+	Left       float64 `json:"left"`
+	Top        float64 `json:"top"`
+	Width      float64 `json:"width"`
+	Height     float64 `json:"height"`
+	PageWidth  float64 `json:"page_width"`
+	PageHeight float64 `json:"page_height"`
+}
+
+// Union returns the smallest bounding box containing both boxes, they must be on the same page.
+func (b BoundingBox) Union(other BoundingBox) BoundingBox {
+	var (
+		left   = min(b.Left, other.Left)
+		top    = min(b.Top, other.Top)
+		right  = max(b.Left+b.Width, other.Left+other.Width)
+		bottom = max(b.Top+b.Height, other.Top+other.Height)
+	)
+	return BoundingBox{
+		Left:       left,
+		Top:        top,
+		Width:      right - left,
+		Height:     bottom - top,
+		PageWidth:  b.PageWidth,
+		PageHeight: b.PageHeight,
+	}
 }
 
 type DocumentFilter struct {
 	SimilarTo string
 	Vector    Vector
 	FileIDs   []FileID
-}
-
-type Topic struct {
-	Name     string
-	Keywords []string
+	Language  Language       // only documents detected to be in this language, all languages if empty
+	Metadata  MetadataFilter // only documents of files with matching indexed metadata
 }
 
 func (d Document) Sanitize() Document {
 	d.Content = strings.TrimSpace(d.Content)
 	d.Content = strings.Join(strings.Fields(d.Content), " ")
+	d.Section = strings.Join(strings.Fields(d.Section), " ")
 	return d
 }
 
-type RelevantTopics []Topic
+// relevantDocuments filters out documents which are not relevant to any of the topics, either
+// those recorded on the file when it was uploaded or the ones configured on the server.
+// Section headings are taken into account as they often say what the content is about.
+func (rs *ragServer) relevantDocuments(ctx context.Context, aFile *File, documents []Document) ([]Document, error) {
+	relevantTopics := rs.relevantTopics
+	if aFile.Topics != nil {
+		relevantTopics = aFile.Topics.Topics
+	}
+	if len(relevantTopics) == 0 {
+		return documents, nil
+	}
 
-func (rt RelevantTopics) IsRelevant(content string) (Topic, bool) {
-	for len(rt) == 0 {
-		return Topic{}, false
+	classified, err := rs.relevanceFilter.Classify(ctx, relevantTopics, documents)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering relevant documents: %w", err)
 	}
 
-	for _, topic := range rt {
-		for _, keyword := range topic.Keywords {
-			if strings.Contains(strings.ToLower(content), strings.ToLower(keyword)) {
-				return topic, true
-			}
+	var (
+		relevant   = make([]Document, 0, len(documents))
+		topicCount = map[string]int{}
+	)
+	for i, aDocument := range documents {
+		aTopic := classified[i]
+		if aTopic == nil {
+			continue
+		}
+		if aTopic.Name != "" {
+			topicCount[aTopic.Name] += 1
 		}
+		relevant = append(relevant, aDocument)
+	}
+
+	for name, count := range topicCount {
+		rs.logger.Sugar().Infof("%s relevant documents: %d", name, count)
 	}
 
-	return Topic{}, false
+	return relevant, nil
 }
 
 func (rs *ragServer) ListFileDocuments(ctx context.Context, principal authz.Principal, id FileID, filter DocumentFilter, limit int) ([]Document, error) {
@@ -74,8 +131,9 @@ func (rs *ragServer) ListFileDocuments(ctx context.Context, principal authz.Prin
 			// Search redis/weaviate to find the most relevant (closest in vector space)
 			// documents to the query.
 			documents, err = rs.retriever.SearchDocuments(ctx, DocumentFilter{
-				Vector:  vector,
-				FileIDs: []FileID{id},
+				Vector:   vector,
+				FileIDs:  []FileID{id},
+				Language: filter.Language,
 			}, limit)
 			return err
 		}
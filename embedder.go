@@ -0,0 +1,288 @@
+package ragserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultEmbedBatchSize    = 100
+	defaultEmbedConcurrency  = 1
+	defaultEmbedMaxAttempts  = 3
+	defaultEmbedRetryBackoff = 1 * time.Second
+)
+
+// BatchEmbedder is an Embedder decorator for embedding models with limits on requests. Documents are
+// split into batches embedded concurrently, requests are rate limited with a token bucket and requests
+// failing with a retryable error are sent again with exponential backoff.
+type BatchEmbedder struct {
+	embedder     Embedder
+	batchSize    int
+	concurrency  int
+	limiter      *tokenBucket // nil if requests are not rate limited
+	maxAttempts  int
+	retryBackoff time.Duration
+	logger       *zap.Logger
+}
+
+type BatchEmbedderOption func(*BatchEmbedder)
+
+// WithEmbedBatchSize limits the number of documents embedded by a single request, 100 by default.
+func WithEmbedBatchSize(size int) BatchEmbedderOption {
+	return func(e *BatchEmbedder) {
+		if size > 0 {
+			e.batchSize = size
+		}
+	}
+}
+
+// WithEmbedConcurrency limits the number of batches embedded at the same time, 1 by default.
+func WithEmbedConcurrency(n int) BatchEmbedderOption {
+	return func(e *BatchEmbedder) {
+		if n > 0 {
+			e.concurrency = n
+		}
+	}
+}
+
+// WithEmbedRateLimit limits requests to rate per second allowing bursts of up to burst requests,
+// requests are not rate limited by default.
+func WithEmbedRateLimit(rate float64, burst int) BatchEmbedderOption {
+	return func(e *BatchEmbedder) {
+		if rate > 0 {
+			e.limiter = newTokenBucket(rate, burst)
+		}
+	}
+}
+
+// WithEmbedRetries sets the max number of attempts of a request failing with a retryable error,
+// see IsRetryable, and the backoff before the first retry which doubles with each attempt.
+// 3 attempts with 1s backoff by default, use 1 to disable retries.
+func WithEmbedRetries(maxAttempts int, backoff time.Duration) BatchEmbedderOption {
+	return func(e *BatchEmbedder) {
+		if maxAttempts > 0 {
+			e.maxAttempts = maxAttempts
+		}
+		if backoff > 0 {
+			e.retryBackoff = backoff
+		}
+	}
+}
+
+func WithEmbedLogger(logger *zap.Logger) BatchEmbedderOption {
+	return func(e *BatchEmbedder) {
+		e.logger = logger
+	}
+}
+
+// NewBatchEmbedder decorates the embedder, it keeps the name of the embedder
+// as vectors are generated by the same model.
+func NewBatchEmbedder(embedder Embedder, opts ...BatchEmbedderOption) *BatchEmbedder {
+	e := &BatchEmbedder{
+		embedder:     embedder,
+		batchSize:    defaultEmbedBatchSize,
+		concurrency:  defaultEmbedConcurrency,
+		maxAttempts:  defaultEmbedMaxAttempts,
+		retryBackoff: defaultEmbedRetryBackoff,
+		logger:       zap.NewNop(),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+func (e *BatchEmbedder) Name() string {
+	return e.embedder.Name()
+}
+
+// EmbedDocuments embeds documents in batches, returned vectors are in the same order as documents.
+// Remaining batches are canceled as soon as one of them fails. Progress is reported as each batch
+// is embedded, see withEmbedProgress.
+func (e *BatchEmbedder) EmbedDocuments(ctx context.Context, documents []Document) ([]Vector, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		vectors    = make([]Vector, len(documents))
+		slots      = make(chan struct{}, e.concurrency)
+		wg         sync.WaitGroup
+		errOnce    sync.Once
+		batchErr   error
+		progressMu sync.Mutex
+	)
+	for start := 0; start < len(documents) && ctx.Err() == nil; start += e.batchSize {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+
+		end := min(start+e.batchSize, len(documents))
+		wg.Go(func() {
+			defer func() { <-slots }()
+
+			var batchVectors []Vector
+			err := e.do(ctx, func() error {
+				var err error
+				batchVectors, err = e.embedder.EmbedDocuments(ctx, documents[start:end])
+				return err
+			})
+			if err == nil && len(batchVectors) != end-start {
+				err = fmt.Errorf("embedded batch size mismatch: %d vectors for %d documents", len(batchVectors), end-start)
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					batchErr = err
+					cancel()
+				})
+				return
+			}
+
+			copy(vectors[start:end], batchVectors)
+
+			progressMu.Lock()
+			reportEmbedProgress(ctx, end-start)
+			progressMu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	if batchErr != nil {
+		return nil, batchErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+func (e *BatchEmbedder) batchesDocuments() bool {
+	return true
+}
+
+func (e *BatchEmbedder) EmbedContent(ctx context.Context, content string) (Vector, error) {
+	var vector Vector
+	if err := e.do(ctx, func() error {
+		var err error
+		vector, err = e.embedder.EmbedContent(ctx, content)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return vector, nil
+}
+
+// do sends a request once the rate limiter allows it, sending it again after a backoff
+// if it fails with a retryable error until it runs out of attempts.
+func (e *BatchEmbedder) do(ctx context.Context, request func() error) error {
+	for attempt := 1; ; attempt++ {
+		if e.limiter != nil {
+			if err := e.limiter.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		err := request()
+		if err == nil || attempt >= e.maxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		backoff := retryBackoff(e.retryBackoff, attempt)
+		e.logger.Sugar().With("attempt", attempt, "backoff", backoff, "error", err).Warn("retrying embedding request")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// tokenBucket holds up to burst tokens refilled at rate tokens per second. Each request takes a token,
+// when there are none left requests wait in turn for tokens to be refilled.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := &tokenBucket{
+		rate:  rate,
+		burst: float64(max(burst, 1)),
+		now:   time.Now,
+	}
+	b.tokens = b.burst
+	b.last = b.now()
+	return b
+}
+
+// reserve takes a token and returns how long to wait until it is refilled, tokens are taken
+// ahead of time so concurrent requests are spread out at the rate.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait blocks until a token is available or the context is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type embedProgressKey struct{}
+
+// withEmbedProgress returns a context embedders which split documents into batches report
+// progress of EmbedDocuments to, progress is called with the number of documents embedded
+// by each batch, one batch at a time.
+func withEmbedProgress(ctx context.Context, progress func(embedded int)) context.Context {
+	return context.WithValue(ctx, embedProgressKey{}, progress)
+}
+
+func reportEmbedProgress(ctx context.Context, embedded int) {
+	if progress, ok := ctx.Value(embedProgressKey{}).(func(int)); ok && embedded > 0 {
+		progress(embedded)
+	}
+}
+
+// batchesDocuments returns true if the embedder splits documents into batches itself and reports
+// progress, so all documents can be passed to EmbedDocuments at once.
+func batchesDocuments(embedder Embedder) bool {
+	batching, ok := embedder.(interface{ batchesDocuments() bool })
+	return ok && batching.batchesDocuments()
+}
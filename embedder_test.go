@@ -0,0 +1,208 @@
+package ragserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEmbedder embeds content as its number, failing requests with errors from fails first
+type countingEmbedder struct {
+	mu            sync.Mutex
+	fails         []error
+	batchSizes    []int
+	running       int
+	maxConcurrent int
+}
+
+func (e *countingEmbedder) Name() string {
+	return "counting"
+}
+
+func (e *countingEmbedder) EmbedDocuments(ctx context.Context, documents []Document) ([]Vector, error) {
+	if err := e.start(len(documents)); err != nil {
+		return nil, err
+	}
+	defer e.finish()
+
+	// Give other batches a chance to run at the same time
+	time.Sleep(5 * time.Millisecond)
+
+	vectors := make([]Vector, 0, len(documents))
+	for _, aDocument := range documents {
+		vector, err := e.embed(aDocument.Content)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+func (e *countingEmbedder) EmbedContent(ctx context.Context, content string) (Vector, error) {
+	if err := e.start(1); err != nil {
+		return nil, err
+	}
+	defer e.finish()
+
+	return e.embed(content)
+}
+
+func (e *countingEmbedder) start(batchSize int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.batchSizes = append(e.batchSizes, batchSize)
+	if len(e.fails) > 0 {
+		err := e.fails[0]
+		e.fails = e.fails[1:]
+		return err
+	}
+	e.running++
+	e.maxConcurrent = max(e.maxConcurrent, e.running)
+	return nil
+}
+
+func (e *countingEmbedder) finish() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.running--
+}
+
+func (e *countingEmbedder) embed(content string) (Vector, error) {
+	n, err := strconv.Atoi(content)
+	if err != nil {
+		return nil, err
+	}
+	return Vector{float32(n)}, nil
+}
+
+func TestBatchEmbedder_EmbedDocuments(t *testing.T) {
+	t.Parallel()
+
+	documents := make([]Document, 0, 7)
+	expected := make([]Vector, 0, 7)
+	for i := range 7 {
+		documents = append(documents, Document{Content: strconv.Itoa(i)})
+		expected = append(expected, Vector{float32(i)})
+	}
+
+	t.Run("batches are embedded concurrently in order", func(t *testing.T) {
+		embedder := &countingEmbedder{}
+		batchEmbedder := NewBatchEmbedder(embedder, WithEmbedBatchSize(2), WithEmbedConcurrency(2))
+
+		vectors, err := batchEmbedder.EmbedDocuments(context.Background(), documents)
+		require.NoError(t, err)
+		assert.Equal(t, expected, vectors)
+		assert.ElementsMatch(t, []int{2, 2, 2, 1}, embedder.batchSizes)
+		assert.Equal(t, 2, embedder.maxConcurrent)
+		assert.Equal(t, "counting", batchEmbedder.Name())
+	})
+
+	t.Run("progress is reported as batches are embedded", func(t *testing.T) {
+		var (
+			embedder      = &countingEmbedder{}
+			batchEmbedder = NewBatchEmbedder(embedder, WithEmbedBatchSize(3), WithEmbedConcurrency(2))
+			reported      []int
+		)
+		ctx := withEmbedProgress(context.Background(), func(embedded int) {
+			reported = append(reported, embedded)
+		})
+
+		vectors, err := batchEmbedder.EmbedDocuments(ctx, documents)
+		require.NoError(t, err)
+		assert.Equal(t, expected, vectors)
+		assert.ElementsMatch(t, []int{3, 3, 1}, reported)
+		assert.True(t, batchesDocuments(batchEmbedder))
+		assert.False(t, batchesDocuments(embedder))
+	})
+
+	t.Run("retryable errors are retried", func(t *testing.T) {
+		embedder := &countingEmbedder{fails: []error{
+			Retryable(errors.New("too many requests")),
+			Retryable(errors.New("too many requests")),
+		}}
+		batchEmbedder := NewBatchEmbedder(embedder, WithEmbedBatchSize(10), WithEmbedRetries(3, time.Millisecond))
+
+		vectors, err := batchEmbedder.EmbedDocuments(context.Background(), documents)
+		require.NoError(t, err)
+		assert.Equal(t, expected, vectors)
+		assert.Len(t, embedder.batchSizes, 3)
+	})
+
+	t.Run("retries run out", func(t *testing.T) {
+		embedder := &countingEmbedder{fails: []error{
+			Retryable(errors.New("too many requests")),
+			Retryable(errors.New("too many requests")),
+		}}
+		batchEmbedder := NewBatchEmbedder(embedder, WithEmbedBatchSize(10), WithEmbedRetries(2, time.Millisecond))
+
+		_, err := batchEmbedder.EmbedDocuments(context.Background(), documents)
+		require.Error(t, err)
+		assert.True(t, IsRetryable(err))
+		assert.Len(t, embedder.batchSizes, 2)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		embedder := &countingEmbedder{fails: []error{errors.New("invalid request")}}
+		batchEmbedder := NewBatchEmbedder(embedder, WithEmbedBatchSize(10), WithEmbedRetries(3, time.Millisecond))
+
+		_, err := batchEmbedder.EmbedDocuments(context.Background(), documents)
+		require.EqualError(t, err, "invalid request")
+		assert.Len(t, embedder.batchSizes, 1)
+	})
+
+	t.Run("no documents", func(t *testing.T) {
+		embedder := &countingEmbedder{}
+		vectors, err := NewBatchEmbedder(embedder).EmbedDocuments(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, vectors)
+		assert.Empty(t, embedder.batchSizes)
+	})
+}
+
+func TestBatchEmbedder_EmbedContent(t *testing.T) {
+	t.Parallel()
+
+	embedder := &countingEmbedder{fails: []error{Retryable(errors.New("service unavailable"))}}
+	batchEmbedder := NewBatchEmbedder(embedder, WithEmbedRetries(3, time.Millisecond), WithEmbedRateLimit(1000, 1))
+
+	vector, err := batchEmbedder.EmbedContent(context.Background(), "42")
+	require.NoError(t, err)
+	assert.Equal(t, Vector{42}, vector)
+	assert.Len(t, embedder.batchSizes, 2)
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now    = time.Now()
+		bucket = newTokenBucket(10, 2)
+	)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	// Burst is available straight away, further requests wait in turn
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+	assert.Equal(t, 200*time.Millisecond, bucket.reserve())
+
+	// Tokens are refilled up to the burst
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, bucket.wait(ctx), context.Canceled)
+}
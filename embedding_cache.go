@@ -0,0 +1,173 @@
+package ragserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// CachingEmbedder is an Embedder decorator which caches vectors of embedded contents, so identical
+// contents such as boilerplate disclaimers or documents of files uploaded or processed again are not
+// embedded again. Vectors are cached by the name of the embedder, its model and hash of the content.
+type CachingEmbedder struct {
+	embedder Embedder
+	model    string
+	cache    EmbeddingCache
+	hits     atomic.Int64
+	misses   atomic.Int64
+	logger   *zap.Logger
+}
+
+// EmbeddingCacheStats counts contents served from the cache and contents which had to be embedded.
+type EmbeddingCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+type CachingEmbedderOption func(*CachingEmbedder)
+
+func WithCacheLogger(logger *zap.Logger) CachingEmbedderOption {
+	return func(e *CachingEmbedder) {
+		e.logger = logger
+	}
+}
+
+// NewCachingEmbedder decorates the embedder, the model is part of the cache key as the embedder name
+// stays the same when it is configured with a different model.
+func NewCachingEmbedder(embedder Embedder, model string, cache EmbeddingCache, opts ...CachingEmbedderOption) *CachingEmbedder {
+	e := &CachingEmbedder{
+		embedder: embedder,
+		model:    model,
+		cache:    cache,
+		logger:   zap.NewNop(),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+func (e *CachingEmbedder) Name() string {
+	return e.embedder.Name()
+}
+
+// Stats returns hits and misses since the embedder was created.
+func (e *CachingEmbedder) Stats() EmbeddingCacheStats {
+	return EmbeddingCacheStats{
+		Hits:   e.hits.Load(),
+		Misses: e.misses.Load(),
+	}
+}
+
+// EmbedDocuments embeds only documents with contents which are not cached, each distinct content once.
+// Vectors are still generated if the cache is not available, as it is only an optimization.
+func (e *CachingEmbedder) EmbedDocuments(ctx context.Context, documents []Document) ([]Vector, error) {
+	hashes := make([]string, 0, len(documents))
+	for _, aDocument := range documents {
+		hashes = append(hashes, contentHash(aDocument.Content))
+	}
+
+	cached := e.findEmbeddings(ctx, hashes...)
+
+	var (
+		vectors = make([]Vector, len(documents))
+		missing = make([]Document, 0, len(documents))
+		indexes = map[string][]int{}
+	)
+	for i, hash := range hashes {
+		if vector, ok := cached[hash]; ok {
+			vectors[i] = vector
+			continue
+		}
+		if _, ok := indexes[hash]; !ok {
+			missing = append(missing, documents[i])
+		}
+		indexes[hash] = append(indexes[hash], i)
+	}
+
+	hits, misses := len(documents)-len(missing), len(missing)
+	e.hits.Add(int64(hits))
+	e.misses.Add(int64(misses))
+	e.logger.Sugar().With("hits", hits, "misses", misses).Debug("embedding cache lookup")
+
+	// Documents with cached or repeated contents are done, the embedder reports the rest
+	reportEmbedProgress(ctx, hits)
+
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := e.embedder.EmbedDocuments(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missing) {
+		return nil, fmt.Errorf("embedded batch size mismatch: %d vectors for %d documents", len(embedded), len(missing))
+	}
+
+	toCache := make(map[string]Vector, len(missing))
+	for i, aDocument := range missing {
+		hash := contentHash(aDocument.Content)
+		for _, j := range indexes[hash] {
+			vectors[j] = embedded[i]
+		}
+		toCache[hash] = embedded[i]
+	}
+	e.saveEmbeddings(ctx, toCache)
+
+	return vectors, nil
+}
+
+// batchesDocuments returns true if the decorated embedder does, see BatchEmbedder.
+func (e *CachingEmbedder) batchesDocuments() bool {
+	return batchesDocuments(e.embedder)
+}
+
+func (e *CachingEmbedder) EmbedContent(ctx context.Context, content string) (Vector, error) {
+	hash := contentHash(content)
+
+	if vector, ok := e.findEmbeddings(ctx, hash)[hash]; ok {
+		e.hits.Add(1)
+		return vector, nil
+	}
+	e.misses.Add(1)
+
+	vector, err := e.embedder.EmbedContent(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	e.saveEmbeddings(ctx, map[string]Vector{hash: vector})
+
+	return vector, nil
+}
+
+func (e *CachingEmbedder) findEmbeddings(ctx context.Context, hashes ...string) map[string]Vector {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	cached, err := e.cache.FindEmbeddings(ctx, e.embedder.Name(), e.model, hashes...)
+	if err != nil {
+		e.logger.Sugar().With("error", err).Warn("error finding cached embeddings")
+		return nil
+	}
+	return cached
+}
+
+func (e *CachingEmbedder) saveEmbeddings(ctx context.Context, vectors map[string]Vector) {
+	if err := e.cache.SaveEmbeddings(ctx, e.embedder.Name(), e.model, vectors); err != nil {
+		e.logger.Sugar().With("error", err).Warn("error saving embeddings to cache")
+	}
+}
+
+// contentHash returns hex encoded SHA-256 hash of the content.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
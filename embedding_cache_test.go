@@ -0,0 +1,136 @@
+package ragserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryEmbeddingCache struct {
+	mu      sync.Mutex
+	err     error
+	vectors map[string]Vector
+}
+
+func (c *memoryEmbeddingCache) FindEmbeddings(ctx context.Context, embedder, model string, hashes ...string) (map[string]Vector, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	cached := map[string]Vector{}
+	for _, hash := range hashes {
+		if vector, ok := c.vectors[embedder+"/"+model+"/"+hash]; ok {
+			cached[hash] = vector
+		}
+	}
+	return cached, nil
+}
+
+func (c *memoryEmbeddingCache) SaveEmbeddings(ctx context.Context, embedder, model string, vectors map[string]Vector) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	if c.vectors == nil {
+		c.vectors = map[string]Vector{}
+	}
+	for hash, vector := range vectors {
+		c.vectors[embedder+"/"+model+"/"+hash] = vector
+	}
+	return nil
+}
+
+func TestCachingEmbedder_EmbedDocuments(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		cache     = &memoryEmbeddingCache{}
+		embedder  = &countingEmbedder{}
+		documents = []Document{{Content: "1"}, {Content: "2"}, {Content: "1"}}
+		expected  = []Vector{{1}, {2}, {1}}
+	)
+
+	cachingEmbedder := NewCachingEmbedder(embedder, "model", cache)
+	assert.Equal(t, "counting", cachingEmbedder.Name())
+
+	// Repeated contents are embedded once
+	vectors, err := cachingEmbedder.EmbedDocuments(ctx, documents)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vectors)
+	assert.Equal(t, []int{2}, embedder.batchSizes)
+	assert.Equal(t, EmbeddingCacheStats{Hits: 1, Misses: 2}, cachingEmbedder.Stats())
+
+	// Only new contents are embedded
+	vectors, err = cachingEmbedder.EmbedDocuments(ctx, append(documents, Document{Content: "3"}))
+	require.NoError(t, err)
+	assert.Equal(t, append(expected, Vector{3}), vectors)
+	assert.Equal(t, []int{2, 1}, embedder.batchSizes)
+	assert.Equal(t, EmbeddingCacheStats{Hits: 4, Misses: 3}, cachingEmbedder.Stats())
+
+	// Cached vectors are served for queries
+	vector, err := cachingEmbedder.EmbedContent(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, Vector{2}, vector)
+	assert.Equal(t, []int{2, 1}, embedder.batchSizes)
+	assert.Equal(t, EmbeddingCacheStats{Hits: 5, Misses: 3}, cachingEmbedder.Stats())
+
+	// Vectors of another model are not reused
+	otherModelEmbedder := NewCachingEmbedder(embedder, "other-model", cache)
+	vectors, err = otherModelEmbedder.EmbedDocuments(ctx, documents)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vectors)
+	assert.Equal(t, []int{2, 1, 2}, embedder.batchSizes)
+	assert.Equal(t, EmbeddingCacheStats{Hits: 1, Misses: 2}, otherModelEmbedder.Stats())
+}
+
+func TestCachingEmbedder_CacheUnavailable(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = context.Background()
+		embedder = &countingEmbedder{}
+		cache    = &memoryEmbeddingCache{err: errors.New("connection refused")}
+	)
+
+	cachingEmbedder := NewCachingEmbedder(embedder, "model", cache)
+
+	vectors, err := cachingEmbedder.EmbedDocuments(ctx, []Document{{Content: "1"}, {Content: "2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Vector{{1}, {2}}, vectors)
+
+	vector, err := cachingEmbedder.EmbedContent(ctx, "3")
+	require.NoError(t, err)
+	assert.Equal(t, Vector{3}, vector)
+	assert.Equal(t, EmbeddingCacheStats{Hits: 0, Misses: 3}, cachingEmbedder.Stats())
+}
+
+func TestCachingEmbedder_EmbedProgress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache           = &memoryEmbeddingCache{vectors: map[string]Vector{"counting/model/" + contentHash("1"): {1}}}
+		batchEmbedder   = NewBatchEmbedder(&countingEmbedder{}, WithEmbedBatchSize(1))
+		cachingEmbedder = NewCachingEmbedder(batchEmbedder, "model", cache)
+		embedded        int
+	)
+	ctx := withEmbedProgress(context.Background(), func(n int) {
+		embedded += n
+	})
+
+	// Cached and repeated contents are reported at once, the rest by the batch embedder
+	vectors, err := cachingEmbedder.EmbedDocuments(ctx, []Document{{Content: "1"}, {Content: "2"}, {Content: "3"}, {Content: "2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Vector{{1}, {2}, {3}, {2}}, vectors)
+	assert.Equal(t, 4, embedded)
+	assert.True(t, batchesDocuments(cachingEmbedder))
+	assert.False(t, batchesDocuments(NewCachingEmbedder(&countingEmbedder{}, "model", cache)))
+}
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	_ "embed"
 	"errors"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
 	"strings"
@@ -18,7 +20,6 @@ import (
 	"github.com/knights-analytics/hugot"
 	hugotOptions "github.com/knights-analytics/hugot/options"
 	_ "github.com/lib/pq"
-	"github.com/neurosnap/sentences"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -29,10 +30,14 @@ import (
 	"github.com/RichardKnop/ragserver/adapter/filestorage"
 	googlegenai "github.com/RichardKnop/ragserver/adapter/google-genai"
 	hugotAdapter "github.com/RichardKnop/ragserver/adapter/hugot"
+	"github.com/RichardKnop/ragserver/adapter/image"
+	"github.com/RichardKnop/ragserver/adapter/office"
 	"github.com/RichardKnop/ragserver/adapter/pdf"
+	"github.com/RichardKnop/ragserver/adapter/pdftext"
 	redisAdapter "github.com/RichardKnop/ragserver/adapter/redis"
 	"github.com/RichardKnop/ragserver/adapter/rest"
 	"github.com/RichardKnop/ragserver/adapter/store"
+	"github.com/RichardKnop/ragserver/adapter/text"
 	"github.com/RichardKnop/ragserver/api"
 )
 
@@ -41,6 +46,17 @@ func main() {
 	defer cancel()
 
 	viper.SetConfigName("config")
+	viper.SetDefault("adapter.extract.tables", true)
+	viper.SetDefault("adapter.chunk.name", "sentence")
+	viper.SetDefault("http.max_file_size_mb", 20)
+	viper.SetDefault("http.max_request_size_mb", 100)
+	viper.SetDefault("processing.interval", time.Second)
+	viper.SetDefault("processing.jitter", 100*time.Millisecond)
+	viper.SetDefault("processing.file_concurrency", 10)
+	viper.SetDefault("processing.file_timeout", 15*time.Minute)
+	viper.SetDefault("processing.max_file_attempts", 5)
+	viper.SetDefault("processing.file_retry_backoff", time.Minute)
+	viper.SetDefault("processing.upload_expiry", 24*time.Hour)
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
 	viper.AddConfigPath("examples/redis")
@@ -63,12 +79,6 @@ func main() {
 		log.Fatal("genai client: ", err)
 	}
 
-	// Load the training data
-	training, err := sentences.LoadTraining([]byte(ragserver.TestEn))
-	if err != nil {
-		log.Fatal("load training: ", err)
-	}
-
 	// Connect to the database
 	log.Println("connecting to db: ", viper.GetString("db.name"))
 	db, err := sql.Open(
@@ -100,12 +110,17 @@ func main() {
 	switch name := viper.GetString("adapter.extract.name"); name {
 	case "pdf":
 		log.Println("extract adapter: pdf")
-		extractor = pdf.New(training, pdf.WithLogger(logger))
+		extractor = pdf.New(
+			pdf.WithTables(viper.GetBool("adapter.extract.tables")),
+			pdf.WithLogger(logger),
+		)
+	case "pdftext":
+		log.Println("extract adapter: pdftext")
+		extractor = pdftext.New(pdftext.WithLogger(logger))
 	case "document":
 		log.Println("extract adapter: document")
 		extractor = document.New(
 			genaiClient,
-			training,
 			document.WithModel(viper.GetString("adapter.extract.model")),
 		)
 	default:
@@ -137,6 +152,14 @@ func main() {
 	default:
 		log.Fatalf("unknown embed adapter: %s", name)
 	}
+	// Documents are embedded in batches with bounded concurrency, rate limits and retries
+	embebber = batchEmbedderFromConfig(embebber, logger)
+
+	// Metadata fields copied to documents so retrieval can be filtered by them
+	metadataFields, err := metadataFieldsFromConfig()
+	if err != nil {
+		log.Fatal("metadata fields: ", err)
+	}
 
 	// Retriever
 	var retriever ragserver.Retriever
@@ -158,6 +181,8 @@ func main() {
 			redisAdapter.WithDialectVersion(viper.GetInt("redis.protocol")),
 			redisAdapter.WithVectorDim(viper.GetInt("redis.vector_dim")),
 			redisAdapter.WithVectorDistanceMetric(viper.GetString("redis.vector_distance_metric")),
+			redisAdapter.WithMetadataFields(metadataFields...),
+			redisAdapter.WithEmbeddingCacheTTL(viper.GetDuration("redis.embedding_cache_ttl")),
 			redisAdapter.WithLogger(logger),
 		)
 		if err != nil {
@@ -167,6 +192,12 @@ func main() {
 		log.Fatalf("unknown retrieve adapter: %s", name)
 	}
 
+	// Vectors of contents embedded before are served from the cache
+	embebber, err = cachingEmbedderFromConfig(embebber, db, retriever, logger)
+	if err != nil {
+		log.Fatal("embedding cache: ", err)
+	}
+
 	// Generative model
 	var gm ragserver.GenerativeModel
 	switch name := viper.GetString("adapter.generative.name"); name {
@@ -192,11 +223,73 @@ func main() {
 	}
 	log.Println("relevant topics configured", relevantTopics)
 
+	// Relevance filter decides which documents are relevant to topics
+	relevanceFilter, err := relevanceFilterFromConfig(embebber, hAdapter)
+	if err != nil {
+		log.Fatal("relevance filter: ", err)
+	}
+	if validator, ok := relevanceFilter.(ragserver.TopicValidator); ok {
+		if err := validator.ValidateTopics(relevantTopics); err != nil {
+			log.Fatal("relevant topics: ", err)
+		}
+	}
+
+	// Chunker splits extracted documents before embedding them
+	chunker, err := chunkerFromConfig()
+	if err != nil {
+		log.Fatal("chunker: ", err)
+	}
+	log.Println("chunker: ", chunker.Name())
+
+	// Plain text, markdown, HTML and Office files are always extracted locally
+	var (
+		textExtractor   = text.New(text.WithLogger(logger))
+		officeExtractor = office.New(office.WithLogger(logger))
+	)
+
+	// The limit applies to regular and resumable uploads as well as imported files
+	maxFileSize := viper.GetInt64("http.max_file_size_mb") * ragserver.MB
+
+	// Files are only imported from public addresses unless their network is allowed
+	importNetworks, err := importAllowedNetworksFromConfig()
+	if err != nil {
+		log.Fatal("import allowed networks: ", err)
+	}
+
 	opts := []ragserver.Option{
 		ragserver.WithRelevantTopics(relevantTopics),
+		ragserver.WithRelevanceFilter(relevanceFilter),
+		ragserver.WithIndexedMetadata(metadataFields...),
+		ragserver.WithChunker(chunker),
+		ragserver.WithExtractor(ragserver.ContentTypeText, textExtractor),
+		ragserver.WithExtractor(ragserver.ContentTypeMarkdown, textExtractor),
+		ragserver.WithExtractor(ragserver.ContentTypeHTML, textExtractor),
+		ragserver.WithExtractor(ragserver.ContentTypeDOCX, officeExtractor),
+		ragserver.WithExtractor(ragserver.ContentTypeXLSX, officeExtractor),
+		ragserver.WithExtractor(ragserver.ContentTypePPTX, officeExtractor),
+		ragserver.WithMaxFileSize(maxFileSize),
+		ragserver.WithImportAllowedNetworks(importNetworks...),
+		ragserver.WithProcessInterval(viper.GetDuration("processing.interval")),
+		ragserver.WithProcessJitter(viper.GetDuration("processing.jitter")),
+		ragserver.WithFileConcurrency(viper.GetInt("processing.file_concurrency")),
+		ragserver.WithFileTimeout(viper.GetDuration("processing.file_timeout")),
+		ragserver.WithMaxFileAttempts(viper.GetInt("processing.max_file_attempts")),
+		ragserver.WithFileRetryBackoff(viper.GetDuration("processing.file_retry_backoff")),
+		ragserver.WithUploadExpiry(viper.GetDuration("processing.upload_expiry")),
 		ragserver.WithLogger(logger),
 	}
 
+	// Images are only supported when an OCR engine is available (build with -tags tesseract)
+	if ocr := initOCR(logger); ocr != nil {
+		log.Println("ocr enabled")
+		imageExtractor := image.New(ocr, image.WithLogger(logger))
+		opts = append(
+			opts,
+			ragserver.WithExtractor(ragserver.ContentTypeJPEG, imageExtractor),
+			ragserver.WithExtractor(ragserver.ContentTypePNG, imageExtractor),
+		)
+	}
+
 	fileStorage, err := filestorage.New(
 		filestorage.WithDir(viper.GetString("adapter.filestorage.dir")),
 		filestorage.WithLogger(logger),
@@ -216,12 +309,17 @@ func main() {
 			fileStorage,
 			opts...,
 		)
-		restAdapter = rest.New(rs, rest.WithLogger(logger))
-		mux         = http.NewServeMux()
+		restAdapter = rest.New(
+			rs,
+			rest.WithMaxRequestSize(viper.GetInt64("http.max_request_size_mb")*ragserver.MB),
+			rest.WithLogger(logger),
+		)
+		mux = http.NewServeMux()
 		// get an `http.Handler` that we can use
 		h       = api.HandlerFromMux(restAdapter, mux)
 		address = ":" + viper.GetString("http.port")
 	)
+	mux.Handle("GET /debug/vars", expvar.Handler())
 
 	httpServer := &http.Server{
 		ReadHeaderTimeout: 10 * time.Second,
@@ -261,16 +359,152 @@ func main() {
 }
 
 func relevantTopicsFromConfig() (ragserver.RelevantTopics, error) {
-	var relevantTopics []ragserver.Topic
-	for name, keywords := range viper.GetStringMapStringSlice("relevant_topics") {
+	var relevantTopics ragserver.RelevantTopics
+	for name, value := range viper.GetStringMap("relevant_topics") {
+		key := "relevant_topics." + name
+		// A topic is either a list of keywords or a map with a description, keywords, patterns, exclusions and min matches
+		if _, ok := value.(map[string]any); !ok {
+			relevantTopics = append(relevantTopics, ragserver.Topic{
+				Name:     name,
+				Keywords: viper.GetStringSlice(key),
+			})
+			continue
+		}
 		relevantTopics = append(relevantTopics, ragserver.Topic{
-			Name:     name,
-			Keywords: keywords,
+			Name:            name,
+			Description:     viper.GetString(key + ".description"),
+			Keywords:        viper.GetStringSlice(key + ".keywords"),
+			Patterns:        viper.GetStringSlice(key + ".patterns"),
+			ExcludeKeywords: viper.GetStringSlice(key + ".exclude_keywords"),
+			MinMatches:      viper.GetInt(key + ".min_matches"),
 		})
 	}
+	if err := relevantTopics.Validate(); err != nil {
+		return nil, err
+	}
 	return relevantTopics, nil
 }
 
+func metadataFieldsFromConfig() ([]ragserver.MetadataField, error) {
+	var configured []struct {
+		Key  string `mapstructure:"key"`
+		Type string `mapstructure:"type"`
+	}
+	if err := viper.UnmarshalKey("metadata_fields", &configured); err != nil {
+		return nil, err
+	}
+
+	fields := make([]ragserver.MetadataField, 0, len(configured))
+	for _, c := range configured {
+		// Fields are matched by exact values unless they are numeric
+		field := ragserver.MetadataField{
+			Key:  c.Key,
+			Type: ragserver.MetadataFieldType(strings.ToUpper(c.Type)),
+		}
+		if field.Type == "" {
+			field.Type = ragserver.MetadataFieldTag
+		}
+		if field.Type != ragserver.MetadataFieldTag && field.Type != ragserver.MetadataFieldNumeric {
+			return nil, fmt.Errorf("unknown type of metadata field %s: %s", field.Key, field.Type)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func importAllowedNetworksFromConfig() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, network := range viper.GetStringSlice("http.import_allowed_networks") {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func relevanceFilterFromConfig(embedder ragserver.Embedder, hAdapter *hugotAdapter.Adapter) (ragserver.RelevanceFilter, error) {
+	switch name := viper.GetString("adapter.relevance.name"); name {
+	case "", "keyword":
+		log.Println("relevance filter: keyword")
+		return ragserver.KeywordFilter{}, nil
+	case "embedding":
+		log.Println("relevance filter: embedding")
+		return ragserver.NewEmbeddingFilter(embedder, viper.GetFloat64("adapter.relevance.threshold")), nil
+	case "zero-shot":
+		log.Println("relevance filter: zero-shot")
+		if hAdapter == nil {
+			return nil, fmt.Errorf("zero-shot relevance filter requires hugot")
+		}
+		return hAdapter, nil
+	default:
+		return nil, fmt.Errorf("unknown relevance filter: %s", name)
+	}
+}
+
+func batchEmbedderFromConfig(embedder ragserver.Embedder, logger *zap.Logger) ragserver.Embedder {
+	return ragserver.NewBatchEmbedder(
+		embedder,
+		ragserver.WithEmbedBatchSize(viper.GetInt("adapter.embed.batch_size")),
+		ragserver.WithEmbedConcurrency(viper.GetInt("adapter.embed.concurrency")),
+		ragserver.WithEmbedRateLimit(viper.GetFloat64("adapter.embed.rate_limit"), viper.GetInt("adapter.embed.burst")),
+		ragserver.WithEmbedRetries(viper.GetInt("adapter.embed.max_attempts"), viper.GetDuration("adapter.embed.retry_backoff")),
+		ragserver.WithEmbedLogger(logger),
+	)
+}
+
+func cachingEmbedderFromConfig(embedder ragserver.Embedder, db *sql.DB, retriever ragserver.Retriever, logger *zap.Logger) (ragserver.Embedder, error) {
+	var cache ragserver.EmbeddingCache
+	switch name := viper.GetString("adapter.embed.cache"); name {
+	case "":
+		return embedder, nil
+	case "postgres":
+		cache = store.New(db)
+	case "redis":
+		redisCache, ok := retriever.(ragserver.EmbeddingCache)
+		if !ok {
+			return nil, fmt.Errorf("redis cache requires redis retriever")
+		}
+		cache = redisCache
+	default:
+		return nil, fmt.Errorf("unknown embedding cache: %s", name)
+	}
+	log.Println("embedding cache: ", viper.GetString("adapter.embed.cache"))
+
+	cachingEmbedder := ragserver.NewCachingEmbedder(
+		embedder,
+		viper.GetString("adapter.embed.model"),
+		cache,
+		ragserver.WithCacheLogger(logger),
+	)
+	// Hits and misses are published at /debug/vars
+	expvar.Publish("embedding_cache", expvar.Func(func() any {
+		return cachingEmbedder.Stats()
+	}))
+
+	return cachingEmbedder, nil
+}
+
+func chunkerFromConfig() (ragserver.Chunker, error) {
+	switch name := viper.GetString("adapter.chunk.name"); name {
+	case "sentence":
+		// Training data of the detected language of each document is used
+		return ragserver.NewSentenceChunker(nil), nil
+	case "paragraph":
+		return ragserver.NewParagraphChunker(), nil
+	case "window":
+		return ragserver.NewWindowChunker(
+			viper.GetInt("adapter.chunk.size"),
+			viper.GetInt("adapter.chunk.overlap"),
+		)
+	case "section":
+		return ragserver.NewSectionChunker(viper.GetInt("adapter.chunk.max_size"))
+	default:
+		return nil, fmt.Errorf("unknown chunker: %s", name)
+	}
+}
+
 func initGenaiClient(ctx context.Context) (*genai.Client, error) {
 	if viper.GetString("adapter.extract.name") != "document" &&
 		viper.GetString("adapter.embed.name") != "google-genai" &&
@@ -324,9 +558,7 @@ func initHugot(ctx context.Context, logger *zap.Logger) (*hugotAdapter.Adapter,
 			log.Fatal("hugot session destroy: ", err)
 		}
 	}
-	hAdapter, err := hugotAdapter.New(
-		ctx,
-		session,
+	options := []hugotAdapter.Option{
 		hugotAdapter.WithEmbeddingModelName(viper.GetString("adapter.embed.model")),
 		hugotAdapter.WithEmbeddingModelOnnxFilePath(viper.GetString("adapter.embed.onx_file_path")),
 		hugotAdapter.WithGenerativeModelName(viper.GetString("adapter.generative.model")),
@@ -335,7 +567,16 @@ func initHugot(ctx context.Context, logger *zap.Logger) (*hugotAdapter.Adapter,
 		hugotAdapter.WithTemplatesDir(viper.GetString("adapter.generative.templates_dir")),
 		hugotAdapter.WithModelsDir(viper.GetString("hugot.models_dir")),
 		hugotAdapter.WithLogger(logger),
-	)
+	}
+	if viper.GetString("adapter.relevance.name") == "zero-shot" {
+		options = append(
+			options,
+			hugotAdapter.WithZeroShotModelName(viper.GetString("adapter.relevance.model")),
+			hugotAdapter.WithZeroShotModelOnnxFilePath(viper.GetString("adapter.relevance.onx_file_path")),
+			hugotAdapter.WithZeroShotThreshold(viper.GetFloat64("adapter.relevance.threshold")),
+		)
+	}
+	hAdapter, err := hugotAdapter.New(ctx, session, options...)
 	if err != nil {
 		log.Fatal("hugot adapter: ", err)
 	}
@@ -0,0 +1,18 @@
+//go:build tesseract
+
+package main
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/adapter/tesseract"
+)
+
+func initOCR(logger *zap.Logger) ragserver.OCR {
+	return tesseract.New(
+		tesseract.WithLanguages(viper.GetStringSlice("adapter.ocr.languages")...),
+		tesseract.WithLogger(logger),
+	)
+}
@@ -0,0 +1,14 @@
+//go:build !tesseract
+
+package main
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+// initOCR returns nil when built without the tesseract build tag, image uploads are disabled.
+func initOCR(logger *zap.Logger) ragserver.OCR {
+	return nil
+}
@@ -0,0 +1,21 @@
+package ragserver
+
+import "context"
+
+// ProcessFileWith processes the file with fakes and the extractor for its content type and
+// returns documents saved to the retriever. Tests in the ragserver_test package use it to
+// process files with adapters and ragservertest fakes, which can't be imported here.
+func ProcessFileWith(ctx context.Context, aFile *File, contents []byte, extractor Extractor) ([]Document, error) {
+	var (
+		retriever = newFakeRetriever()
+		rs        = newTestRagServer(newFakeStore(aFile))
+	)
+	rs.retriever = retriever
+	rs.extractors[aFile.ContentType] = extractor
+	rs.filestorage = fakeFileStorage{contents: map[string][]byte{aFile.Hash: contents}}
+
+	if err := rs.processFile(ctx, aFile); err != nil {
+		return nil, err
+	}
+	return retriever.fileDocuments(aFile.ID), nil
+}
@@ -0,0 +1,359 @@
+package ragserver
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+// Fakes below implement only the methods used by tests, other methods of embedded interfaces panic.
+
+type fakeStore struct {
+	Store
+	mu        sync.Mutex
+	files     map[FileID]*File
+	uploads   map[UploadID]*Upload
+	documents map[LogicalDocumentID]*LogicalDocument
+}
+
+func newFakeStore(files ...*File) *fakeStore {
+	s := &fakeStore{
+		files:     map[FileID]*File{},
+		uploads:   map[UploadID]*Upload{},
+		documents: map[LogicalDocumentID]*LogicalDocument{},
+	}
+	for _, aFile := range files {
+		s.files[aFile.ID] = aFile
+	}
+	return s
+}
+
+func (s *fakeStore) Transactional(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (s *fakeStore) SavePrincipal(ctx context.Context, principal authz.Principal) error {
+	return nil
+}
+
+func (s *fakeStore) SaveFiles(ctx context.Context, files ...*File) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, aFile := range files {
+		saved := *aFile
+		s.files[aFile.ID] = &saved
+	}
+	return nil
+}
+
+func (s *fakeStore) SaveFileMetadata(ctx context.Context, files ...*File) error {
+	return nil
+}
+
+func (s *fakeStore) FindFile(ctx context.Context, id FileID, partial authz.Partial) (*File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aFile, ok := s.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *aFile
+	return &found, nil
+}
+
+func (s *fakeStore) ListFiles(ctx context.Context, filter FileFilter, partial authz.Partial, params SortParams) ([]*File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var files []*File
+	for _, aFile := range s.files {
+		if filter.Hash != "" && aFile.Hash != filter.Hash {
+			continue
+		}
+		if filter.Chunker != "" && aFile.Chunker != filter.Chunker {
+			continue
+		}
+		if filter.Status != "" && aFile.Status != filter.Status {
+			continue
+		}
+		found := *aFile
+		files = append(files, &found)
+	}
+	return files, nil
+}
+
+func (s *fakeStore) SaveUploads(ctx context.Context, uploads ...*Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, anUpload := range uploads {
+		saved := *anUpload
+		s.uploads[anUpload.ID] = &saved
+	}
+	return nil
+}
+
+func (s *fakeStore) ListUploads(ctx context.Context, filter UploadFilter, params SortParams) ([]*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var uploads []*Upload
+	for _, anUpload := range s.uploads {
+		if !filter.ExpiresBefore.IsZero() && !anUpload.Expires.Before(filter.ExpiresBefore) {
+			continue
+		}
+		found := *anUpload
+		uploads = append(uploads, &found)
+	}
+	return uploads, nil
+}
+
+func (s *fakeStore) FindUpload(ctx context.Context, id UploadID, partial authz.Partial, lock bool) (*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	anUpload, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *anUpload
+	return &found, nil
+}
+
+func (s *fakeStore) DeleteUploads(ctx context.Context, uploads ...*Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, anUpload := range uploads {
+		delete(s.uploads, anUpload.ID)
+	}
+	return nil
+}
+
+func (s *fakeStore) FindLogicalDocument(ctx context.Context, id LogicalDocumentID, lock bool) (*LogicalDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aDocument, ok := s.documents[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return aDocument, nil
+}
+
+func (s *fakeStore) SaveScreenings(ctx context.Context, screenings ...*Screening) error {
+	return nil
+}
+
+func (s *fakeStore) SaveScreeningFiles(ctx context.Context, screenings ...*Screening) error {
+	return nil
+}
+
+func (s *fakeStore) SaveScreeningQuestions(ctx context.Context, screenings ...*Screening) error {
+	return nil
+}
+
+type fakeRetriever struct {
+	Retriever
+	name         string // fake-retriever if empty
+	mu           sync.Mutex
+	documents    map[FileID][]Document
+	filters      []DocumentFilter // filters documents were searched with
+	metadata     map[FileID]Metadata
+	metadataErrs []error // returned by metadata updates one by one before they succeed
+}
+
+func newFakeRetriever(documents ...Document) *fakeRetriever {
+	r := &fakeRetriever{documents: map[FileID][]Document{}, metadata: map[FileID]Metadata{}}
+	for _, aDocument := range documents {
+		r.documents[aDocument.FileID] = append(r.documents[aDocument.FileID], aDocument)
+	}
+	return r
+}
+
+func (r *fakeRetriever) Name() string {
+	if r.name != "" {
+		return r.name
+	}
+	return "fake-retriever"
+}
+
+func (r *fakeRetriever) SaveDocuments(ctx context.Context, documents []Document, vectors []Vector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, aDocument := range documents {
+		r.documents[aDocument.FileID] = append(r.documents[aDocument.FileID], aDocument)
+	}
+	return nil
+}
+
+func (r *fakeRetriever) SearchDocuments(ctx context.Context, filter DocumentFilter, limit int) ([]Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.filters = append(r.filters, filter)
+	var documents []Document
+	for _, fileID := range filter.FileIDs {
+		documents = append(documents, r.documents[fileID]...)
+	}
+	return documents, nil
+}
+
+func (r *fakeRetriever) DeleteFileDocuments(ctx context.Context, id FileID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.documents, id)
+	return nil
+}
+
+func (r *fakeRetriever) CopyFileDocuments(ctx context.Context, from, to FileID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, aDocument := range r.documents[from] {
+		aDocument.FileID = to
+		r.documents[to] = append(r.documents[to], aDocument)
+	}
+	return nil
+}
+
+func (r *fakeRetriever) UpdateFileMetadata(ctx context.Context, id FileID, metadata Metadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.metadataErrs) > 0 {
+		err := r.metadataErrs[0]
+		r.metadataErrs = r.metadataErrs[1:]
+		return err
+	}
+	r.metadata[id] = metadata
+	return nil
+}
+
+func (r *fakeRetriever) fileDocuments(id FileID) []Document {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.documents[id]
+}
+
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Name() string {
+	return "fake-embedder"
+}
+
+func (e fakeEmbedder) EmbedDocuments(ctx context.Context, documents []Document) ([]Vector, error) {
+	vectors := make([]Vector, 0, len(documents))
+	for _, aDocument := range documents {
+		vector, _ := e.EmbedContent(ctx, aDocument.Content)
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+func (fakeEmbedder) EmbedContent(ctx context.Context, content string) (Vector, error) {
+	return Vector{float32(len(content))}, nil
+}
+
+type fakeExtractor struct {
+	documents []Document
+}
+
+func (e fakeExtractor) Extract(ctx context.Context, fileName string, contents io.ReadSeeker) ([]Document, error) {
+	return e.documents, nil
+}
+
+type fakeFileStorage struct {
+	FileStorage
+	contents map[string][]byte // keyed by file name
+	tempDir  string            // temp files are created in, tests using them must set it
+}
+
+func (s fakeFileStorage) NewTempFile() (TempFile, error) {
+	return os.CreateTemp(s.tempDir, "upload")
+}
+
+func (s fakeFileStorage) OpenTempFile(name string) (TempFile, error) {
+	return os.OpenFile(name, os.O_RDWR, 0)
+}
+
+func (s fakeFileStorage) DeleteTempFile(name string) error {
+	return os.Remove(name)
+}
+
+func (s fakeFileStorage) Write(filename string, data io.Reader) error {
+	contents, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	s.contents[filename] = contents
+	return nil
+}
+
+func (s fakeFileStorage) Exists(filename string) (bool, error) {
+	_, ok := s.contents[filename]
+	return ok, nil
+}
+
+func (s fakeFileStorage) Delete(filename string) error {
+	delete(s.contents, filename)
+	return nil
+}
+
+func (s fakeFileStorage) Read(filename string) (io.ReadSeekCloser, error) {
+	contents, ok := s.contents[filename]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return nopCloser{bytes.NewReader(contents)}, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+type fakeGenerativeModel struct {
+	responses []Response
+	err       error
+	documents []Document // documents the last response was generated from
+}
+
+func (m *fakeGenerativeModel) Generate(ctx context.Context, question Question, documents []Document) ([]Response, error) {
+	m.documents = documents
+	return m.responses, m.err
+}
+
+// newTestRagServer returns a RAG server with fakes, tests replace them as needed.
+func newTestRagServer(store Store) *ragServer {
+	return &ragServer{
+		extractors:         map[string]Extractor{},
+		chunker:            NewParagraphChunker(),
+		embedder:           fakeEmbedder{},
+		retriever:          newFakeRetriever(),
+		previousRetrievers: map[string]Retriever{},
+		generative:         &fakeGenerativeModel{},
+		store:              store,
+		filestorage:        fakeFileStorage{contents: map[string][]byte{}},
+		maxFileAttempts:    defaultMaxFileAttempts,
+		now:                func() time.Time { return time.Now().UTC() },
+		relevanceFilter:    KeywordFilter{},
+		logger:             zap.NewNop(),
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
@@ -17,8 +20,14 @@ import (
 	"github.com/RichardKnop/ragserver/pkg/authz"
 )
 
+var (
+	ErrInvalidFileType   = errors.New("invalid file type")
+	ErrInvalidFileStatus = errors.New("invalid file status")
+)
+
 const (
-	MB          = 1 << 20
+	MB = 1 << 20
+	// MaxFileSize is the default limit of file size, use WithMaxFileSize option to change it
 	MaxFileSize = 20 * MB
 )
 
@@ -43,21 +52,69 @@ const (
 	FileStatusProcessingFailed      FileStatus = "PROCESSING_FAILED"
 )
 
+// FileStage is a step of processing a file, files which failed to process stay in the stage they failed in.
+type FileStage string
+
+const (
+	FileStageReading    FileStage = "READING"
+	FileStageExtracting FileStage = "EXTRACTING"
+	FileStageEmbedding  FileStage = "EMBEDDING"
+	FileStageIndexing   FileStage = "INDEXING"
+)
+
+// FileProgress counts work done while processing a file.
+type FileProgress struct {
+	Pages             int // pages documents were extracted from
+	Documents         int // documents to embed after chunking
+	DocumentsEmbedded int
+}
+
 type File struct {
-	ID            FileID
-	AuthorID      AuthorID
-	FileName      string
-	ContentType   string
-	Extension     string
-	Size          int64
-	Hash          string
-	Embedder      string // adapter used to generate embeddings for this file
-	Retriever     string // adapter used to store/retrieve embeddings for this file
-	Status        FileStatus
-	StatusMessage string
-	Created       time.Time
-	Updated       time.Time
-	Documents     []Document
+	ID                FileID
+	AuthorID          AuthorID
+	FileName          string
+	ContentType       string
+	Extension         string
+	Size              int64
+	Hash              string
+	SourceURL         string // URL the file was downloaded from, empty for uploaded files
+	Chunker           string // chunker used to split documents extracted from this file
+	Embedder          string // adapter used to generate embeddings for this file
+	Retriever         string // adapter used to store/retrieve embeddings for this file
+	Status            FileStatus
+	StatusMessage     string
+	Topics            *TopicSet         // topics selected when the file was uploaded, nil for topics configured on the server
+	Metadata          Metadata          // company name, reporting year, document type and such
+	LogicalDocumentID LogicalDocumentID // logical document the file is a version of, nil UUID if none
+	Version           int               // version of the logical document starting from 1, 0 if none
+	Language          Language          // detected language of the file contents, empty if unknown
+	Stage             FileStage         // current stage of processing, empty until processing starts
+	Progress          FileProgress
+	Attempts          int       // number of times processing of this file has started
+	NextAttempt       time.Time // when a failed file is processed again, zero if it won't be retried
+	Reprocessing      bool      // documents are extracted again rather than copied from a file with the same hash
+	Run               int       // incremented when the file is reprocessed, attempts and status events are counted per run
+	Created           time.Time
+	Updated           time.Time
+	Documents         []Document
+}
+
+// StartProcessing moves an uploaded file, or a failed file which is due to be retried,
+// to the processing state and counts the attempt.
+func (f *File) StartProcessing(updatedAt time.Time) error {
+	if f.Status != FileStatusUploaded && f.Status != FileStatusProcessingFailed {
+		return fmt.Errorf("%w: cannot start processing file in status %s", ErrInvalidFileStatus, f.Status)
+	}
+
+	f.Status = FileStatusProcessing
+	f.StatusMessage = ""
+	f.Stage = FileStageReading
+	f.Progress = FileProgress{}
+	f.Attempts++
+	f.NextAttempt = time.Time{}
+	f.Updated = updatedAt
+
+	return nil
 }
 
 // CompleteWithStatus changes the status of a file to a completion status,
@@ -70,6 +127,45 @@ func (f *File) CompleteWithStatus(newStatus FileStatus, message string, updatedA
 	f.Status = newStatus
 	f.StatusMessage = message
 	f.Updated = updatedAt
+	if newStatus == FileStatusProcessedSuccessfully {
+		f.Reprocessing = false
+	}
+
+	return nil
+}
+
+// StartStage moves a file being processed to the next stage of processing.
+func (f *File) StartStage(stage FileStage, updatedAt time.Time) error {
+	if f.Status != FileStatusProcessing {
+		return fmt.Errorf("%w: cannot start stage %s of file in status %s", ErrInvalidFileStatus, stage, f.Status)
+	}
+
+	f.Stage = stage
+	f.Updated = updatedAt
+
+	return nil
+}
+
+// Reprocess moves a processed file back to the uploaded state so it is picked up for processing
+// again, this time with the given embedder and retriever. Documents of processed files with the
+// same hash could be stale as well so they are not reused. Reprocessing starts a new run, so attempts
+// are counted from the start while status events of earlier runs are kept.
+func (f *File) Reprocess(embedder, retriever string, updatedAt time.Time) error {
+	if f.Status != FileStatusProcessedSuccessfully && f.Status != FileStatusProcessingFailed {
+		return fmt.Errorf("%w: cannot reprocess file in status %s", ErrInvalidFileStatus, f.Status)
+	}
+
+	f.Embedder = embedder
+	f.Retriever = retriever
+	f.Status = FileStatusUploaded
+	f.StatusMessage = ""
+	f.Stage = ""
+	f.Progress = FileProgress{}
+	f.Run++
+	f.Attempts = 0
+	f.NextAttempt = time.Time{}
+	f.Reprocessing = true
+	f.Updated = updatedAt
 
 	return nil
 }
@@ -77,87 +173,153 @@ func (f *File) CompleteWithStatus(newStatus FileStatus, message string, updatedA
 type FileFilter struct {
 	Embedder          string
 	Retriever         string
+	Chunker           string
+	NotEmbedder       string // files processed with a different embedder or NotRetriever
+	NotRetriever      string // files processed with a different retriever or NotEmbedder
 	Status            FileStatus
 	LastUpdatedBefore time.Time
+	NextAttemptBefore time.Time // failed files due to be retried
 	ScreeningID       ScreeningID
 	Hash              string
+	Metadata          MetadataFilter
+	LogicalDocumentID LogicalDocumentID
 	Lock              bool
 }
 
+// FileParams are recorded on new files, the same params are recorded on all files uploaded together.
+type FileParams struct {
+	Topics   TopicSelection
+	Metadata Metadata
+}
+
+// FileUpdate replaces metadata of a file, documents of the file are updated with indexed metadata.
+type FileUpdate struct {
+	Metadata Metadata
+}
+
 type TempFile interface {
 	io.ReadSeekCloser
 	io.Writer
 	Name() string
 }
 
-func (rs *ragServer) CreateFile(ctx context.Context, principal authz.Principal, file io.ReadSeeker, header *multipart.FileHeader) (*File, error) {
-	tempFile, err := rs.filestorage.NewTempFile()
+func (rs *ragServer) CreateFile(ctx context.Context, principal authz.Principal, file io.ReadSeeker, header *multipart.FileHeader, params FileParams) (*File, error) {
+	rs.logger.Sugar().With("filename", header.Filename, "size", header.Size, "header", header.Header).Infof("uploading file")
+
+	if header.Size > rs.maxFileSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrFileTooLarge, header.Size, rs.maxFileSize)
+	}
+
+	return rs.createFile(ctx, principal, header.Filename, file, "", params)
+}
+
+// createFile creates a new file from contents of the reader and saves it.
+func (rs *ragServer) createFile(ctx context.Context, principal authz.Principal, fileName string, file io.Reader, sourceURL string, params FileParams) (*File, error) {
+	if err := rs.validateMetadata(params.Metadata); err != nil {
+		return nil, err
+	}
+
+	topicSet, err := rs.resolveTopics(ctx, params.Topics)
 	if err != nil {
-		return nil, fmt.Errorf("error creating temp file: %v", err)
+		return nil, err
 	}
-	defer tempFile.Close()
 
-	contentType, ok, err := checkContentType(file)
+	aFile, err := rs.newFile(principal, fileName, file, sourceURL)
 	if err != nil {
-		return nil, fmt.Errorf("error checking content type: %w", err)
+		return nil, err
 	}
-	if !ok {
-		return nil, fmt.Errorf("invalid file type")
+	aFile.Topics = topicSet
+	aFile.Metadata = params.Metadata
+
+	if err := rs.saveNewFiles(ctx, principal, aFile); err != nil {
+		return nil, err
 	}
 
-	// Reset the file offset to the beginning for further reading
-	_, err = file.Seek(0, io.SeekStart)
+	return aFile, nil
+}
+
+// newFile copies contents of the reader to a temp file, calculating its hash, and stores it.
+// The returned file is not saved yet.
+func (rs *ragServer) newFile(principal authz.Principal, fileName string, file io.Reader, sourceURL string) (*File, error) {
+	tempFile, err := rs.filestorage.NewTempFile()
 	if err != nil {
-		return nil, fmt.Errorf("error seeking file to start: %w", err)
+		return nil, fmt.Errorf("error creating temp file: %v", err)
 	}
+	defer func() {
+		tempFile.Close()
+		rs.filestorage.DeleteTempFile(tempFile.Name())
+	}()
 
-	rs.logger.Sugar().With("filename", header.Filename, "size", header.Size, "header", header.Header).Infof("uploading file")
-
+	// Read one byte over the limit to detect larger files
 	hashWriter := sha256.New()
-	newReader := io.TeeReader(file, hashWriter)
+	newReader := io.TeeReader(io.LimitReader(file, rs.maxFileSize+1), hashWriter)
 	fileSize, err := io.Copy(tempFile, newReader)
 	if err != nil {
 		return nil, fmt.Errorf("error copying to temp file: %w", err)
 	}
+	if fileSize > rs.maxFileSize {
+		return nil, fmt.Errorf("%w: exceeds limit of %d bytes", ErrFileTooLarge, rs.maxFileSize)
+	}
 
 	fileHash := hex.EncodeToString(hashWriter.Sum(nil))
 
+	return rs.storeFile(principal, fileName, tempFile, fileSize, fileHash, sourceURL)
+}
+
+// storeFile stores contents of the file in file storage, unless a file with the same hash
+// already exists, and returns a new file in the uploaded state. The hash must be a hex encoded
+// SHA-256 of the contents. The returned file is not saved yet.
+func (rs *ragServer) storeFile(principal authz.Principal, fileName string, contents io.ReadSeeker, fileSize int64, fileHash, sourceURL string) (*File, error) {
+	// Reset the offset to the beginning for further reading
+	if _, err := contents.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("error seeking file to start: %w", err)
+	}
+
+	contentType, ok, err := rs.checkContentType(fileName, contents)
+	if err != nil {
+		return nil, fmt.Errorf("error checking content type: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFileType, contentType)
+	}
+
 	exists, err := rs.filestorage.Exists(fileHash)
 	if err != nil {
 		return nil, fmt.Errorf("error checking if file exists: %w", err)
 	}
 	if !exists {
-		// Reset the temp file offset to the beginning for further reading
-		_, err := tempFile.Seek(0, io.SeekStart)
-		if err != nil {
-			return nil, fmt.Errorf("error seeking temp file to start: %w", err)
+		// Reset the offset to the beginning again after detecting content type
+		if _, err := contents.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("error seeking file to start: %w", err)
 		}
 
-		if err := rs.filestorage.Write(fileHash, tempFile); err != nil {
+		if err := rs.filestorage.Write(fileHash, contents); err != nil {
 			return nil, fmt.Errorf("error writing to file storage: %w", err)
 		}
 	}
-	defer rs.filestorage.DeleteTempFile(tempFile.Name())
 
-	aFile := &File{
+	return &File{
 		ID:          NewFileID(),
 		AuthorID:    AuthorID{principal.ID().UUID},
-		FileName:    header.Filename,
+		FileName:    fileName,
 		ContentType: contentType,
+		Extension:   fileExtensions[contentType],
 		Size:        fileSize,
 		Hash:        fileHash,
+		SourceURL:   sourceURL,
+		Chunker:     rs.chunker.Name(),
 		Embedder:    rs.embedder.Name(),
 		Retriever:   rs.retriever.Name(),
 		Status:      FileStatusUploaded,
 		Created:     rs.now(),
 		Updated:     rs.now(),
-	}
+	}, nil
+}
 
-	switch contentType {
-	case "application/pdf":
-		aFile.Extension = strings.TrimPrefix(contentType, "application/")
-	case "image/jpeg", "image/png":
-		return nil, fmt.Errorf("image file processing not implemented yet")
+// saveNewFiles saves new files in a single transaction so they are picked up for processing.
+func (rs *ragServer) saveNewFiles(ctx context.Context, principal authz.Principal, files ...*File) error {
+	if len(files) == 0 {
+		return nil
 	}
 
 	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
@@ -165,23 +327,32 @@ func (rs *ragServer) CreateFile(ctx context.Context, principal authz.Principal,
 			return fmt.Errorf("error saving principal: %w", err)
 		}
 
-		if err := rs.store.SaveFiles(ctx, aFile); err != nil {
-			return fmt.Errorf("error saving file: %w", err)
+		if err := rs.store.SaveFiles(ctx, files...); err != nil {
+			return fmt.Errorf("error saving files: %w", err)
+		}
+
+		if err := rs.store.SaveFileMetadata(ctx, files...); err != nil {
+			return fmt.Errorf("error saving file metadata: %w", err)
 		}
 
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("error saving file: %v", err)
+		return fmt.Errorf("error saving files: %v", err)
 	}
 
-	return aFile, nil
+	return nil
 }
 
-func (rs *ragServer) ListFiles(ctx context.Context, principal authz.Principal) ([]*File, error) {
+// ListFiles lists files with metadata matching the filter, all files if the filter is empty.
+func (rs *ragServer) ListFiles(ctx context.Context, principal authz.Principal, metadata MetadataFilter) ([]*File, error) {
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+
 	var files []*File
 	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
 		var err error
-		files, err = rs.store.ListFiles(ctx, FileFilter{}, rs.filePpartial(), SortParams{})
+		files, err = rs.store.ListFiles(ctx, FileFilter{Metadata: metadata}, rs.filePpartial(), SortParams{})
 		if err != nil {
 			return err
 		}
@@ -207,6 +378,98 @@ func (rs *ragServer) FindFile(ctx context.Context, principal authz.Principal, id
 	return aFile, nil
 }
 
+// UpdateFile replaces metadata of a file. Documents of processed files are updated with the new
+// indexed metadata once the file is saved, files which are being processed can't be updated until
+// they are done.
+func (rs *ragServer) UpdateFile(ctx context.Context, principal authz.Principal, id FileID, update FileUpdate) (*File, error) {
+	rs.logger.Sugar().With("id", id).Info("updating file")
+
+	if err := rs.validateMetadata(update.Metadata); err != nil {
+		return nil, err
+	}
+
+	var aFile *File
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		aFile, err = rs.store.FindFile(ctx, id, rs.filePpartial())
+		if err != nil {
+			return err
+		}
+
+		if aFile.Status == FileStatusProcessing {
+			return fmt.Errorf("%w: cannot update file in status %s", ErrInvalidFileStatus, aFile.Status)
+		}
+
+		aFile.Metadata = update.Metadata
+		aFile.Updated = rs.now()
+
+		if err := rs.store.SaveFiles(ctx, aFile); err != nil {
+			return fmt.Errorf("error saving file: %w", err)
+		}
+
+		if err := rs.store.SaveFileMetadata(ctx, aFile); err != nil {
+			return fmt.Errorf("error saving file metadata: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// The retriever is not part of the transaction, documents are updated once the file is saved
+	if aFile.Status == FileStatusProcessedSuccessfully && len(rs.indexedMetadata) > 0 {
+		if err := rs.updateDocumentMetadata(ctx, aFile.ID); err != nil {
+			return nil, fmt.Errorf("error updating metadata of file documents, update the file again: %w", err)
+		}
+	}
+
+	return aFile, nil
+}
+
+const (
+	documentMetadataAttempts     = 3
+	documentMetadataRetryBackoff = 100 * time.Millisecond
+)
+
+// updateDocumentMetadata copies indexed metadata of a file to its documents. Metadata of documents
+// is replaced so failed updates are retried, and the file is read again on each attempt so documents
+// end up with the latest metadata when the file is updated concurrently.
+func (rs *ragServer) updateDocumentMetadata(ctx context.Context, id FileID) error {
+	for attempt := 1; ; attempt++ {
+		err := rs.tryUpdateDocumentMetadata(ctx, id)
+		if err == nil || attempt >= documentMetadataAttempts || errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		backoff := retryBackoff(documentMetadataRetryBackoff, attempt)
+		rs.logger.Sugar().With("id", id, "attempt", attempt, "backoff", backoff, "error", err).Warn("retrying update of document metadata")
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (rs *ragServer) tryUpdateDocumentMetadata(ctx context.Context, id FileID) error {
+	var aFile *File
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		aFile, err = rs.store.FindFile(ctx, id, rs.filePpartial())
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// Documents of files reprocessed in the meantime get metadata when they are saved
+	if aFile.Status != FileStatusProcessedSuccessfully {
+		return nil
+	}
+
+	return rs.retriever.UpdateFileMetadata(ctx, aFile.ID, rs.documentMetadata(aFile))
+}
+
 func (rs *ragServer) DeleteFile(ctx context.Context, principal authz.Principal, id FileID) error {
 	rs.logger.Sugar().With("id", id).Info("deleting file")
 
@@ -234,6 +497,9 @@ func (rs *ragServer) DeleteFile(ctx context.Context, principal authz.Principal,
 		filesWithHash, err := rs.store.ListFiles(ctx, FileFilter{
 			Hash: aFile.Hash,
 		}, authz.NilPartial, SortParams{})
+		if err != nil {
+			return fmt.Errorf("error listing files with the same hash: %w", err)
+		}
 
 		if err := rs.store.DeleteFiles(ctx, aFile); err != nil {
 			return fmt.Errorf("error deleting file: %w", err)
@@ -244,6 +510,9 @@ func (rs *ragServer) DeleteFile(ctx context.Context, principal authz.Principal,
 			if err := rs.filestorage.Delete(aFile.Hash); err != nil {
 				return fmt.Errorf("error deleting file from storage: %w", err)
 			}
+			if err := rs.deleteExtractedDocuments(aFile.Hash); err != nil {
+				return err
+			}
 		}
 
 		if err := rs.retriever.DeleteFileDocuments(ctx, id); err != nil {
@@ -257,23 +526,42 @@ func (rs *ragServer) DeleteFile(ctx context.Context, principal authz.Principal,
 	return nil
 }
 
-var allowedContentTypes = map[string]struct{}{
-	"application/pdf": {},
-	// "image/jpeg":      {},
-	// "image/png":       {},
-	// "image/gif":       {},
+const (
+	ContentTypePDF      = "application/pdf"
+	ContentTypeText     = "text/plain"
+	ContentTypeMarkdown = "text/markdown"
+	ContentTypeHTML     = "text/html"
+	ContentTypeDOCX     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	ContentTypeXLSX     = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	ContentTypePPTX     = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	ContentTypeJPEG     = "image/jpeg"
+	ContentTypePNG      = "image/png"
+)
+
+var fileExtensions = map[string]string{
+	ContentTypePDF:      "pdf",
+	ContentTypeText:     "txt",
+	ContentTypeMarkdown: "md",
+	ContentTypeHTML:     "html",
+	ContentTypeDOCX:     "docx",
+	ContentTypeXLSX:     "xlsx",
+	ContentTypePPTX:     "pptx",
+	ContentTypeJPEG:     "jpg",
+	ContentTypePNG:      "png",
 }
 
-func checkContentType(reader io.Reader) (string, bool, error) {
-	contentType, err := detectContentType(reader)
+// checkContentType detects content type of the file and checks whether there is an extractor
+// registered for it.
+func (rs *ragServer) checkContentType(fileName string, reader io.Reader) (string, bool, error) {
+	contentType, err := detectContentType(fileName, reader)
 	if err != nil {
 		return "", false, err
 	}
-	_, ok := allowedContentTypes[contentType]
+	_, ok := rs.extractors[contentType]
 	return contentType, ok, nil
 }
 
-func detectContentType(reader io.Reader) (string, error) {
+func detectContentType(fileName string, reader io.Reader) (string, error) {
 	// At most the first 512 bytes of data are used:
 	// https://golang.org/src/net/http/sniff.go?s=646:688#L11
 	buff := make([]byte, 512)
@@ -287,5 +575,33 @@ func detectContentType(reader io.Reader) (string, error) {
 	// (for example a text file which is smaller than 512 bytes)
 	buff = buff[:bytesRead]
 
-	return http.DetectContentType(buff), nil
+	// Detected content type can contain parameters such as charset, e.g. "text/plain; charset=utf-8"
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(buff))
+	if err != nil {
+		return "", err
+	}
+
+	// Markdown is just plain text as far as sniffing is concerned, and HTML fragments without
+	// a recognised opening tag are too, so we rely on the file extension for those.
+	// The same goes for Office documents which are zip archives.
+	switch contentType {
+	case ContentTypeText:
+		switch strings.ToLower(path.Ext(fileName)) {
+		case ".md", ".markdown":
+			return ContentTypeMarkdown, nil
+		case ".html", ".htm":
+			return ContentTypeHTML, nil
+		}
+	case "application/zip":
+		switch strings.ToLower(path.Ext(fileName)) {
+		case ".docx":
+			return ContentTypeDOCX, nil
+		case ".xlsx":
+			return ContentTypeXLSX, nil
+		case ".pptx":
+			return ContentTypePPTX, nil
+		}
+	}
+
+	return contentType, nil
 }
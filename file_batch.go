@@ -0,0 +1,125 @@
+package ragserver
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+var (
+	ErrInvalidArchive = errors.New("invalid archive")
+	ErrTooManyFiles   = errors.New("too many files")
+)
+
+// MaxBatchFiles is the maximum number of files uploaded in a single request or archive.
+const MaxBatchFiles = 100
+
+// FileEntry is one of multiple files uploaded together, contents are only read when the file is created.
+type FileEntry struct {
+	FileName string
+	Open     func() (io.ReadCloser, error)
+}
+
+// FileEntryResult is the outcome of creating a file from an entry, either File or Err is set.
+type FileEntryResult struct {
+	FileName string
+	File     *File
+	Err      error
+}
+
+// CreateFiles creates a file from each entry. Entries which can't be created, for example because
+// their content type is not supported, are reported in the results and don't prevent other entries
+// from being created. All created files are saved in a single transaction with the same params.
+func (rs *ragServer) CreateFiles(ctx context.Context, principal authz.Principal, entries []FileEntry, params FileParams) ([]FileEntryResult, error) {
+	rs.logger.Sugar().With("files", len(entries)).Info("uploading files")
+
+	if len(entries) > MaxBatchFiles {
+		return nil, fmt.Errorf("%w: %d files exceeds limit of %d files", ErrTooManyFiles, len(entries), MaxBatchFiles)
+	}
+
+	if err := rs.validateMetadata(params.Metadata); err != nil {
+		return nil, err
+	}
+
+	topicSet, err := rs.resolveTopics(ctx, params.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results = make([]FileEntryResult, 0, len(entries))
+		files   = make([]*File, 0, len(entries))
+	)
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		aFile, err := rs.newFileFromEntry(principal, entry)
+		if err != nil {
+			rs.logger.Sugar().With("filename", entry.FileName, "error", err).Warn("error creating file")
+			results = append(results, FileEntryResult{FileName: entry.FileName, Err: err})
+			continue
+		}
+		aFile.Topics = topicSet
+		aFile.Metadata = params.Metadata
+
+		files = append(files, aFile)
+		results = append(results, FileEntryResult{FileName: entry.FileName, File: aFile})
+	}
+
+	if err := rs.saveNewFiles(ctx, principal, files...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (rs *ragServer) newFileFromEntry(principal authz.Principal, entry FileEntry) (*File, error) {
+	contents, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer contents.Close()
+
+	return rs.newFile(principal, entry.FileName, contents, "")
+}
+
+// CreateFilesFromArchive creates a file from each entry of a ZIP archive, see CreateFiles.
+func (rs *ragServer) CreateFilesFromArchive(ctx context.Context, principal authz.Principal, archive io.ReaderAt, size int64, params FileParams) ([]FileEntryResult, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	return rs.CreateFiles(ctx, principal, archiveEntries(reader), params)
+}
+
+// archiveEntries returns file entries of the archive, skipping directories and metadata
+// added by archiving tools such as __MACOSX folders or .DS_Store files. Entries are named by
+// their base name as directories inside the archive have no meaning for uploaded files.
+func archiveEntries(reader *zip.Reader) []FileEntry {
+	entries := make([]FileEntry, 0, len(reader.File))
+	for _, aFile := range reader.File {
+		if aFile.FileInfo().IsDir() {
+			continue
+		}
+
+		fileName := path.Base(aFile.Name)
+		if strings.HasPrefix(aFile.Name, "__MACOSX/") || strings.HasPrefix(fileName, ".") {
+			continue
+		}
+
+		entries = append(entries, FileEntry{
+			FileName: fileName,
+			Open:     aFile.Open,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,196 @@
+package ragserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+// batchContents are contents of a valid text file, an unsupported binary file and a text file over
+// the size limit of newBatchRagServer, keyed by file name.
+var batchContents = map[string]string{
+	"emissions.txt": "Our total scope 1 emissions were 100 tCO2e.",
+	"logo.bin":      "\x00\x01\x02\x03",
+	"large.txt":     strings.Repeat("scope 1 ", 20),
+}
+
+func newBatchRagServer(t *testing.T) (*ragServer, *fakeStore) {
+	store := newFakeStore()
+	rs := newTestRagServer(store)
+	rs.filestorage = fakeFileStorage{contents: map[string][]byte{}, tempDir: t.TempDir()}
+	rs.extractors[ContentTypeText] = fakeExtractor{}
+	rs.maxFileSize = 100
+	return rs, store
+}
+
+// assertBatchResults checks the valid file is created and saved while others are reported as errors.
+func assertBatchResults(t *testing.T, store *fakeStore, results []FileEntryResult) {
+	require.Len(t, results, 3)
+
+	byName := map[string]FileEntryResult{}
+	for _, result := range results {
+		byName[result.FileName] = result
+	}
+
+	valid := byName["emissions.txt"]
+	require.NoError(t, valid.Err)
+	require.NotNil(t, valid.File)
+	assert.Equal(t, ContentTypeText, valid.File.ContentType)
+	assert.Equal(t, map[FileID]*File{valid.File.ID: valid.File}, store.files)
+
+	assert.Nil(t, byName["logo.bin"].File)
+	assert.ErrorIs(t, byName["logo.bin"].Err, ErrInvalidFileType)
+
+	assert.Nil(t, byName["large.txt"].File)
+	assert.ErrorIs(t, byName["large.txt"].Err, ErrFileTooLarge)
+}
+
+func TestRagServer_CreateFiles(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+	)
+
+	t.Run("Entries which can't be created don't fail others", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store := newBatchRagServer(t)
+
+		var entries []FileEntry
+		for _, name := range []string{"emissions.txt", "logo.bin", "large.txt"} {
+			entries = append(entries, FileEntry{
+				FileName: name,
+				Open: func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader(batchContents[name])), nil
+				},
+			})
+		}
+
+		results, err := rs.CreateFiles(ctx, principal, entries, FileParams{})
+		require.NoError(t, err)
+		assertBatchResults(t, store, results)
+		assert.Equal(t, "emissions.txt", results[0].FileName, "results are in the order of entries")
+	})
+
+	t.Run("Too many files", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store := newBatchRagServer(t)
+
+		opened := 0
+		entries := make([]FileEntry, MaxBatchFiles+1)
+		for i := range entries {
+			entries[i] = FileEntry{
+				FileName: "emissions.txt",
+				Open: func() (io.ReadCloser, error) {
+					opened += 1
+					return io.NopCloser(strings.NewReader(batchContents["emissions.txt"])), nil
+				},
+			}
+		}
+
+		_, err := rs.CreateFiles(ctx, principal, entries, FileParams{})
+		require.ErrorIs(t, err, ErrTooManyFiles)
+		assert.Zero(t, opened)
+		assert.Empty(t, store.files)
+
+		results, err := rs.CreateFiles(ctx, principal, entries[:MaxBatchFiles], FileParams{})
+		require.NoError(t, err)
+		assert.Len(t, results, MaxBatchFiles)
+	})
+}
+
+func TestRagServer_CreateFilesFromArchive(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+	)
+
+	t.Run("Entries which can't be created don't fail others", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store := newBatchRagServer(t)
+
+		buf := new(bytes.Buffer)
+		writer := zip.NewWriter(buf)
+		for name, contents := range batchContents {
+			w, err := writer.Create("reports/" + name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		results, err := rs.CreateFilesFromArchive(ctx, principal, bytes.NewReader(buf.Bytes()), int64(buf.Len()), FileParams{})
+		require.NoError(t, err)
+		assertBatchResults(t, store, results)
+	})
+
+	t.Run("Invalid archive", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store := newBatchRagServer(t)
+
+		data := []byte("not a zip archive")
+		_, err := rs.CreateFilesFromArchive(ctx, principal, bytes.NewReader(data), int64(len(data)), FileParams{})
+		require.ErrorIs(t, err, ErrInvalidArchive)
+		assert.Empty(t, store.files)
+	})
+}
+
+func TestArchiveEntries(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for name, contents := range map[string]string{
+		"reports/":                         "",
+		"reports/2023.pdf":                 "%PDF-1.4 2023",
+		"reports/2024/climate.md":          "# Climate",
+		"__MACOSX/reports/._2023.pdf":      "metadata",
+		"reports/.DS_Store":                "metadata",
+		"../../outside/emissions-data.txt": "scope 1",
+	} {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	entries := archiveEntries(reader)
+
+	actual := map[string]string{}
+	for _, entry := range entries {
+		contents, err := entry.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(contents)
+		require.NoError(t, err)
+		require.NoError(t, contents.Close())
+
+		actual[entry.FileName] = string(data)
+	}
+
+	expected := map[string]string{
+		"2023.pdf":           "%PDF-1.4 2023",
+		"climate.md":         "# Climate",
+		"emissions-data.txt": "scope 1",
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,173 @@
+package ragserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+type TextFormat string
+
+const (
+	TextFormatPlain    TextFormat = "text"
+	TextFormatMarkdown TextFormat = "markdown"
+)
+
+// FileContent opens original contents of a file, the caller must close the returned reader.
+func (rs *ragServer) FileContent(ctx context.Context, principal authz.Principal, id FileID) (*File, io.ReadSeekCloser, error) {
+	aFile, err := rs.FindFile(ctx, principal, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := rs.filestorage.Read(aFile.Hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening file: %w", err)
+	}
+
+	return aFile, content, nil
+}
+
+// FileText returns text of documents extracted from a file grouped by page. Documents are not
+// chunked or filtered by relevance, so reviewers can verify what the extractor saw regardless of
+// what ended up being embedded. Extracted documents are saved when the file is processed, files
+// processed before that was the case are extracted again once and saved.
+func (rs *ragServer) FileText(ctx context.Context, principal authz.Principal, id FileID, format TextFormat) (string, error) {
+	aFile, err := rs.FindFile(ctx, principal, id)
+	if err != nil {
+		return "", err
+	}
+
+	documents, err := rs.findExtractedDocuments(aFile.Hash)
+	if err != nil {
+		return "", err
+	}
+	if documents != nil {
+		return documentsText(documents, format), nil
+	}
+
+	extractor, ok := rs.extractors[aFile.ContentType]
+	if !ok {
+		return "", fmt.Errorf("%w: no extractor for content type %s", ErrInvalidFileType, aFile.ContentType)
+	}
+
+	content, err := rs.filestorage.Read(aFile.Hash)
+	if err != nil {
+		return "", fmt.Errorf("error opening file: %w", err)
+	}
+	defer func() {
+		if err := content.Close(); err != nil {
+			rs.logger.Sugar().With("hash", aFile.Hash, "error", err).Error("error closing file")
+		}
+	}()
+
+	documents, err = extractor.Extract(ctx, aFile.FileName, content)
+	if err != nil {
+		return "", fmt.Errorf("error extracting documents: %w", err)
+	}
+	rs.saveExtractedDocuments(aFile.Hash, documents)
+
+	return documentsText(documents, format), nil
+}
+
+// extractedDocumentsName is the name documents extracted from files with the hash are saved as in
+// file storage, next to the original contents.
+func extractedDocumentsName(hash string) string {
+	return hash + ".documents.json"
+}
+
+// saveExtractedDocuments saves documents extracted from files with the hash, documents are saved as
+// a convenience for FileText so errors are only logged.
+func (rs *ragServer) saveExtractedDocuments(hash string, documents []Document) {
+	if documents == nil {
+		documents = []Document{}
+	}
+	data, err := json.Marshal(documents)
+	if err == nil {
+		err = rs.filestorage.Write(extractedDocumentsName(hash), bytes.NewReader(data))
+	}
+	if err != nil {
+		rs.logger.Sugar().With("hash", hash, "error", err).Warn("error saving extracted documents")
+	}
+}
+
+// findExtractedDocuments returns documents extracted from files with the hash, or nil if they were not saved.
+func (rs *ragServer) findExtractedDocuments(hash string) ([]Document, error) {
+	name := extractedDocumentsName(hash)
+	exists, err := rs.filestorage.Exists(name)
+	if err != nil {
+		return nil, fmt.Errorf("error checking extracted documents: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	content, err := rs.filestorage.Read(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening extracted documents: %w", err)
+	}
+	defer content.Close()
+
+	documents := []Document{}
+	if err := json.NewDecoder(content).Decode(&documents); err != nil {
+		return nil, fmt.Errorf("error decoding extracted documents: %w", err)
+	}
+	return documents, nil
+}
+
+// deleteExtractedDocuments deletes documents extracted from files with the hash if they were saved.
+func (rs *ragServer) deleteExtractedDocuments(hash string) error {
+	name := extractedDocumentsName(hash)
+	exists, err := rs.filestorage.Exists(name)
+	if err != nil {
+		return fmt.Errorf("error checking extracted documents: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+	if err := rs.filestorage.Delete(name); err != nil {
+		return fmt.Errorf("error deleting extracted documents: %w", err)
+	}
+	return nil
+}
+
+// documentsText joins contents of documents in the order they were extracted. Pages are separated
+// by page headings, Markdown also has a heading whenever the section changes.
+func documentsText(documents []Document, format TextFormat) string {
+	var (
+		b       strings.Builder
+		page    = -1
+		section string
+	)
+	for _, aDocument := range documents {
+		content := strings.TrimSpace(aDocument.Content)
+		if content == "" {
+			continue
+		}
+
+		if aDocument.Page != page {
+			page = aDocument.Page
+			section = ""
+			if format == TextFormatMarkdown {
+				fmt.Fprintf(&b, "## Page %d\n\n", page)
+			} else {
+				fmt.Fprintf(&b, "--- Page %d ---\n\n", page)
+			}
+		}
+
+		if aSection := strings.Join(strings.Fields(aDocument.Section), " "); format == TextFormatMarkdown && aSection != "" && aSection != section {
+			section = aSection
+			fmt.Fprintf(&b, "### %s\n\n", section)
+		}
+
+		b.WriteString(content)
+		b.WriteString("\n\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
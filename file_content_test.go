@@ -0,0 +1,112 @@
+package ragserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+func TestDocumentsText(t *testing.T) {
+	t.Parallel()
+
+	documents := []Document{
+		{Page: 1, Section: "Introduction", Content: "First paragraph."},
+		{Page: 1, Section: "Introduction", Content: "  Second paragraph.\n"},
+		{Page: 1, Section: "Scope", Content: "Third paragraph."},
+		{Page: 2, Section: "Scope", Content: "   "},
+		{Page: 2, Section: "Scope", Content: "| a | b |\n| 1 | 2 |"},
+	}
+
+	tests := []struct {
+		name      string
+		documents []Document
+		format    TextFormat
+		expected  string
+	}{
+		{"no documents", nil, TextFormatPlain, ""},
+		{
+			"plain text",
+			documents,
+			TextFormatPlain,
+			"--- Page 1 ---\n\nFirst paragraph.\n\nSecond paragraph.\n\nThird paragraph.\n\n" +
+				"--- Page 2 ---\n\n| a | b |\n| 1 | 2 |\n",
+		},
+		{
+			"markdown",
+			documents,
+			TextFormatMarkdown,
+			"## Page 1\n\n### Introduction\n\nFirst paragraph.\n\nSecond paragraph.\n\n### Scope\n\nThird paragraph.\n\n" +
+				"## Page 2\n\n### Scope\n\n| a | b |\n| 1 | 2 |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, documentsText(tt.documents, tt.format))
+		})
+	}
+}
+
+func TestRagServer_FileText(t *testing.T) {
+	t.Parallel()
+
+	var (
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+		extracted = []Document{{Page: 1, Content: "Extracted paragraph."}}
+		saved     = []Document{{Page: 1, Content: "Saved paragraph."}}
+	)
+
+	newFile := func() *File {
+		return &File{
+			ID:          NewFileID(),
+			FileName:    "report.pdf",
+			ContentType: ContentTypePDF,
+			Hash:        "hash",
+			Status:      FileStatusProcessedSuccessfully,
+		}
+	}
+
+	t.Run("saved documents are returned without extracting them again", func(t *testing.T) {
+		t.Parallel()
+
+		aFile := newFile()
+		rs := newTestRagServer(newFakeStore(aFile))
+		rs.saveExtractedDocuments(aFile.Hash, saved)
+
+		text, err := rs.FileText(context.Background(), principal, aFile.ID, TextFormatPlain)
+		require.NoError(t, err)
+		assert.Equal(t, "--- Page 1 ---\n\nSaved paragraph.\n", text)
+	})
+
+	t.Run("documents are extracted and saved if they were not saved", func(t *testing.T) {
+		t.Parallel()
+
+		aFile := newFile()
+		rs := newTestRagServer(newFakeStore(aFile))
+		rs.extractors[ContentTypePDF] = fakeExtractor{documents: extracted}
+		rs.filestorage = fakeFileStorage{contents: map[string][]byte{"hash": []byte("%PDF")}}
+
+		text, err := rs.FileText(context.Background(), principal, aFile.ID, TextFormatPlain)
+		require.NoError(t, err)
+		assert.Equal(t, "--- Page 1 ---\n\nExtracted paragraph.\n", text)
+
+		documents, err := rs.findExtractedDocuments(aFile.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, extracted, documents)
+	})
+
+	t.Run("files without saved documents and extractor", func(t *testing.T) {
+		t.Parallel()
+
+		aFile := newFile()
+		rs := newTestRagServer(newFakeStore(aFile))
+
+		_, err := rs.FileText(context.Background(), principal, aFile.ID, TextFormatPlain)
+		assert.ErrorIs(t, err, ErrInvalidFileType)
+	})
+}
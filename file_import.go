@@ -0,0 +1,181 @@
+package ragserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+var (
+	ErrInvalidURL   = errors.New("invalid URL")
+	ErrFileTooLarge = errors.New("file too large")
+)
+
+// ImportFile downloads a file from the URL and creates it the same way as an uploaded file.
+// The URL is recorded on the file so it can be downloaded again later.
+func (rs *ragServer) ImportFile(ctx context.Context, principal authz.Principal, sourceURL string, params FileParams) (*File, error) {
+	rs.logger.Sugar().With("url", sourceURL).Info("importing file")
+
+	tempFile, err := rs.filestorage.NewTempFile()
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp file: %v", err)
+	}
+	defer func() {
+		tempFile.Close()
+		rs.filestorage.DeleteTempFile(tempFile.Name())
+	}()
+
+	fileName, err := downloadFile(ctx, rs.httpClient, sourceURL, rs.maxFileSize, tempFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reset the temp file offset to the beginning for further reading
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("error seeking temp file to start: %w", err)
+	}
+
+	return rs.createFile(ctx, principal, fileName, tempFile, sourceURL, params)
+}
+
+// downloadFile writes contents of the URL to dst and returns a file name for it, either from
+// Content-Disposition header or the URL path. Downloads over maxSize bytes are aborted.
+func downloadFile(ctx context.Context, client *http.Client, sourceURL string, maxSize int64, dst io.Writer) (string, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error downloading file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("error downloading file: unexpected status %s", resp.Status)
+	}
+
+	if resp.ContentLength > maxSize {
+		return "", fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrFileTooLarge, resp.ContentLength, maxSize)
+	}
+
+	// Content length is not always known up front, read one byte over the limit to detect larger files
+	written, err := io.Copy(dst, io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("error downloading file: %w", err)
+	}
+	if written > maxSize {
+		return "", fmt.Errorf("%w: exceeds limit of %d bytes", ErrFileTooLarge, maxSize)
+	}
+
+	return fileNameFromResponse(resp), nil
+}
+
+func fileNameFromResponse(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := path.Base(params["filename"]); name != "." && name != "/" {
+			return name
+		}
+	}
+
+	// Use the final URL after redirects
+	name := path.Base(resp.Request.URL.Path)
+	if name == "." || name == "/" || strings.TrimSpace(name) == "" {
+		return "download"
+	}
+	return name
+}
+
+// blockedNetworks are special purpose networks not covered by netip.Addr methods which are
+// not reachable on the internet.
+var blockedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, could translate to a private IPv4 address
+}
+
+// newImportClient returns a client which refuses to connect to private, loopback, link-local and
+// other internal addresses unless they are in the allowed networks. Addresses are checked when
+// connecting rather than when parsing the URL so redirects and DNS rebinding are covered as well.
+// Proxies are not used as the address of the proxy would be checked instead of the file server.
+func newImportClient(allowed []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   importDialControl(allowed),
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   60 * time.Second,
+		Transport: transport,
+	}
+}
+
+func importDialControl(allowed []netip.Prefix) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+		}
+		addr := addrPort.Addr().Unmap()
+
+		for _, prefix := range allowed {
+			if prefix.Contains(addr) {
+				return nil
+			}
+		}
+
+		if !isPublicAddr(addr) {
+			return fmt.Errorf("%w: address %s is not allowed", ErrInvalidURL, addr)
+		}
+		return nil
+	}
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	if addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range blockedNetworks {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package ragserver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadFile(t *testing.T) {
+	t.Parallel()
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reports/2023.pdf":
+			w.Write([]byte("%PDF-1.4 report"))
+		case "/download":
+			w.Header().Set("Content-Disposition", `attachment; filename="annual-report.pdf"`)
+			w.Write([]byte("%PDF-1.4 report"))
+		case "/redirect":
+			http.Redirect(w, r, "/reports/2023.pdf", http.StatusFound)
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", 100)))
+		case "/large-streamed":
+			// Flushing before writing everything forces chunked encoding without content length
+			w.Write([]byte(strings.Repeat("a", 50)))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("a", 50)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer svr.Close()
+
+	tests := []struct {
+		name             string
+		url              string
+		expectedFileName string
+		expectedContents string
+		expectedErr      error
+		wantErr          bool
+	}{
+		{
+			name:             "file name from URL path",
+			url:              svr.URL + "/reports/2023.pdf",
+			expectedFileName: "2023.pdf",
+			expectedContents: "%PDF-1.4 report",
+		},
+		{
+			name:             "file name from content disposition",
+			url:              svr.URL + "/download",
+			expectedFileName: "annual-report.pdf",
+			expectedContents: "%PDF-1.4 report",
+		},
+		{
+			name:             "file name from URL after redirect",
+			url:              svr.URL + "/redirect",
+			expectedFileName: "2023.pdf",
+			expectedContents: "%PDF-1.4 report",
+		},
+		{
+			name:        "too large",
+			url:         svr.URL + "/large",
+			expectedErr: ErrFileTooLarge,
+		},
+		{
+			name:        "too large without content length",
+			url:         svr.URL + "/large-streamed",
+			expectedErr: ErrFileTooLarge,
+		},
+		{
+			name:    "not found",
+			url:     svr.URL + "/missing.pdf",
+			wantErr: true,
+		},
+		{
+			name:        "unsupported scheme",
+			url:         "file:///etc/passwd",
+			expectedErr: ErrInvalidURL,
+		},
+		{
+			name:        "missing host",
+			url:         "http:///report.pdf",
+			expectedErr: ErrInvalidURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := new(bytes.Buffer)
+			fileName, err := downloadFile(context.Background(), svr.Client(), tt.url, 64, dst)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFileName, fileName)
+			assert.Equal(t, tt.expectedContents, dst.String())
+		})
+	}
+}
+
+func TestImportDialControl(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		address string
+		allowed []netip.Prefix
+		wantErr bool
+	}{
+		{name: "public IPv4", address: "93.184.216.34:443"},
+		{name: "public IPv6", address: "[2606:2800:220:1:248:1893:25c8:1946]:443"},
+		{name: "loopback", address: "127.0.0.1:80", wantErr: true},
+		{name: "IPv6 loopback", address: "[::1]:80", wantErr: true},
+		{name: "IPv4 mapped loopback", address: "[::ffff:127.0.0.1]:80", wantErr: true},
+		{name: "private", address: "10.0.0.5:80", wantErr: true},
+		{name: "IPv6 unique local", address: "[fd00::1]:80", wantErr: true},
+		{name: "link-local metadata service", address: "169.254.169.254:80", wantErr: true},
+		{name: "unspecified", address: "0.0.0.0:80", wantErr: true},
+		{name: "carrier-grade NAT", address: "100.64.0.1:80", wantErr: true},
+		{
+			name:    "allowed private network",
+			address: "10.0.0.5:80",
+			allowed: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		},
+		{
+			name:    "other private network than allowed",
+			address: "192.168.1.5:80",
+			allowed: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := importDialControl(tt.allowed)("tcp", tt.address, nil)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidURL)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewImportClient(t *testing.T) {
+	t.Parallel()
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("%PDF-1.4 report"))
+	}))
+	defer svr.Close()
+
+	t.Run("loopback server is refused", func(t *testing.T) {
+		_, err := downloadFile(context.Background(), newImportClient(nil), svr.URL+"/report.pdf", 64, new(bytes.Buffer))
+		require.ErrorIs(t, err, ErrInvalidURL)
+	})
+
+	t.Run("loopback server in allowed networks", func(t *testing.T) {
+		client := newImportClient([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+
+		dst := new(bytes.Buffer)
+		fileName, err := downloadFile(context.Background(), client, svr.URL+"/report.pdf", 64, dst)
+		require.NoError(t, err)
+		assert.Equal(t, "report.pdf", fileName)
+		assert.Equal(t, "%PDF-1.4 report", dst.String())
+	})
+}
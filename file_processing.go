@@ -6,30 +6,36 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"sync"
 	"time"
 )
 
 const (
-	processInterval = 1 * time.Second
-	maxJitter       = 100 * time.Millisecond
+	defaultProcessInterval = 1 * time.Second
+	defaultProcessJitter   = 100 * time.Millisecond
 )
 
+// ProcessFiles polls for uploaded files and processes them on a pool of workers, see WithFileConcurrency.
+// Expired uploads are deleted when polling as well, see WithUploadExpiry.
+// The returned function blocks until polling has stopped and files being processed have finished.
 func (rs *ragServer) ProcessFiles(ctx context.Context) func() {
 	var (
-		ticker = time.NewTicker(processInterval - maxJitter/2)
+		ticker = time.NewTicker(rs.pollInterval())
 		rand   = rand.New(rand.NewSource(time.Now().UnixNano()))
 		wg     = new(sync.WaitGroup)
+		pool   = newWorkerPool(rs.fileConcurrency)
 	)
 	wg.Go(func() {
 		defer ticker.Stop()
+		defer pool.wait()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				if maxJitter > 0 {
-					jitterDuration := time.Duration(rand.Int63n(int64(maxJitter)))
+				if rs.processJitter > 0 {
+					jitterDuration := time.Duration(rand.Int63n(int64(rs.processJitter)))
 					if err := jitter(ctx, jitterDuration); err != nil {
 						if !errors.Is(err, context.Canceled) {
 							rs.logger.Sugar().With("error", err).Error("random jitter failed")
@@ -38,11 +44,18 @@ func (rs *ragServer) ProcessFiles(ctx context.Context) func() {
 					}
 				}
 
-				total, err := rs.processFiles(ctx)
+				total, err := rs.processFiles(ctx, pool)
 				if err != nil {
 					rs.logger.Sugar().With("error", err).Error("error processing files")
 				} else if total > 0 {
-					rs.logger.Sugar().Infof("processed %d files", total)
+					rs.logger.Sugar().Infof("started processing %d files", total)
+				}
+
+				deleted, err := rs.deleteExpiredUploads(ctx)
+				if err != nil {
+					rs.logger.Sugar().With("error", err).Error("error deleting expired uploads")
+				} else if deleted > 0 {
+					rs.logger.Sugar().Infof("deleted %d expired uploads", deleted)
 				}
 			}
 		}
@@ -54,6 +67,16 @@ func (rs *ragServer) ProcessFiles(ctx context.Context) func() {
 	}
 }
 
+// pollInterval returns the ticker interval for polling, random jitter added to each tick
+// makes the average interval equal to the configured one.
+func (rs *ragServer) pollInterval() time.Duration {
+	interval := rs.processInterval - rs.processJitter/2
+	if interval <= 0 {
+		return rs.processInterval
+	}
+	return interval
+}
+
 func jitter(ctx context.Context, jitterDuration time.Duration) error {
 	select {
 	case <-time.After(jitterDuration):
@@ -63,44 +86,62 @@ func jitter(ctx context.Context, jitterDuration time.Duration) error {
 	}
 }
 
-const (
-	processFileTimeout = 15 * time.Minute
-)
-
-func (rs *ragServer) processFiles(ctx context.Context) (int, error) {
+// processFiles claims uploaded files for workers available in the pool and starts processing
+// them without waiting for them to finish, so a slow file doesn't block others from being picked up.
+func (rs *ragServer) processFiles(ctx context.Context, pool *workerPool) (int, error) {
 	var files []*File
 	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
-		// First let's check how many screenings are currently being processed
+		// First let's check how many files are currently being processed
 		// If there are too many, we won't pick up any new ones
 		workersAvailable, err := rs.checkFileConcurrency(ctx)
 		if err != nil {
 			return err
 		}
+		workersAvailable = min(workersAvailable, pool.available())
 		if workersAvailable == 0 {
 			return nil
 		}
 
+		now := rs.now()
+
+		// Failed files due to be retried are picked up first as they have been waiting longer
 		files, err = rs.store.ListFiles(ctx, FileFilter{
-			Status: FileStatusUploaded,
-			Lock:   true,
+			Status:            FileStatusProcessingFailed,
+			NextAttemptBefore: now,
+			Lock:              true,
 		}, rs.filePpartial(), SortParams{
 			Limit: workersAvailable,
 			Order: SortOrderAsc,
 			By:    `f."created"`,
 		})
 		if err != nil {
-			return fmt.Errorf("list files: %w", err)
+			return fmt.Errorf("list files to retry: %w", err)
+		}
+
+		if len(files) < workersAvailable {
+			uploaded, err := rs.store.ListFiles(ctx, FileFilter{
+				Status: FileStatusUploaded,
+				Lock:   true,
+			}, rs.filePpartial(), SortParams{
+				Limit: workersAvailable - len(files),
+				Order: SortOrderAsc,
+				By:    `f."created"`,
+			})
+			if err != nil {
+				return fmt.Errorf("list files: %w", err)
+			}
+			files = append(files, uploaded...)
 		}
 
 		if len(files) == 0 {
 			return nil
 		}
 
-		now := rs.now()
 		for _, aFile := range files {
-			aFile.Status = FileStatusProcessing
-			aFile.Updated = now
-			rs.logger.Sugar().With("id", aFile.ID, "status", aFile.Status).Info("state change for file")
+			if err := aFile.StartProcessing(now); err != nil {
+				return fmt.Errorf("change status: %w", err)
+			}
+			rs.logger.Sugar().With("id", aFile.ID, "status", aFile.Status, "attempt", aFile.Attempts).Info("state change for file")
 		}
 
 		return rs.store.SaveFiles(ctx, files...)
@@ -108,15 +149,16 @@ func (rs *ragServer) processFiles(ctx context.Context) (int, error) {
 		return 0, err
 	}
 
-	// TODO: process files in parallel?
 	for _, aFile := range files {
-		processCtx, cancel := context.WithTimeout(ctx, processFileTimeout)
-		defer cancel()
-		if err := rs.processFile(processCtx, aFile); err != nil {
-			if err := rs.processingFileFailed(ctx, aFile, err); err != nil {
-				rs.logger.Sugar().With("id", aFile.ID, "error", err).Error("error setting status to failed for file")
+		pool.run(func() {
+			processCtx, cancel := context.WithTimeout(ctx, rs.fileTimeout)
+			defer cancel()
+			if err := rs.processFile(processCtx, aFile); err != nil {
+				if err := rs.processingFileFailed(ctx, aFile, err); err != nil {
+					rs.logger.Sugar().With("id", aFile.ID, "error", err).Error("error setting status to failed for file")
+				}
 			}
-		}
+		})
 	}
 
 	// Now let's find files that have been processing for too long and mark them as failed
@@ -125,14 +167,14 @@ func (rs *ragServer) processFiles(ctx context.Context) (int, error) {
 
 		files, err := rs.store.ListFiles(ctx, FileFilter{
 			Status:            FileStatusProcessing,
-			LastUpdatedBefore: now.Add(-processFileTimeout - time.Minute),
+			LastUpdatedBefore: now.Add(-rs.fileTimeout - time.Minute),
 		}, rs.filePpartial(), SortParams{})
 		if err != nil {
 			return fmt.Errorf("list files to fail: %w", err)
 		}
 
 		for _, aFile := range files {
-			if err := aFile.CompleteWithStatus(FileStatusProcessingFailed, "timed out", now); err != nil {
+			if err := rs.failFile(aFile, Retryable(errors.New("timed out")), now); err != nil {
 				return fmt.Errorf("change status: %w", err)
 			}
 			rs.logger.Sugar().With("id", aFile.ID, "status", aFile.Status).Info("state change for file")
@@ -150,7 +192,10 @@ func (rs *ragServer) processFiles(ctx context.Context) (int, error) {
 	return len(files), nil
 }
 
-const maxConcurrentFiles = 10
+const (
+	defaultFileConcurrency = 10
+	defaultFileTimeout     = 15 * time.Minute
+)
 
 // checkFileConcurrency checks how many files are currently being processed,
 // it is used to enforce a maximum number of concurrent file processing jobs.
@@ -161,14 +206,32 @@ func (rs *ragServer) checkFileConcurrency(ctx context.Context) (int, error) {
 	if err != nil {
 		return 0, fmt.Errorf("count files being processed: %w", err)
 	}
-	if len(processing) >= maxConcurrentFiles {
+	if len(processing) >= rs.fileConcurrency {
 		rs.logger.Sugar().Infof("max concurrent files reached: %d", len(processing))
 		return 0, nil
 	}
-	return maxConcurrentFiles - len(processing), nil
+	return rs.fileConcurrency - len(processing), nil
 }
 
 func (rs *ragServer) processFile(ctx context.Context, aFile *File) error {
+	// Documents of a previous attempt could have been partially saved before it failed
+	if aFile.Attempts > 1 {
+		if err := rs.retriever.DeleteFileDocuments(ctx, aFile.ID); err != nil {
+			return fmt.Errorf("error deleting documents of previous attempt: %w", err)
+		}
+	}
+
+	// Reprocessed files are extracted again, documents of other files could be stale as well
+	if !aFile.Reprocessing {
+		reused, err := rs.reuseProcessedFile(ctx, aFile)
+		if err != nil {
+			return err
+		}
+		if reused {
+			return rs.processingFileSucceeded(ctx, aFile)
+		}
+	}
+
 	content, err := rs.filestorage.Read(aFile.Hash)
 	if err != nil {
 		return fmt.Errorf("opening file: %w", err)
@@ -181,52 +244,213 @@ func (rs *ragServer) processFile(ctx context.Context, aFile *File) error {
 
 	rs.logger.Sugar().With("id", aFile.ID, "hash", aFile.Hash).Info("processing file")
 
-	switch aFile.ContentType {
-	case "application/pdf":
-		var err error
-		documents, err := rs.extractor.Extract(ctx, aFile.FileName, content, rs.relevantTopics)
-		if err != nil {
-			return fmt.Errorf("error processing PDF file: %w", err)
-		}
-		for i := 0; i < len(documents); i++ {
-			documents[i].FileID = aFile.ID
-			documents[i] = documents[i].Sanitize()
-		}
-		aFile.Documents = documents
-	case "image/jpeg", "image/png":
-		// client := gosseract.NewClient()
-		// defer client.Close()
+	extractor, ok := rs.extractors[aFile.ContentType]
+	if !ok {
+		return fmt.Errorf("no extractor for content type: %s", aFile.ContentType)
+	}
+
+	if err := rs.startFileStage(ctx, aFile, FileStageExtracting); err != nil {
+		return err
+	}
+
+	documents, err := extractor.Extract(ctx, aFile.FileName, content)
+	if err != nil {
+		return fmt.Errorf("error extracting documents: %w", err)
+	}
+	aFile.Progress.Pages = countPages(documents)
+	rs.saveExtractedDocuments(aFile.Hash, documents)
 
-		// if err := client.SetImageFromBytes(fileBytes); err != nil {
-		// 	http.Error(w, "file processing error", http.StatusInternalServerError)
-		// }
+	// Language is detected before chunking so sentences are split with the matching training data
+	aFile.Language = detectDocumentLanguages(documents)
+	rs.logger.Sugar().With("id", aFile.ID, "language", aFile.Language).Info("detected language of file")
 
-		// text, err := client.Text()
-		// if err != nil {
-		// 	http.Error(w, "file processing error", http.StatusInternalServerError)
-		// 	return
-		// }
+	// Chunker could have been reconfigured since the file was uploaded
+	aFile.Chunker = rs.chunker.Name()
+	rs.logger.Sugar().With("chunker", aFile.Chunker).Infof("chunking documents: %d", len(documents))
 
-		return fmt.Errorf("image file processing not implemented yet")
+	documents, err = rs.chunker.Chunk(documents)
+	if err != nil {
+		return fmt.Errorf("error chunking documents: %w", err)
+	}
+	documents, err = rs.relevantDocuments(ctx, aFile, documents)
+	if err != nil {
+		return err
 	}
 
+	for i := 0; i < len(documents); i++ {
+		documents[i].FileID = aFile.ID
+		documents[i].Metadata = rs.documentMetadata(aFile)
+		documents[i] = documents[i].Sanitize()
+	}
+	aFile.Documents = documents
+	aFile.Progress.Documents = len(documents)
+
 	rs.logger.Sugar().Infof("extracted documents: %d", len(aFile.Documents))
 
-	// Use the batch embedding API to embed all documents at once.
-	vectors, err := rs.embedder.EmbedDocuments(ctx, aFile.Documents)
+	if err := rs.startFileStage(ctx, aFile, FileStageEmbedding); err != nil {
+		return err
+	}
+
+	vectors, err := rs.embedFileDocuments(ctx, aFile)
 	if err != nil {
-		return fmt.Errorf("error generating vectors: %v", err)
+		return err
 	}
 
 	rs.logger.Sugar().Infof("generated vectors: %d", len(vectors))
 
+	if err := rs.startFileStage(ctx, aFile, FileStageIndexing); err != nil {
+		return err
+	}
+
 	if err := rs.retriever.SaveDocuments(ctx, aFile.Documents, vectors); err != nil {
-		return fmt.Errorf("saving embeddings: %v", err)
+		return fmt.Errorf("saving embeddings: %w", err)
 	}
 
 	return rs.processingFileSucceeded(ctx, aFile)
 }
 
+const embedProgressBatchSize = 100
+
+// embedFileDocuments embeds documents of the file and saves progress as they are embedded. Embedders
+// which split documents into batches themselves, such as BatchEmbedder, get all documents at once so
+// their batches can be embedded concurrently, other embedders get embedProgressBatchSize at a time.
+func (rs *ragServer) embedFileDocuments(ctx context.Context, aFile *File) ([]Vector, error) {
+	if !batchesDocuments(rs.embedder) {
+		vectors := make([]Vector, 0, len(aFile.Documents))
+		for batch := range slices.Chunk(aFile.Documents, embedProgressBatchSize) {
+			batchVectors, err := rs.embedder.EmbedDocuments(ctx, batch)
+			if err != nil {
+				return nil, fmt.Errorf("error generating vectors: %w", err)
+			}
+			vectors = append(vectors, batchVectors...)
+
+			aFile.Progress.DocumentsEmbedded = len(vectors)
+			if err := rs.saveFileProgress(ctx, aFile); err != nil {
+				return nil, err
+			}
+		}
+		return vectors, nil
+	}
+
+	var (
+		mu          sync.Mutex
+		progressErr error
+	)
+	progressCtx := withEmbedProgress(ctx, func(embedded int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		aFile.Progress.DocumentsEmbedded += embedded
+		if progressErr == nil {
+			progressErr = rs.saveFileProgress(ctx, aFile)
+		}
+	})
+
+	vectors, err := rs.embedder.EmbedDocuments(progressCtx, aFile.Documents)
+	if err != nil {
+		return nil, fmt.Errorf("error generating vectors: %w", err)
+	}
+	if progressErr != nil {
+		return nil, progressErr
+	}
+
+	// Embedders may report less than they embedded, for example when there is nothing to embed
+	if aFile.Progress.DocumentsEmbedded != len(vectors) {
+		aFile.Progress.DocumentsEmbedded = len(vectors)
+		if err := rs.saveFileProgress(ctx, aFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return vectors, nil
+}
+
+// startFileStage moves a file to the next stage of processing and saves it along with progress so far.
+func (rs *ragServer) startFileStage(ctx context.Context, aFile *File, stage FileStage) error {
+	if err := aFile.StartStage(stage, rs.now()); err != nil {
+		return err
+	}
+	rs.logger.Sugar().With("id", aFile.ID, "stage", aFile.Stage).Info("stage change for file")
+
+	return rs.saveFileProgress(ctx, aFile)
+}
+
+func (rs *ragServer) saveFileProgress(ctx context.Context, aFile *File) error {
+	aFile.Updated = rs.now()
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		return rs.store.SaveFiles(ctx, aFile)
+	}); err != nil {
+		return fmt.Errorf("error saving file progress: %w", err)
+	}
+	return nil
+}
+
+// countPages returns the number of distinct pages documents were extracted from.
+func countPages(documents []Document) int {
+	pages := map[int]struct{}{}
+	for _, aDocument := range documents {
+		pages[aDocument.Page] = struct{}{}
+	}
+	return len(pages)
+}
+
+// maxReuseCandidates limits how many processed files with the same hash are checked for matching topics.
+const maxReuseCandidates = 10
+
+// reuseProcessedFile copies documents and vectors of an already processed file with the same hash
+// instead of extracting and embedding the same contents again. Only files processed with the current
+// embedder, retriever and chunker and with the same topics are reused, documents would be different otherwise.
+func (rs *ragServer) reuseProcessedFile(ctx context.Context, aFile *File) (bool, error) {
+	var processed []*File
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		processed, err = rs.store.ListFiles(ctx, FileFilter{
+			Hash:    aFile.Hash,
+			Chunker: rs.chunker.Name(),
+			Status:  FileStatusProcessedSuccessfully,
+		}, rs.filePpartial(), SortParams{
+			Limit: maxReuseCandidates,
+			Order: SortOrderDesc,
+			By:    `f."updated"`,
+		})
+		return err
+	}); err != nil {
+		return false, fmt.Errorf("error listing processed files with the same hash: %w", err)
+	}
+
+	var source *File
+	for _, candidate := range processed {
+		if aFile.Topics.sameTopics(candidate.Topics) {
+			source = candidate
+			break
+		}
+	}
+	if source == nil {
+		return false, nil
+	}
+
+	rs.logger.Sugar().With("id", aFile.ID, "hash", aFile.Hash, "source_id", source.ID).Info("reusing documents of processed file")
+
+	aFile.Progress = source.Progress
+	aFile.Language = source.Language
+	if err := rs.startFileStage(ctx, aFile, FileStageIndexing); err != nil {
+		return false, err
+	}
+
+	if err := rs.retriever.CopyFileDocuments(ctx, source.ID, aFile.ID); err != nil {
+		return false, fmt.Errorf("error copying documents: %w", err)
+	}
+	// Copied documents have indexed metadata of the source file
+	if len(rs.indexedMetadata) > 0 {
+		if err := rs.retriever.UpdateFileMetadata(ctx, aFile.ID, rs.documentMetadata(aFile)); err != nil {
+			return false, fmt.Errorf("error updating metadata of copied documents: %w", err)
+		}
+	}
+	aFile.Chunker = source.Chunker
+
+	return true, nil
+}
+
 func (rs *ragServer) processingFileSucceeded(ctx context.Context, aFile *File) error {
 	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
 		if err := aFile.CompleteWithStatus(FileStatusProcessedSuccessfully, "", rs.now()); err != nil {
@@ -245,7 +469,7 @@ func (rs *ragServer) processingFileSucceeded(ctx context.Context, aFile *File) e
 
 func (rs *ragServer) processingFileFailed(ctx context.Context, aFile *File, perr error) error {
 	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
-		if err := aFile.CompleteWithStatus(FileStatusProcessingFailed, perr.Error(), rs.now()); err != nil {
+		if err := rs.failFile(aFile, perr, rs.now()); err != nil {
 			return fmt.Errorf("change status: %w", err)
 		}
 		rs.logger.Sugar().With("id", aFile.ID, "status", aFile.Status).Info("state change for file")
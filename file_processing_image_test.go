@@ -0,0 +1,48 @@
+package ragserver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver"
+	"github.com/RichardKnop/ragserver/adapter/image"
+	"github.com/RichardKnop/ragserver/ragservertest"
+)
+
+func TestProcessFile_Image(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now      = time.Now().UTC()
+		contents = []byte("scanned certificate")
+		ocr      = ragservertest.NewOCR("")
+		aFile    = &ragserver.File{
+			ID:          ragserver.NewFileID(),
+			FileName:    "certificate.png",
+			ContentType: ragserver.ContentTypePNG,
+			Hash:        "certificate-hash",
+			Chunker:     "paragraph",
+			Embedder:    "fake-embedder",
+			Retriever:   "fake-retriever",
+			Status:      ragserver.FileStatusUploaded,
+			Created:     now,
+			Updated:     now,
+		}
+	)
+	ocr.Add(contents, "This certifies that the company\nhas verified its scope 1 emissions.\n\nIssued in 2023.")
+	require.NoError(t, aFile.StartProcessing(now))
+
+	documents, err := ragserver.ProcessFileWith(context.Background(), aFile, contents, image.New(ocr))
+	require.NoError(t, err)
+
+	assert.Equal(t, ragserver.FileStatusProcessedSuccessfully, aFile.Status)
+	assert.Equal(t, ragserver.FileProgress{Pages: 1, Documents: 2, DocumentsEmbedded: 2}, aFile.Progress)
+	require.Len(t, documents, 2)
+	assert.Equal(t, "This certifies that the company has verified its scope 1 emissions.", documents[0].Content)
+	assert.Equal(t, "Issued in 2023.", documents[1].Content)
+	assert.Equal(t, 1, documents[0].Page)
+}
@@ -0,0 +1,166 @@
+package ragserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRagServer_ProcessFile(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now       = time.Now().UTC()
+		extracted = []Document{
+			{Page: 1, Content: "Scope 1 emissions were 100 tCO2e."},
+			{Page: 2, Content: "Scope 2 emissions were 200 tCO2e."},
+		}
+	)
+
+	newFile := func() *File {
+		aFile := &File{
+			ID:          NewFileID(),
+			FileName:    "report.pdf",
+			ContentType: ContentTypePDF,
+			Hash:        "same-hash",
+			Chunker:     "paragraph",
+			Embedder:    "fake-embedder",
+			Retriever:   "fake-retriever",
+			Status:      FileStatusUploaded,
+			Created:     now,
+			Updated:     now,
+		}
+		require.NoError(t, aFile.StartProcessing(now))
+		return aFile
+	}
+
+	t.Run("documents of a processed file with the same hash are reused", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			source = &File{
+				ID:       NewFileID(),
+				Hash:     "same-hash",
+				Chunker:  "paragraph",
+				Status:   FileStatusProcessedSuccessfully,
+				Language: LanguageEnglish,
+				Progress: FileProgress{Pages: 2, Documents: 2, DocumentsEmbedded: 2},
+			}
+			aFile     = newFile()
+			retriever = newFakeRetriever(
+				Document{FileID: source.ID, Page: 1, Content: "Scope 1 emissions were 100 tCO2e."},
+				Document{FileID: source.ID, Page: 2, Content: "Scope 2 emissions were 200 tCO2e."},
+			)
+			rs = newTestRagServer(newFakeStore(source, aFile))
+		)
+		rs.retriever = retriever
+
+		require.NoError(t, rs.processFile(context.Background(), aFile))
+
+		assert.Equal(t, FileStatusProcessedSuccessfully, aFile.Status)
+		assert.Equal(t, FileStageIndexing, aFile.Stage)
+		assert.Equal(t, source.Progress, aFile.Progress)
+		assert.Equal(t, LanguageEnglish, aFile.Language)
+		assert.Len(t, retriever.fileDocuments(aFile.ID), 2)
+	})
+
+	t.Run("reprocessed files are extracted even if a processed file has the same hash", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			source = &File{
+				ID:      NewFileID(),
+				Hash:    "same-hash",
+				Chunker: "paragraph",
+				Status:  FileStatusProcessedSuccessfully,
+			}
+			aFile     = newFile()
+			retriever = newFakeRetriever(
+				Document{FileID: source.ID, Page: 1, Content: "Stale document."},
+			)
+			rs = newTestRagServer(newFakeStore(source, aFile))
+		)
+		aFile.Reprocessing = true
+		rs.retriever = retriever
+		rs.extractors[ContentTypePDF] = fakeExtractor{documents: extracted}
+		rs.filestorage = fakeFileStorage{contents: map[string][]byte{"same-hash": []byte("%PDF")}}
+
+		require.NoError(t, rs.processFile(context.Background(), aFile))
+
+		assert.Equal(t, FileStatusProcessedSuccessfully, aFile.Status)
+		assert.False(t, aFile.Reprocessing)
+		assert.Equal(t, FileProgress{Pages: 2, Documents: 2, DocumentsEmbedded: 2}, aFile.Progress)
+		documents := retriever.fileDocuments(aFile.ID)
+		require.Len(t, documents, 2)
+		assert.Equal(t, extracted[0].Content, documents[0].Content)
+	})
+
+	t.Run("files without a processed file with the same hash are extracted", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			other = &File{
+				ID:      NewFileID(),
+				Hash:    "same-hash",
+				Chunker: "sentence",
+				Status:  FileStatusProcessedSuccessfully,
+			}
+			aFile     = newFile()
+			retriever = newFakeRetriever()
+			rs        = newTestRagServer(newFakeStore(other, aFile))
+		)
+		rs.retriever = retriever
+		rs.extractors[ContentTypePDF] = fakeExtractor{documents: extracted}
+		rs.filestorage = fakeFileStorage{contents: map[string][]byte{"same-hash": []byte("%PDF")}}
+
+		require.NoError(t, rs.processFile(context.Background(), aFile))
+
+		assert.Equal(t, FileStatusProcessedSuccessfully, aFile.Status)
+		assert.Equal(t, FileProgress{Pages: 2, Documents: 2, DocumentsEmbedded: 2}, aFile.Progress)
+		assert.Len(t, retriever.fileDocuments(aFile.ID), 2)
+
+		saved, err := rs.findExtractedDocuments(aFile.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, extracted, saved)
+	})
+
+	t.Run("progress is saved as batches of documents are embedded", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			aFile     = newFile()
+			retriever = newFakeRetriever()
+			store     = &progressStore{fakeStore: newFakeStore(aFile)}
+			rs        = newTestRagServer(store)
+		)
+		rs.retriever = retriever
+		rs.embedder = NewBatchEmbedder(fakeEmbedder{}, WithEmbedBatchSize(1))
+		rs.extractors[ContentTypePDF] = fakeExtractor{documents: extracted}
+		rs.filestorage = fakeFileStorage{contents: map[string][]byte{"same-hash": []byte("%PDF")}}
+
+		require.NoError(t, rs.processFile(context.Background(), aFile))
+
+		assert.Equal(t, FileStatusProcessedSuccessfully, aFile.Status)
+		assert.Equal(t, FileProgress{Pages: 2, Documents: 2, DocumentsEmbedded: 2}, aFile.Progress)
+		assert.Equal(t, []int{1, 2}, store.embedded)
+		assert.Len(t, retriever.fileDocuments(aFile.ID), 2)
+	})
+}
+
+// progressStore records embedding progress of files as they are saved.
+type progressStore struct {
+	*fakeStore
+	embedded []int
+}
+
+func (s *progressStore) SaveFiles(ctx context.Context, files ...*File) error {
+	for _, aFile := range files {
+		if aFile.Stage == FileStageEmbedding && aFile.Progress.DocumentsEmbedded > 0 {
+			s.embedded = append(s.embedded, aFile.Progress.DocumentsEmbedded)
+		}
+	}
+	return s.fakeStore.SaveFiles(ctx, files...)
+}
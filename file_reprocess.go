@@ -0,0 +1,129 @@
+package ragserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+// ErrUnknownRetriever is returned when reprocessing files stored by a retriever which is neither
+// the current one nor registered with WithPreviousRetrievers, their documents couldn't be deleted.
+var ErrUnknownRetriever = errors.New("unknown retriever")
+
+// ReprocessFile deletes documents of a processed file from the retriever and moves the file back
+// to the uploaded state, it is then extracted again from file storage and embedded with the current
+// embedder and retriever. Files processed with a different embedder or retriever can be reprocessed,
+// documents stored by a different retriever are deleted from it, see WithPreviousRetrievers.
+func (rs *ragServer) ReprocessFile(ctx context.Context, principal authz.Principal, id FileID) (*File, error) {
+	rs.logger.Sugar().With("id", id).Info("reprocessing file")
+
+	var aFile *File
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		// Files processed with a different embedder or retriever are not visible with the file partial
+		aFile, err = rs.store.FindFile(ctx, id, authz.NilPartial)
+		if err != nil {
+			return err
+		}
+
+		return rs.reprocessFiles(ctx, aFile)
+	}); err != nil {
+		return nil, err
+	}
+
+	return aFile, nil
+}
+
+const reindexBatchSize = 100
+
+// ReindexFiles reprocesses all processed files which were embedded with a different embedder
+// or stored by a different retriever than the current ones, see ReprocessFile. When all is true,
+// files processed with the current embedder and retriever are reprocessed as well, for example
+// to extract them again after changing an extractor or chunker.
+func (rs *ragServer) ReindexFiles(ctx context.Context, principal authz.Principal, all bool) ([]*File, error) {
+	rs.logger.Sugar().With("all", all).Info("reindexing files")
+
+	filter := FileFilter{
+		// Files reprocessed quickly could be listed again otherwise
+		LastUpdatedBefore: rs.now(),
+		Lock:              true,
+	}
+	if !all {
+		filter.NotEmbedder = rs.embedder.Name()
+		filter.NotRetriever = rs.retriever.Name()
+	}
+
+	var reindexed []*File
+	for _, status := range []FileStatus{FileStatusProcessedSuccessfully, FileStatusProcessingFailed} {
+		filter.Status = status
+
+		// Reprocessed files move to the uploaded state, so listing again returns the next batch
+		for {
+			var files []*File
+			if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+				var err error
+				files, err = rs.store.ListFiles(ctx, filter, authz.NilPartial, SortParams{
+					Limit: reindexBatchSize,
+					Order: SortOrderAsc,
+					By:    `f."created"`,
+				})
+				if err != nil {
+					return fmt.Errorf("list files: %w", err)
+				}
+
+				return rs.reprocessFiles(ctx, files...)
+			}); err != nil {
+				return nil, err
+			}
+
+			reindexed = append(reindexed, files...)
+
+			if len(files) < reindexBatchSize {
+				break
+			}
+		}
+	}
+
+	rs.logger.Sugar().Infof("reindexed %d files", len(reindexed))
+
+	return reindexed, nil
+}
+
+func (rs *ragServer) reprocessFiles(ctx context.Context, files ...*File) error {
+	now := rs.now()
+	for _, aFile := range files {
+		previousRetriever, err := rs.fileRetriever(aFile)
+		if err != nil {
+			return err
+		}
+		if err := aFile.Reprocess(rs.embedder.Name(), rs.retriever.Name(), now); err != nil {
+			return err
+		}
+		rs.logger.Sugar().With("id", aFile.ID, "status", aFile.Status).Info("state change for file")
+
+		if err := previousRetriever.DeleteFileDocuments(ctx, aFile.ID); err != nil {
+			return fmt.Errorf("error deleting file documents from retriever %s: %w", previousRetriever.Name(), err)
+		}
+	}
+
+	if err := rs.store.SaveFiles(ctx, files...); err != nil {
+		return fmt.Errorf("save files: %w", err)
+	}
+
+	return nil
+}
+
+// fileRetriever returns the retriever documents of the file were stored by.
+func (rs *ragServer) fileRetriever(aFile *File) (Retriever, error) {
+	if aFile.Retriever == "" || aFile.Retriever == rs.retriever.Name() {
+		return rs.retriever, nil
+	}
+	retriever, ok := rs.previousRetrievers[aFile.Retriever]
+	if !ok {
+		return nil, fmt.Errorf("%w: documents of file %s are stored by %s, it has to be configured to reprocess the file", ErrUnknownRetriever, aFile.ID, aFile.Retriever)
+	}
+	return retriever, nil
+}
@@ -0,0 +1,72 @@
+package ragserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+func TestRagServer_ReprocessFile(t *testing.T) {
+	t.Parallel()
+
+	principal := authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+
+	tests := []struct {
+		name               string
+		retriever          string // retriever the file was stored by
+		previousRetrievers bool
+		expectedErr        error
+	}{
+		{"documents are deleted from the current retriever", "fake-retriever", false, nil},
+		{"documents are deleted from a previous retriever", "old-retriever", true, nil},
+		{"files stored by an unknown retriever can't be reprocessed", "old-retriever", false, ErrUnknownRetriever},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				aFile = &File{
+					ID:        NewFileID(),
+					Embedder:  "fake-embedder",
+					Retriever: tt.retriever,
+					Status:    FileStatusProcessedSuccessfully,
+					Attempts:  2,
+				}
+				current  = newFakeRetriever(Document{FileID: aFile.ID, Content: "Current."})
+				previous = newFakeRetriever(Document{FileID: aFile.ID, Content: "Previous."})
+				store    = newFakeStore(aFile)
+				rs       = newTestRagServer(store)
+			)
+			previous.name = "old-retriever"
+			rs.retriever = current
+			if tt.previousRetrievers {
+				rs.previousRetrievers[previous.Name()] = previous
+			}
+
+			reprocessed, err := rs.ReprocessFile(context.Background(), principal, aFile.ID)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				assert.NotEmpty(t, previous.fileDocuments(aFile.ID))
+				assert.Equal(t, FileStatusProcessedSuccessfully, store.files[aFile.ID].Status)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, FileStatusUploaded, reprocessed.Status)
+			assert.Equal(t, "fake-retriever", reprocessed.Retriever)
+			assert.Equal(t, 1, reprocessed.Run)
+			if tt.previousRetrievers {
+				assert.Empty(t, previous.fileDocuments(aFile.ID))
+			} else {
+				assert.Empty(t, current.fileDocuments(aFile.ID))
+			}
+		})
+	}
+}
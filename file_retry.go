@@ -0,0 +1,83 @@
+package ragserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxFileAttempts  = 5
+	defaultFileRetryBackoff = 1 * time.Minute
+	maxFileRetryBackoff     = 1 * time.Hour
+)
+
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable marks an error as transient, for example when a service is temporarily unavailable
+// or rate limits requests. Files which fail to process with a retryable error are processed again.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err: err}
+}
+
+// IsRetryable returns true for errors marked with Retryable as well as timeouts and network errors,
+// other errors are permanent and processing the same file again would fail the same way.
+func IsRetryable(err error) bool {
+	if errors.As(err, new(retryableError)) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsRetryableStatusCode returns true for HTTP status codes of responses which could succeed
+// if the request is sent again later.
+func IsRetryableStatusCode(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryBackoff returns how long to wait before the next attempt, doubling the base
+// backoff with each attempt up to maxFileRetryBackoff.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxFileRetryBackoff {
+			return maxFileRetryBackoff
+		}
+	}
+	return min(backoff, maxFileRetryBackoff)
+}
+
+// failFile moves a file being processed to the failed state. Files which failed with a retryable
+// error are scheduled to be processed again unless they have run out of attempts.
+func (rs *ragServer) failFile(aFile *File, perr error, now time.Time) error {
+	if err := aFile.CompleteWithStatus(FileStatusProcessingFailed, perr.Error(), now); err != nil {
+		return err
+	}
+
+	if IsRetryable(perr) && aFile.Attempts < rs.maxFileAttempts {
+		aFile.NextAttempt = now.Add(retryBackoff(rs.fileRetryBackoff, aFile.Attempts))
+		rs.logger.Sugar().With("id", aFile.ID, "attempts", aFile.Attempts, "next_attempt", aFile.NextAttempt).Info("scheduled retry for file")
+	}
+
+	return nil
+}
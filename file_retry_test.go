@@ -0,0 +1,81 @@
+package ragserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "marked as retryable",
+			err:      fmt.Errorf("error generating vectors: %w", Retryable(errors.New("503 Service Unavailable"))),
+			expected: true,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("error extracting documents: %w", context.DeadlineExceeded),
+			expected: true,
+		},
+		{
+			name: "network error",
+			err: &url.Error{
+				Op:  "Post",
+				URL: "http://localhost:5060",
+				Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			},
+			expected: true,
+		},
+		{
+			name:     "permanent error",
+			err:      fmt.Errorf("no extractor for content type: %s", "image/gif"),
+			expected: false,
+		},
+		{
+			name:     "cancelled",
+			err:      context.Canceled,
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: time.Minute},
+		{attempt: 2, expected: 2 * time.Minute},
+		{attempt: 3, expected: 4 * time.Minute},
+		{attempt: 6, expected: 32 * time.Minute},
+		{attempt: 7, expected: time.Hour},
+		{attempt: 100, expected: time.Hour},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tc.attempt), func(t *testing.T) {
+			assert.Equal(t, tc.expected, retryBackoff(time.Minute, tc.attempt))
+		})
+	}
+}
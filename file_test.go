@@ -1,11 +1,17 @@
 package ragserver
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
 )
 
 func TestFile_CompleteWithStatus(t *testing.T) {
@@ -53,7 +59,8 @@ func TestFile_CompleteWithStatus(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			f := &File{
-				Status: tc.from,
+				Status:       tc.from,
+				Reprocessing: true,
 			}
 			err := f.CompleteWithStatus(tc.to, tc.message, updatedAt)
 			if tc.wantErr {
@@ -64,7 +71,338 @@ func TestFile_CompleteWithStatus(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, tc.to, f.Status)
 			assert.Equal(t, tc.message, f.StatusMessage)
+			// Failed files are retried without reusing documents of other files too
+			assert.Equal(t, tc.to == FileStatusProcessingFailed, f.Reprocessing)
 			assert.Equal(t, updatedAt, f.Updated)
 		})
 	}
 }
+
+func TestFile_Reprocess(t *testing.T) {
+	t.Parallel()
+
+	updatedAt := time.Now().UTC()
+
+	tests := []struct {
+		name    string
+		from    FileStatus
+		wantErr bool
+	}{
+		{
+			name:    "processed successfully",
+			from:    FileStatusProcessedSuccessfully,
+			wantErr: false,
+		},
+		{
+			name:    "processing failed",
+			from:    FileStatusProcessingFailed,
+			wantErr: false,
+		},
+		{
+			name:    "cannot reprocess uploaded file",
+			from:    FileStatusUploaded,
+			wantErr: true,
+		},
+		{
+			name:    "cannot reprocess file being processed",
+			from:    FileStatusProcessing,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &File{
+				Embedder:      "old-embedder",
+				Retriever:     "old-retriever",
+				Status:        tc.from,
+				StatusMessage: "some error message",
+				Attempts:      3,
+				Run:           1,
+			}
+			err := f.Reprocess("embedder", "retriever", updatedAt)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidFileStatus)
+				assert.Equal(t, tc.from, f.Status)
+				assert.Equal(t, "old-embedder", f.Embedder)
+				assert.Equal(t, 1, f.Run)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, FileStatusUploaded, f.Status)
+			assert.Empty(t, f.StatusMessage)
+			assert.Equal(t, "embedder", f.Embedder)
+			assert.Equal(t, "retriever", f.Retriever)
+			assert.Equal(t, 0, f.Attempts)
+			assert.Equal(t, 2, f.Run)
+			assert.True(t, f.Reprocessing)
+			assert.Equal(t, updatedAt, f.Updated)
+		})
+	}
+}
+
+func TestFile_StartProcessing(t *testing.T) {
+	t.Parallel()
+
+	updatedAt := time.Now().UTC()
+
+	tests := []struct {
+		name    string
+		from    FileStatus
+		wantErr bool
+	}{
+		{
+			name:    "uploaded",
+			from:    FileStatusUploaded,
+			wantErr: false,
+		},
+		{
+			name:    "retry processing failed",
+			from:    FileStatusProcessingFailed,
+			wantErr: false,
+		},
+		{
+			name:    "cannot start processing file being processed",
+			from:    FileStatusProcessing,
+			wantErr: true,
+		},
+		{
+			name:    "cannot start processing processed file",
+			from:    FileStatusProcessedSuccessfully,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &File{
+				Status:        tc.from,
+				StatusMessage: "some error message",
+				Attempts:      1,
+				NextAttempt:   updatedAt.Add(-time.Minute),
+				Stage:         FileStageEmbedding,
+				Progress:      FileProgress{Pages: 3, Documents: 12, DocumentsEmbedded: 5},
+			}
+			err := f.StartProcessing(updatedAt)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidFileStatus)
+				assert.Equal(t, 1, f.Attempts)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, FileStatusProcessing, f.Status)
+			assert.Empty(t, f.StatusMessage)
+			assert.Equal(t, 2, f.Attempts)
+			assert.True(t, f.NextAttempt.IsZero())
+			assert.Equal(t, FileStageReading, f.Stage)
+			assert.Equal(t, FileProgress{}, f.Progress)
+			assert.Equal(t, updatedAt, f.Updated)
+		})
+	}
+}
+
+func TestFile_StartStage(t *testing.T) {
+	t.Parallel()
+
+	updatedAt := time.Now().UTC()
+
+	tests := []struct {
+		name    string
+		from    FileStatus
+		wantErr bool
+	}{
+		{
+			name:    "file being processed",
+			from:    FileStatusProcessing,
+			wantErr: false,
+		},
+		{
+			name:    "cannot start stage of uploaded file",
+			from:    FileStatusUploaded,
+			wantErr: true,
+		},
+		{
+			name:    "cannot start stage of processed file",
+			from:    FileStatusProcessedSuccessfully,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &File{
+				Status: tc.from,
+				Stage:  FileStageExtracting,
+			}
+			err := f.StartStage(FileStageEmbedding, updatedAt)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidFileStatus)
+				assert.Equal(t, FileStageExtracting, f.Stage)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, FileStageEmbedding, f.Stage)
+			assert.Equal(t, updatedAt, f.Updated)
+		})
+	}
+}
+
+func TestRagServer_UpdateFile(t *testing.T) {
+	t.Parallel()
+
+	var (
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+		update    = FileUpdate{Metadata: Metadata{"company": "Acme", "reporting_year": "2024"}}
+	)
+
+	newFile := func(status FileStatus) *File {
+		return &File{
+			ID:       NewFileID(),
+			Status:   status,
+			Metadata: Metadata{"company": "Old name"},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		status           FileStatus
+		retrieverErrs    []error
+		expectedMetadata Metadata // metadata of documents, nil if they are not updated
+		wantErr          bool
+	}{
+		{
+			name:             "documents of processed file are updated",
+			status:           FileStatusProcessedSuccessfully,
+			expectedMetadata: Metadata{"company": "Acme"},
+		},
+		{
+			name:             "failed update of documents is retried",
+			status:           FileStatusProcessedSuccessfully,
+			retrieverErrs:    []error{errors.New("connection reset")},
+			expectedMetadata: Metadata{"company": "Acme"},
+		},
+		{
+			name:   "file is saved even if documents can't be updated",
+			status: FileStatusProcessedSuccessfully,
+			retrieverErrs: []error{
+				errors.New("connection reset"),
+				errors.New("connection reset"),
+				errors.New("connection reset"),
+			},
+			wantErr: true,
+		},
+		{
+			name:   "documents of failed file are not updated",
+			status: FileStatusProcessingFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				aFile     = newFile(tt.status)
+				store     = newFakeStore(aFile)
+				retriever = newFakeRetriever()
+				rs        = newTestRagServer(store)
+			)
+			retriever.metadataErrs = tt.retrieverErrs
+			rs.retriever = retriever
+			rs.indexedMetadata = []MetadataField{{Key: "company", Type: MetadataFieldTag}}
+
+			updated, err := rs.UpdateFile(context.Background(), principal, aFile.ID, update)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, update.Metadata, updated.Metadata)
+			}
+
+			saved, err := store.FindFile(context.Background(), aFile.ID, authz.NilPartial)
+			require.NoError(t, err)
+			assert.Equal(t, update.Metadata, saved.Metadata)
+
+			metadata, ok := retriever.metadata[aFile.ID]
+			assert.Equal(t, tt.expectedMetadata != nil, ok)
+			assert.Equal(t, tt.expectedMetadata, metadata)
+		})
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		expected string
+	}{
+		{
+			name:     "pdf",
+			fileName: "report.pdf",
+			data:     "%PDF-1.7\n",
+			expected: "application/pdf",
+		},
+		{
+			name:     "plain text without charset parameter",
+			fileName: "notes.txt",
+			data:     "Some notes.",
+			expected: "text/plain",
+		},
+		{
+			name:     "markdown by extension",
+			fileName: "notes.md",
+			data:     "# Notes\n\nSome notes.",
+			expected: "text/markdown",
+		},
+		{
+			name:     "html by content",
+			fileName: "page",
+			data:     "<!DOCTYPE html><html><body>Hello</body></html>",
+			expected: "text/html",
+		},
+		{
+			name:     "html fragment by extension",
+			fileName: "fragment.HTM",
+			data:     "Hello <b>world</b>",
+			expected: "text/html",
+		},
+		{
+			name:     "word document by extension",
+			fileName: "report.docx",
+			data:     "PK\x03\x04",
+			expected: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		},
+		{
+			name:     "excel workbook by extension",
+			fileName: "report.XLSX",
+			data:     "PK\x03\x04",
+			expected: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		},
+		{
+			name:     "powerpoint presentation by extension",
+			fileName: "report.pptx",
+			data:     "PK\x03\x04",
+			expected: "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		},
+		{
+			name:     "zip without office extension",
+			fileName: "archive.zip",
+			data:     "PK\x03\x04",
+			expected: "application/zip",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, err := detectContentType(tc.fileName, strings.NewReader(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, contentType)
+		})
+	}
+}
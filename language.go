@@ -0,0 +1,174 @@
+package ragserver
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/neurosnap/sentences"
+)
+
+// Language is an ISO 639-1 code of a language documents are written in.
+type Language string
+
+const (
+	LanguageEnglish    Language = "en"
+	LanguageGerman     Language = "de"
+	LanguageFrench     Language = "fr"
+	LanguageSpanish    Language = "es"
+	LanguageItalian    Language = "it"
+	LanguageDutch      Language = "nl"
+	LanguagePortuguese Language = "pt"
+)
+
+// SupportedLanguages are languages which are detected and have embedded sentence training data.
+var SupportedLanguages = []Language{
+	LanguageEnglish,
+	LanguageGerman,
+	LanguageFrench,
+	LanguageSpanish,
+	LanguageItalian,
+	LanguageDutch,
+	LanguagePortuguese,
+}
+
+// Valid returns true for supported languages.
+func (l Language) Valid() bool {
+	for _, supported := range SupportedLanguages {
+		if l == supported {
+			return true
+		}
+	}
+	return false
+}
+
+//go:embed testdata/*.json
+var trainingFiles embed.FS
+
+var trainingFileNames = map[Language]string{
+	LanguageEnglish:    "testdata/english.json",
+	LanguageGerman:     "testdata/german.json",
+	LanguageFrench:     "testdata/french.json",
+	LanguageSpanish:    "testdata/spanish.json",
+	LanguageItalian:    "testdata/italian.json",
+	LanguageDutch:      "testdata/dutch.json",
+	LanguagePortuguese: "testdata/portuguese.json",
+}
+
+var (
+	trainingMu sync.Mutex
+	trainings  = map[Language]*sentences.Storage{}
+)
+
+// loadTraining loads embedded sentence training data of a language only when it is needed,
+// English training data is used for unknown languages.
+func loadTraining(language Language) (*sentences.Storage, error) {
+	if !language.Valid() {
+		language = LanguageEnglish
+	}
+
+	trainingMu.Lock()
+	defer trainingMu.Unlock()
+
+	if training, ok := trainings[language]; ok {
+		return training, nil
+	}
+
+	data, err := trainingFiles.ReadFile(trainingFileNames[language])
+	if err != nil {
+		return nil, fmt.Errorf("read %s training: %w", language, err)
+	}
+	training, err := sentences.LoadTraining(data)
+	if err != nil {
+		return nil, fmt.Errorf("load %s training: %w", language, err)
+	}
+	trainings[language] = training
+
+	return training, nil
+}
+
+// stopWords are frequent words which are distinctive enough to tell supported languages apart.
+var stopWords = map[Language][]string{
+	LanguageEnglish:    {"the", "and", "of", "to", "is", "that", "for", "with", "are", "was", "this", "our", "we", "by", "be", "on", "it", "as", "have", "which"},
+	LanguageGerman:     {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "von", "zu", "sich", "auf", "für", "des", "dem", "wir", "eine", "ein", "wird", "auch"},
+	LanguageFrench:     {"le", "les", "et", "est", "des", "une", "dans", "pour", "qui", "pas", "sur", "du", "au", "aux", "nous", "avec", "sont", "ce", "cette", "ou"},
+	LanguageSpanish:    {"el", "los", "las", "y", "es", "del", "por", "con", "una", "para", "se", "lo", "su", "al", "como", "más", "está", "son", "este", "sus"},
+	LanguageItalian:    {"il", "della", "di", "che", "è", "per", "con", "una", "non", "sono", "gli", "del", "nel", "alla", "anche", "questo", "dei", "delle", "più", "si"},
+	LanguageDutch:      {"de", "het", "een", "van", "en", "is", "dat", "niet", "op", "voor", "met", "zijn", "ook", "aan", "wij", "er", "dit", "worden", "bij", "naar"},
+	LanguagePortuguese: {"o", "os", "da", "do", "das", "dos", "e", "é", "não", "uma", "com", "para", "em", "por", "mais", "ao", "são", "como", "pelo", "na"},
+}
+
+// stopWordLanguages maps each stop word to languages it is frequent in.
+var stopWordLanguages = func() map[string][]Language {
+	index := map[string][]Language{}
+	for _, language := range SupportedLanguages {
+		for _, word := range stopWords[language] {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+const (
+	// Text with fewer stop words is too short to detect its language reliably
+	minLanguageStopWords = 5
+	// Only the beginning of long text is needed to detect its language
+	maxLanguageWords = 5000
+)
+
+// DetectLanguage detects the language of text by counting stop words of supported languages.
+// It returns an empty language if text is too short or not written in a supported language.
+func DetectLanguage(text string) Language {
+	var (
+		counts = map[Language]int{}
+		words  = 0
+	)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for _, language := range stopWordLanguages[word] {
+			counts[language] += 1
+		}
+		words += 1
+		if words >= maxLanguageWords {
+			break
+		}
+	}
+
+	var (
+		best      Language
+		bestCount = 0
+	)
+	// Supported languages are in order of preference when counts are equal
+	for _, language := range SupportedLanguages {
+		if counts[language] > bestCount {
+			best, bestCount = language, counts[language]
+		}
+	}
+	if bestCount < minLanguageStopWords {
+		return ""
+	}
+
+	return best
+}
+
+// detectDocumentLanguages detects the language of the file from contents of all its documents and
+// then of each document. Documents too short to detect their language are in the language of the file.
+func detectDocumentLanguages(documents []Document) Language {
+	contents := make([]string, 0, len(documents))
+	for _, aDocument := range documents {
+		contents = append(contents, aDocument.Content)
+	}
+	fileLanguage := DetectLanguage(strings.Join(contents, "\n"))
+
+	for i := range documents {
+		documents[i].Language = DetectLanguage(documents[i].Content)
+		if documents[i].Language == "" {
+			documents[i].Language = fileLanguage
+		}
+	}
+
+	return fileLanguage
+}
@@ -0,0 +1,105 @@
+package ragserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected Language
+	}{
+		{
+			"English",
+			"Our total scope 1 emissions were 100 tCO2e, which is a decrease of 5% compared to the previous year.",
+			LanguageEnglish,
+		},
+		{
+			"German",
+			"Die gesamten Scope-1-Emissionen des Unternehmens betrugen 100 tCO2e und sind damit auch im Vergleich zu dem Vorjahr gesunken.",
+			LanguageGerman,
+		},
+		{
+			"French",
+			"Les émissions totales du scope 1 sont de 100 tCO2e, ce qui représente une baisse par rapport à l'année précédente pour nous.",
+			LanguageFrench,
+		},
+		{
+			"Spanish",
+			"Las emisiones totales del alcance 1 fueron de 100 tCO2e, lo que supone una reducción con respecto al año anterior para la empresa y sus filiales.",
+			LanguageSpanish,
+		},
+		{
+			"Italian",
+			"Le emissioni totali di scope 1 sono state di 100 tCO2e, che è una riduzione rispetto all'anno precedente anche per il gruppo e delle sue controllate.",
+			LanguageItalian,
+		},
+		{
+			"Dutch",
+			"De totale scope 1 emissies van het bedrijf waren 100 tCO2e, dat is een daling ten opzichte van het vorige jaar en ook voor de dochterondernemingen.",
+			LanguageDutch,
+		},
+		{
+			"Portuguese",
+			"As emissões totais do escopo 1 foram de 100 tCO2e, o que é uma redução em relação ao ano anterior para a empresa e as suas subsidiárias.",
+			LanguagePortuguese,
+		},
+		{
+			"Too short",
+			"Scope 1 emissions",
+			"",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DetectLanguage(tc.text))
+		})
+	}
+}
+
+func TestDetectDocumentLanguages(t *testing.T) {
+	t.Parallel()
+
+	documents := []Document{
+		{Content: "Die gesamten Scope-1-Emissionen des Unternehmens betrugen 100 tCO2e und sind damit auch im Vergleich zu dem Vorjahr gesunken."},
+		{Content: "Scope 1: 100 tCO2e"},
+		{Content: "Our total scope 1 emissions were 100 tCO2e, which is a decrease of 5% compared to the previous year."},
+		{Content: "Die Ziele der Gruppe werden von dem Vorstand festgelegt und sind auch für die Tochtergesellschaften verbindlich."},
+	}
+
+	language := detectDocumentLanguages(documents)
+	assert.Equal(t, LanguageGerman, language)
+
+	// Documents too short to detect their language are in the language of the file
+	expected := []Language{LanguageGerman, LanguageGerman, LanguageEnglish, LanguageGerman}
+	for i, aDocument := range documents {
+		assert.Equal(t, expected[i], aDocument.Language)
+	}
+}
+
+func TestSentenceChunker_Language(t *testing.T) {
+	t.Parallel()
+
+	documents := []Document{
+		{Content: "Die Emissionen sanken um 5 Prozent. Das Ziel ist Netto-Null bis 2050.", Language: LanguageGerman},
+		{Content: "Les émissions ont baissé. L'objectif est la neutralité carbone.", Language: LanguageFrench},
+	}
+
+	chunks, err := NewSentenceChunker(nil).Chunk(documents)
+	require.NoError(t, err)
+
+	expected := []Document{
+		{Content: "Die Emissionen sanken um 5 Prozent.", Language: LanguageGerman},
+		{Content: "Das Ziel ist Netto-Null bis 2050.", Language: LanguageGerman},
+		{Content: "Les émissions ont baissé.", Language: LanguageFrench},
+		{Content: "L'objectif est la neutralité carbone.", Language: LanguageFrench},
+	}
+	assert.Equal(t, expected, chunks)
+}
@@ -0,0 +1,236 @@
+package ragserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+var ErrInvalidLogicalDocument = errors.New("invalid logical document")
+
+type LogicalDocumentID struct{ uuid.UUID }
+
+func NewLogicalDocumentID() LogicalDocumentID {
+	return LogicalDocumentID{uuid.Must(uuid.NewV4())}
+}
+
+// LogicalDocument groups files which are versions of the same document, such as a report republished
+// with corrections. Versions are ordered from the oldest, the last one is the latest version.
+type LogicalDocument struct {
+	ID       LogicalDocumentID
+	AuthorID AuthorID
+	Name     string
+	Versions []*File
+	// LastVersion is the number of the last version added, it isn't decreased when the file
+	// of a version is deleted so version numbers are never reused
+	LastVersion int
+	Created     time.Time
+	Updated     time.Time
+}
+
+// LogicalDocumentParams create a logical document, files are added as its versions in the given order.
+type LogicalDocumentParams struct {
+	Name    string
+	FileIDs []FileID
+}
+
+// LatestVersion returns the most recently added version, nil if the document has no versions.
+func (d *LogicalDocument) LatestVersion() *File {
+	if len(d.Versions) == 0 {
+		return nil
+	}
+	return d.Versions[len(d.Versions)-1]
+}
+
+// LatestProcessedVersion returns the newest version processed successfully with the embedder
+// and retriever, nil if there is none. Newer versions still being processed are skipped.
+func (d *LogicalDocument) LatestProcessedVersion(embedder, retriever string) *File {
+	for i := len(d.Versions) - 1; i >= 0; i-- {
+		aFile := d.Versions[i]
+		if aFile.Status == FileStatusProcessedSuccessfully && aFile.Embedder == embedder && aFile.Retriever == retriever {
+			return aFile
+		}
+	}
+	return nil
+}
+
+// AddVersion adds the file as the latest version, a file can only be a version of one document.
+func (d *LogicalDocument) AddVersion(aFile *File, updatedAt time.Time) error {
+	if !aFile.LogicalDocumentID.IsNil() {
+		return fmt.Errorf("%w: file %s is already version %d of logical document %s", ErrInvalidLogicalDocument, aFile.ID, aFile.Version, aFile.LogicalDocumentID)
+	}
+
+	d.LastVersion += 1
+	aFile.LogicalDocumentID = d.ID
+	aFile.Version = d.LastVersion
+	d.Versions = append(d.Versions, aFile)
+	d.Updated = updatedAt
+
+	return nil
+}
+
+func (rs *ragServer) CreateLogicalDocument(ctx context.Context, principal authz.Principal, params LogicalDocumentParams) (*LogicalDocument, error) {
+	rs.logger.Sugar().With("name", params.Name).Info("creating logical document")
+
+	if strings.TrimSpace(params.Name) == "" {
+		return nil, fmt.Errorf("%w: missing name", ErrInvalidLogicalDocument)
+	}
+
+	aDocument := &LogicalDocument{
+		ID:       NewLogicalDocumentID(),
+		AuthorID: AuthorID{principal.ID().UUID},
+		Name:     params.Name,
+		Versions: make([]*File, 0, len(params.FileIDs)),
+		Created:  rs.now(),
+		Updated:  rs.now(),
+	}
+
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		files, err := rs.versionFiles(ctx, aDocument, params.FileIDs...)
+		if err != nil {
+			return err
+		}
+
+		if err := rs.store.SavePrincipal(ctx, principal); err != nil {
+			return fmt.Errorf("error saving principal: %w", err)
+		}
+
+		if err := rs.store.SaveLogicalDocument(ctx, aDocument); err != nil {
+			return fmt.Errorf("error saving logical document: %w", err)
+		}
+
+		if err := rs.store.SaveLogicalDocumentVersions(ctx, aDocument, files...); err != nil {
+			return fmt.Errorf("error saving logical document versions: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return aDocument, nil
+}
+
+// AddLogicalDocumentVersion adds the file as the latest version of the logical document, screenings
+// created with the document afterwards use it once it has been processed.
+func (rs *ragServer) AddLogicalDocumentVersion(ctx context.Context, principal authz.Principal, id LogicalDocumentID, fileID FileID) (*LogicalDocument, error) {
+	rs.logger.Sugar().With("id", id, "file_id", fileID).Info("adding logical document version")
+
+	var aDocument *LogicalDocument
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		// Lock the document so concurrent versions get different numbers
+		aDocument, err = rs.store.FindLogicalDocument(ctx, id, true)
+		if err != nil {
+			return err
+		}
+
+		files, err := rs.versionFiles(ctx, aDocument, fileID)
+		if err != nil {
+			return err
+		}
+
+		if err := rs.store.SaveLogicalDocument(ctx, aDocument); err != nil {
+			return fmt.Errorf("error saving logical document: %w", err)
+		}
+
+		if err := rs.store.SaveLogicalDocumentVersions(ctx, aDocument, files...); err != nil {
+			return fmt.Errorf("error saving logical document versions: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return aDocument, nil
+}
+
+// versionFiles finds the files and adds them as versions of the logical document.
+func (rs *ragServer) versionFiles(ctx context.Context, aDocument *LogicalDocument, ids ...FileID) ([]*File, error) {
+	files := make([]*File, 0, len(ids))
+	for _, fileID := range ids {
+		aFile, err := rs.store.FindFile(ctx, fileID, rs.filePpartial())
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: file %s not found", ErrInvalidLogicalDocument, fileID)
+			}
+			return nil, fmt.Errorf("error finding file: %w", err)
+		}
+		if err := aDocument.AddVersion(aFile, rs.now()); err != nil {
+			return nil, err
+		}
+		files = append(files, aFile)
+	}
+	return files, nil
+}
+
+func (rs *ragServer) ListLogicalDocuments(ctx context.Context, principal authz.Principal) ([]*LogicalDocument, error) {
+	var documents []*LogicalDocument
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		documents, err = rs.store.ListLogicalDocuments(ctx, SortParams{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (rs *ragServer) FindLogicalDocument(ctx context.Context, principal authz.Principal, id LogicalDocumentID) (*LogicalDocument, error) {
+	var aDocument *LogicalDocument
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		aDocument, err = rs.store.FindLogicalDocument(ctx, id, false)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return aDocument, nil
+}
+
+// DeleteLogicalDocument deletes the logical document, files of its versions are kept.
+func (rs *ragServer) DeleteLogicalDocument(ctx context.Context, principal authz.Principal, id LogicalDocumentID) error {
+	rs.logger.Sugar().With("id", id).Info("deleting logical document")
+
+	return rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		aDocument, err := rs.store.FindLogicalDocument(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		return rs.store.DeleteLogicalDocument(ctx, aDocument)
+	})
+}
+
+// latestProcessedFileIDs resolves logical documents to IDs of their latest processed versions.
+func (rs *ragServer) latestProcessedFileIDs(ctx context.Context, ids ...LogicalDocumentID) ([]FileID, error) {
+	fileIDs := make([]FileID, 0, len(ids))
+	for _, id := range ids {
+		aDocument, err := rs.store.FindLogicalDocument(ctx, id, false)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: logical document %s not found", ErrInvalidLogicalDocument, id)
+			}
+			return nil, fmt.Errorf("error finding logical document: %w", err)
+		}
+
+		aFile := aDocument.LatestProcessedVersion(rs.embedder.Name(), rs.retriever.Name())
+		if aFile == nil {
+			return nil, fmt.Errorf("%w: logical document %s has no processed version", ErrInvalidLogicalDocument, id)
+		}
+
+		rs.logger.Sugar().With("id", id, "file_id", aFile.ID, "version", aFile.Version).Info("resolved logical document version")
+
+		fileIDs = append(fileIDs, aFile.ID)
+	}
+	return fileIDs, nil
+}
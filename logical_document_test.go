@@ -0,0 +1,87 @@
+package ragserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogicalDocument_AddVersion(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now       = time.Now()
+		aDocument = &LogicalDocument{ID: NewLogicalDocumentID(), Name: "Annual report"}
+		file1     = &File{ID: NewFileID()}
+		file2     = &File{ID: NewFileID()}
+		file3     = &File{ID: NewFileID()}
+	)
+
+	assert.Nil(t, aDocument.LatestVersion())
+
+	require.NoError(t, aDocument.AddVersion(file1, now))
+	assert.Equal(t, aDocument.ID, file1.LogicalDocumentID)
+	assert.Equal(t, 1, file1.Version)
+	assert.Equal(t, file1, aDocument.LatestVersion())
+
+	require.NoError(t, aDocument.AddVersion(file2, now.Add(time.Minute)))
+	assert.Equal(t, 2, file2.Version)
+	assert.Equal(t, file2, aDocument.LatestVersion())
+	assert.Equal(t, 2, aDocument.LastVersion)
+	assert.Equal(t, now.Add(time.Minute), aDocument.Updated)
+
+	// The version of a deleted file is removed, its number is not reused
+	aDocument.Versions = aDocument.Versions[:1]
+	require.NoError(t, aDocument.AddVersion(file3, now.Add(2*time.Minute)))
+	assert.Equal(t, 3, file3.Version)
+	assert.Equal(t, file3, aDocument.LatestVersion())
+
+	err := (&LogicalDocument{ID: NewLogicalDocumentID()}).AddVersion(file1, now)
+	assert.ErrorIs(t, err, ErrInvalidLogicalDocument)
+}
+
+func TestLogicalDocument_LatestProcessedVersion(t *testing.T) {
+	t.Parallel()
+
+	var (
+		processed = &File{
+			ID:        NewFileID(),
+			Embedder:  "hugot",
+			Retriever: "redis",
+			Status:    FileStatusProcessedSuccessfully,
+		}
+		otherEmbedder = &File{
+			ID:        NewFileID(),
+			Embedder:  "google-genai",
+			Retriever: "redis",
+			Status:    FileStatusProcessedSuccessfully,
+		}
+		processing = &File{
+			ID:        NewFileID(),
+			Embedder:  "hugot",
+			Retriever: "redis",
+			Status:    FileStatusProcessing,
+		}
+	)
+
+	tests := []struct {
+		name     string
+		versions []*File
+		expected *File
+	}{
+		{"no versions", nil, nil},
+		{"no processed version", []*File{processing}, nil},
+		{"newer version still processing", []*File{processed, processing}, processed},
+		{"newer version processed by another embedder", []*File{processed, otherEmbedder}, processed},
+		{"latest version", []*File{otherEmbedder, processed}, processed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aDocument := &LogicalDocument{Versions: tt.versions}
+			assert.Equal(t, tt.expected, aDocument.LatestProcessedVersion("hugot", "redis"))
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package ragserver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+const (
+	maxMetadataKeys        = 32
+	maxMetadataValueLength = 256
+)
+
+// metadataKeyPattern allows keys which can be used as field names of all retrievers.
+var metadataKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Metadata are key/value pairs describing a file, such as company name, reporting year or document type.
+type Metadata map[string]string
+
+// Validate checks keys are lowercase identifiers and values are not empty or too long.
+func (m Metadata) Validate() error {
+	if len(m) > maxMetadataKeys {
+		return fmt.Errorf("%w: %d keys exceeds limit of %d keys", ErrInvalidMetadata, len(m), maxMetadataKeys)
+	}
+	for key, value := range m {
+		if !metadataKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: key %q must start with a lowercase letter followed by lowercase letters, digits or underscores", ErrInvalidMetadata, key)
+		}
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%w: missing value of key %q", ErrInvalidMetadata, key)
+		}
+		if len(value) > maxMetadataValueLength {
+			return fmt.Errorf("%w: value of key %q exceeds limit of %d bytes", ErrInvalidMetadata, key, maxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+type MetadataFieldType string
+
+const (
+	// MetadataFieldTag is matched by exact values
+	MetadataFieldTag MetadataFieldType = "TAG"
+	// MetadataFieldNumeric is matched by a range, values must be numbers
+	MetadataFieldNumeric MetadataFieldType = "NUMERIC"
+)
+
+// MetadataField is a metadata key copied to documents of files, so retrieval can be filtered by it.
+// Fields are configured with WithIndexedMetadata and the same fields have to be configured on the retriever.
+type MetadataField struct {
+	Key  string
+	Type MetadataFieldType
+}
+
+// MetadataCondition matches metadata with any of the values of the key, or for numeric keys
+// a value between Min and Max inclusive. Either bound can be nil for open ranges.
+type MetadataCondition struct {
+	Key    string
+	Values []string
+	Min    *float64
+	Max    *float64
+}
+
+// IsRange returns true if the condition matches numeric values by range rather than exact values.
+func (c MetadataCondition) IsRange() bool {
+	return c.Min != nil || c.Max != nil
+}
+
+func (c MetadataCondition) validate() error {
+	if !metadataKeyPattern.MatchString(c.Key) {
+		return fmt.Errorf("%w: invalid key %q", ErrInvalidMetadata, c.Key)
+	}
+	switch {
+	case c.IsRange() && len(c.Values) > 0:
+		return fmt.Errorf("%w: key %q can be matched by either values or a range, not both", ErrInvalidMetadata, c.Key)
+	case !c.IsRange() && len(c.Values) == 0:
+		return fmt.Errorf("%w: missing values or range of key %q", ErrInvalidMetadata, c.Key)
+	case c.Min != nil && c.Max != nil && *c.Min > *c.Max:
+		return fmt.Errorf("%w: min of key %q is greater than max", ErrInvalidMetadata, c.Key)
+	}
+	return nil
+}
+
+// Matches returns true if the metadata has a value of the key matching the condition.
+func (c MetadataCondition) Matches(metadata Metadata) bool {
+	value, ok := metadata[c.Key]
+	if !ok {
+		return false
+	}
+	if !c.IsRange() {
+		return slices.Contains(c.Values, value)
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	return (c.Min == nil || number >= *c.Min) && (c.Max == nil || number <= *c.Max)
+}
+
+// MetadataFilter matches metadata if all of its conditions match.
+type MetadataFilter []MetadataCondition
+
+// Validate checks all conditions, see MetadataCondition.
+func (f MetadataFilter) Validate() error {
+	for _, condition := range f {
+		if err := condition.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f MetadataFilter) Matches(metadata Metadata) bool {
+	for _, condition := range f {
+		if !condition.Matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexedMetadataField returns the indexed field of the key.
+func (rs *ragServer) indexedMetadataField(key string) (MetadataField, bool) {
+	for _, field := range rs.indexedMetadata {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return MetadataField{}, false
+}
+
+// validateMetadata validates metadata of a file, values of numeric indexed fields must be numbers.
+func (rs *ragServer) validateMetadata(metadata Metadata) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+	for key, value := range metadata {
+		field, ok := rs.indexedMetadataField(key)
+		if !ok || field.Type != MetadataFieldNumeric {
+			continue
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%w: value of key %q must be a number", ErrInvalidMetadata, key)
+		}
+	}
+	return nil
+}
+
+// validateRetrievalFilter checks documents can be filtered by the metadata filter, only indexed
+// fields are copied to documents and only numeric fields can be matched by a range.
+func (rs *ragServer) validateRetrievalFilter(filter MetadataFilter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	for _, condition := range filter {
+		field, ok := rs.indexedMetadataField(condition.Key)
+		if !ok {
+			return fmt.Errorf("%w: key %q is not indexed for retrieval", ErrInvalidMetadata, condition.Key)
+		}
+		if condition.IsRange() && field.Type != MetadataFieldNumeric {
+			return fmt.Errorf("%w: key %q is not numeric", ErrInvalidMetadata, condition.Key)
+		}
+	}
+	return nil
+}
+
+// documentMetadata returns metadata of the file copied to its documents.
+func (rs *ragServer) documentMetadata(aFile *File) Metadata {
+	var metadata Metadata
+	for _, field := range rs.indexedMetadata {
+		value, ok := aFile.Metadata[field.Key]
+		if !ok {
+			continue
+		}
+		if metadata == nil {
+			metadata = Metadata{}
+		}
+		metadata[field.Key] = value
+	}
+	return metadata
+}
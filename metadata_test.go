@@ -0,0 +1,197 @@
+package ragserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata_Validate(t *testing.T) {
+	t.Parallel()
+
+	tooManyKeys := Metadata{}
+	for i := range maxMetadataKeys + 1 {
+		tooManyKeys[string(rune('a'+i%26))+strings.Repeat("x", i/26)] = "value"
+	}
+
+	tests := []struct {
+		name     string
+		metadata Metadata
+		err      string
+	}{
+		{"empty", nil, ""},
+		{"valid", Metadata{"company": "Acme, Inc.", "reporting_year": "2024"}, ""},
+		{
+			"uppercase key",
+			Metadata{"Company": "Acme"},
+			`invalid metadata: key "Company" must start with a lowercase letter followed by lowercase letters, digits or underscores`,
+		},
+		{
+			"key starting with a digit",
+			Metadata{"2024": "Acme"},
+			`invalid metadata: key "2024" must start with a lowercase letter followed by lowercase letters, digits or underscores`,
+		},
+		{"blank value", Metadata{"company": " "}, `invalid metadata: missing value of key "company"`},
+		{
+			"value too long",
+			Metadata{"company": strings.Repeat("a", maxMetadataValueLength+1)},
+			`invalid metadata: value of key "company" exceeds limit of 256 bytes`,
+		},
+		{"too many keys", tooManyKeys, "invalid metadata: 33 keys exceeds limit of 32 keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metadata.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidMetadata)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestMetadataFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	var (
+		metadata = Metadata{"company": "Acme", "reporting_year": "2023"}
+		min2023  = float64(2023)
+		max2022  = float64(2022)
+	)
+
+	tests := []struct {
+		name     string
+		filter   MetadataFilter
+		expected bool
+	}{
+		{"empty filter", nil, true},
+		{"any of values", MetadataFilter{{Key: "company", Values: []string{"Globex", "Acme"}}}, true},
+		{"values are case sensitive", MetadataFilter{{Key: "company", Values: []string{"acme"}}}, false},
+		{"missing key", MetadataFilter{{Key: "document_type", Values: []string{"annual report"}}}, false},
+		{"open range", MetadataFilter{{Key: "reporting_year", Min: &min2023}}, true},
+		{"out of range", MetadataFilter{{Key: "reporting_year", Max: &max2022}}, false},
+		{"range of non numeric value", MetadataFilter{{Key: "company", Min: &min2023}}, false},
+		{
+			"all conditions must match",
+			MetadataFilter{
+				{Key: "company", Values: []string{"Acme"}},
+				{Key: "reporting_year", Max: &max2022},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Matches(metadata))
+		})
+	}
+}
+
+func TestRagServer_ValidateRetrievalFilter(t *testing.T) {
+	t.Parallel()
+
+	rs := &ragServer{
+		indexedMetadata: []MetadataField{
+			{Key: "company", Type: MetadataFieldTag},
+			{Key: "reporting_year", Type: MetadataFieldNumeric},
+		},
+	}
+
+	var (
+		min2023 = float64(2023)
+		max2022 = float64(2022)
+	)
+
+	tests := []struct {
+		name   string
+		filter MetadataFilter
+		err    string
+	}{
+		{"empty filter", nil, ""},
+		{
+			"valid",
+			MetadataFilter{
+				{Key: "company", Values: []string{"Acme"}},
+				{Key: "reporting_year", Min: &min2023},
+			},
+			"",
+		},
+		{
+			"key not indexed",
+			MetadataFilter{{Key: "document_type", Values: []string{"annual report"}}},
+			`invalid metadata: key "document_type" is not indexed for retrieval`,
+		},
+		{
+			"range of tag field",
+			MetadataFilter{{Key: "company", Min: &min2023}},
+			`invalid metadata: key "company" is not numeric`,
+		},
+		{
+			"missing values",
+			MetadataFilter{{Key: "company"}},
+			`invalid metadata: missing values or range of key "company"`,
+		},
+		{
+			"values and range",
+			MetadataFilter{{Key: "reporting_year", Values: []string{"2023"}, Min: &min2023}},
+			`invalid metadata: key "reporting_year" can be matched by either values or a range, not both`,
+		},
+		{
+			"min greater than max",
+			MetadataFilter{{Key: "reporting_year", Min: &min2023, Max: &max2022}},
+			`invalid metadata: min of key "reporting_year" is greater than max`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rs.validateRetrievalFilter(tt.filter)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidMetadata)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRagServer_DocumentMetadata(t *testing.T) {
+	t.Parallel()
+
+	rs := &ragServer{
+		indexedMetadata: []MetadataField{
+			{Key: "company", Type: MetadataFieldTag},
+			{Key: "reporting_year", Type: MetadataFieldNumeric},
+		},
+	}
+
+	t.Run("only indexed keys are copied", func(t *testing.T) {
+		aFile := &File{Metadata: Metadata{"company": "Acme", "document_type": "annual report"}}
+		assert.Equal(t, Metadata{"company": "Acme"}, rs.documentMetadata(aFile))
+	})
+
+	t.Run("no indexed keys", func(t *testing.T) {
+		aFile := &File{Metadata: Metadata{"document_type": "annual report"}}
+		assert.Nil(t, rs.documentMetadata(aFile))
+	})
+}
+
+func TestRagServer_ValidateMetadata(t *testing.T) {
+	t.Parallel()
+
+	rs := &ragServer{
+		indexedMetadata: []MetadataField{{Key: "reporting_year", Type: MetadataFieldNumeric}},
+	}
+
+	assert.NoError(t, rs.validateMetadata(Metadata{"reporting_year": "2023.5", "fiscal_year": "FY2023"}))
+
+	err := rs.validateMetadata(Metadata{"reporting_year": "FY2023"})
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	assert.EqualError(t, err, `invalid metadata: value of key "reporting_year" must be a number`)
+}
@@ -8,9 +8,30 @@ import (
 	"github.com/RichardKnop/ragserver/pkg/authz"
 )
 
-// Extractor extracts documents from various contents, optionally limited by relevant topics.
+// Extractor extracts documents from various contents. Documents are blocks of the original
+// layout such as paragraphs, list items or table rows, they are split further by a Chunker.
 type Extractor interface {
-	Extract(ctx context.Context, fileName string, contents io.ReadSeeker, topics RelevantTopics) ([]Document, error)
+	Extract(ctx context.Context, fileName string, contents io.ReadSeeker) ([]Document, error)
+}
+
+// Chunker splits extracted documents into chunks which are embedded and stored by a retriever.
+type Chunker interface {
+	// Name identifies the chunker including its configuration, it is recorded on files.
+	Name() string
+	Chunk(documents []Document) ([]Document, error)
+}
+
+// RelevanceFilter decides which chunked documents are relevant to topics, documents which are not
+// relevant to any topic are dropped before they are embedded.
+type RelevanceFilter interface {
+	// Classify returns the most relevant topic for each document, nil if it is not relevant to any.
+	Classify(ctx context.Context, topics RelevantTopics, documents []Document) ([]*Topic, error)
+}
+
+// TopicValidator is an optional capability of a RelevanceFilter. It rejects topics the filter can't
+// classify documents by, such as topics with only a description for KeywordFilter.
+type TopicValidator interface {
+	ValidateTopics(topics RelevantTopics) error
 }
 
 // Embedder encodes document passages as vectors
@@ -20,6 +41,14 @@ type Embedder interface {
 	EmbedContent(ctx context.Context, content string) (Vector, error)
 }
 
+// EmbeddingCache stores vectors of contents embedded by a model, see CachingEmbedder.
+// Contents are identified by their hex encoded SHA-256 hash.
+type EmbeddingCache interface {
+	// FindEmbeddings returns cached vectors by hash, hashes which are not cached are missing from the map.
+	FindEmbeddings(ctx context.Context, embedder, model string, hashes ...string) (map[string]Vector, error)
+	SaveEmbeddings(ctx context.Context, embedder, model string, vectors map[string]Vector) error
+}
+
 // Retriever that runs a question through the embeddings model and returns any encoded documents near the embedded question.
 type Retriever interface {
 	Name() string
@@ -27,6 +56,10 @@ type Retriever interface {
 	ListFileDocuments(ctx context.Context, id FileID, limit int) ([]Document, error)
 	SearchDocuments(ctx context.Context, filter DocumentFilter, limit int) ([]Document, error)
 	DeleteFileDocuments(ctx context.Context, id FileID) error
+	// CopyFileDocuments saves documents of one file along with their vectors under another file.
+	CopyFileDocuments(ctx context.Context, from, to FileID) error
+	// UpdateFileMetadata replaces indexed metadata of all documents of the file.
+	UpdateFileMetadata(ctx context.Context, id FileID, metadata Metadata) error
 }
 
 // GenerativeModel uses generative AI to generate responses based on a query and relevant documents.
@@ -34,10 +67,24 @@ type GenerativeModel interface {
 	Generate(ctx context.Context, question Question, documents []Document) ([]Response, error)
 }
 
+// StreamingGenerativeModel is an optional capability of a GenerativeModel. It reports raw model output
+// to onPartial as it is being generated, then returns the same structured responses as Generate.
+type StreamingGenerativeModel interface {
+	StreamGenerate(ctx context.Context, question Question, documents []Document, onPartial func(text string) error) ([]Response, error)
+}
+
+// OCR recognizes text in images.
+type OCR interface {
+	Recognize(ctx context.Context, image io.Reader) (string, error)
+}
+
 type Store interface {
 	Transactional
 	FileStore
+	UploadStore
 	ScreeningStgore
+	TopicSetStore
+	LogicalDocumentStore
 }
 
 type Transactional interface {
@@ -50,6 +97,31 @@ type FileStore interface {
 	ListFiles(ctx context.Context, filter FileFilter, partial authz.Partial, params SortParams) ([]*File, error)
 	FindFile(ctx context.Context, id FileID, partial authz.Partial) (*File, error)
 	DeleteFiles(ctx context.Context, files ...*File) error
+	// SaveFileMetadata replaces metadata of the files.
+	SaveFileMetadata(ctx context.Context, files ...*File) error
+}
+
+type UploadStore interface {
+	SaveUploads(ctx context.Context, uploads ...*Upload) error
+	ListUploads(ctx context.Context, filter UploadFilter, params SortParams) ([]*Upload, error)
+	FindUpload(ctx context.Context, id UploadID, partial authz.Partial, lock bool) (*Upload, error)
+	DeleteUploads(ctx context.Context, uploads ...*Upload) error
+}
+
+type TopicSetStore interface {
+	SaveTopicSet(ctx context.Context, aTopicSet *TopicSet) error
+	ListTopicSets(ctx context.Context) ([]*TopicSet, error)
+	FindTopicSet(ctx context.Context, name string) (*TopicSet, error)
+	DeleteTopicSet(ctx context.Context, name string) error
+}
+
+type LogicalDocumentStore interface {
+	SaveLogicalDocument(ctx context.Context, aDocument *LogicalDocument) error
+	// SaveLogicalDocumentVersions adds files as versions of the logical document, numbered by their Version.
+	SaveLogicalDocumentVersions(ctx context.Context, aDocument *LogicalDocument, files ...*File) error
+	ListLogicalDocuments(ctx context.Context, params SortParams) ([]*LogicalDocument, error)
+	FindLogicalDocument(ctx context.Context, id LogicalDocumentID, lock bool) (*LogicalDocument, error)
+	DeleteLogicalDocument(ctx context.Context, aDocument *LogicalDocument) error
 }
 
 type ScreeningStgore interface {
@@ -64,6 +136,7 @@ type ScreeningStgore interface {
 
 type FileStorage interface {
 	NewTempFile() (TempFile, error)
+	OpenTempFile(name string) (TempFile, error)
 	DeleteTempFile(name string) error
 	Write(filename string, data io.Reader) error
 	Exists(filename string) (bool, error)
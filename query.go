@@ -0,0 +1,73 @@
+package ragserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query answers a single ad-hoc question using documents from files of the filter. Unlike a screening,
+// nothing is persisted, the response is generated synchronously and returned with its evidence.
+// If the filter has a language or metadata conditions, only matching documents are used.
+func (rs *ragServer) Query(ctx context.Context, principal authz.Principal, aQuestion Question, filter DocumentFilter) (Response, error) {
+	if err := rs.validateQuery(aQuestion, filter); err != nil {
+		return Response{}, err
+	}
+
+	rs.logger.Sugar().With("type", aQuestion.Type, "language", filter.Language, "file_ids", filter.FileIDs).Info("querying files")
+
+	return rs.generateResponse(ctx, aQuestion, filter, nil)
+}
+
+// StreamQuery works like Query but passes partial model output to onPartial as it is generated.
+// If the generative model does not support streaming, onPartial is never called and only the
+// final response is returned.
+func (rs *ragServer) StreamQuery(ctx context.Context, principal authz.Principal, aQuestion Question, filter DocumentFilter, onPartial func(text string) error) (Response, error) {
+	if err := rs.validateQuery(aQuestion, filter); err != nil {
+		return Response{}, err
+	}
+	if onPartial == nil {
+		return Response{}, fmt.Errorf("partial callback is required")
+	}
+
+	rs.logger.Sugar().With("type", aQuestion.Type, "language", filter.Language, "file_ids", filter.FileIDs).Info("streaming query")
+
+	return rs.generateResponse(ctx, aQuestion, filter, onPartial)
+}
+
+// ValidateQuery checks the question and that all files of the filter exist and have been processed,
+// without generating a response. It lets callers report invalid queries before they start streaming.
+func (rs *ragServer) ValidateQuery(ctx context.Context, principal authz.Principal, aQuestion Question, filter DocumentFilter) error {
+	if err := rs.validateQuery(aQuestion, filter); err != nil {
+		return err
+	}
+
+	if _, err := rs.processedFilesFromIDs(ctx, filter.FileIDs...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rs *ragServer) validateQuery(aQuestion Question, filter DocumentFilter) error {
+	if strings.TrimSpace(aQuestion.Content) == "" {
+		return fmt.Errorf("%w: question content is required", ErrInvalidQuery)
+	}
+	switch aQuestion.Type {
+	case QuestionTypeText, QuestionTypeMetric, QuestionTypeBoolean:
+	default:
+		return fmt.Errorf("%w: invalid question type: %s", ErrInvalidQuery, aQuestion.Type)
+	}
+	if filter.Language != "" && !filter.Language.Valid() {
+		return fmt.Errorf("%w: unsupported language: %s", ErrInvalidQuery, filter.Language)
+	}
+	if len(filter.FileIDs) == 0 {
+		return fmt.Errorf("%w: at least one file is required", ErrInvalidQuery)
+	}
+	return rs.validateRetrievalFilter(filter.Metadata)
+}
@@ -0,0 +1,252 @@
+package ragserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+// fakeStreamingGenerativeModel passes text of each response to onPartial before returning them.
+type fakeStreamingGenerativeModel struct {
+	*fakeGenerativeModel
+}
+
+func (m fakeStreamingGenerativeModel) StreamGenerate(ctx context.Context, question Question, documents []Document, onPartial func(text string) error) ([]Response, error) {
+	responses, err := m.Generate(ctx, question, documents)
+	if err != nil {
+		return nil, err
+	}
+	for _, response := range responses {
+		if err := onPartial(string(response.Text)); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+func TestRagServer_Query(t *testing.T) {
+	t.Parallel()
+
+	var (
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+		processed = &File{ID: NewFileID(), Status: FileStatusProcessedSuccessfully}
+		uploaded  = &File{ID: NewFileID(), Status: FileStatusUploaded}
+		document  = Document{FileID: processed.ID, Page: 1, Content: "Scope 1 emissions were 100 tCO2e."}
+		question  = Question{Type: QuestionTypeText, Content: "What were scope 1 emissions?"}
+		response  = Response{Text: "Scope 1 emissions were 100 tCO2e."}
+		year      = 2024.0
+		metadata  = MetadataFilter{{Key: "year", Min: &year}}
+	)
+
+	tests := []struct {
+		name        string
+		question    Question
+		filter      DocumentFilter
+		expectedErr error
+	}{
+		{
+			"empty content",
+			Question{Type: QuestionTypeText, Content: "  "},
+			DocumentFilter{FileIDs: []FileID{processed.ID}},
+			ErrInvalidQuery,
+		},
+		{
+			"invalid language",
+			question,
+			DocumentFilter{FileIDs: []FileID{processed.ID}, Language: "xx"},
+			ErrInvalidQuery,
+		},
+		{
+			"no file IDs",
+			question,
+			DocumentFilter{},
+			ErrInvalidQuery,
+		},
+		{
+			"metadata condition without values",
+			question,
+			DocumentFilter{FileIDs: []FileID{processed.ID}, Metadata: MetadataFilter{{Key: "year"}}},
+			ErrInvalidMetadata,
+		},
+		{
+			"metadata key is not indexed",
+			question,
+			DocumentFilter{FileIDs: []FileID{processed.ID}, Metadata: MetadataFilter{{Key: "company", Values: []string{"acme"}}}},
+			ErrInvalidMetadata,
+		},
+		{
+			"file is not processed",
+			question,
+			DocumentFilter{FileIDs: []FileID{uploaded.ID}},
+			ErrInvalidFileStatus,
+		},
+		{
+			"file does not exist",
+			question,
+			DocumentFilter{FileIDs: []FileID{NewFileID()}},
+			ErrNotFound,
+		},
+		{
+			"duplicate file IDs",
+			question,
+			DocumentFilter{FileIDs: []FileID{processed.ID, processed.ID}},
+			ErrInvalidQuery,
+		},
+		{
+			"invalid question type",
+			Question{Type: "UNKNOWN", Content: question.Content},
+			DocumentFilter{FileIDs: []FileID{processed.ID}},
+			ErrInvalidQuery,
+		},
+		{
+			"documents are retrieved with the filter",
+			question,
+			DocumentFilter{FileIDs: []FileID{processed.ID}, Language: LanguageEnglish, Metadata: metadata},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				retriever  = newFakeRetriever(document)
+				generative = &fakeGenerativeModel{responses: []Response{response}}
+				rs         = newTestRagServer(newFakeStore(processed, uploaded))
+			)
+			rs.retriever = retriever
+			rs.generative = generative
+			rs.indexedMetadata = []MetadataField{{Key: "year", Type: MetadataFieldNumeric}}
+
+			actual, err := rs.Query(context.Background(), principal, tt.question, tt.filter)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, retriever.filters)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, response, actual)
+			assert.Equal(t, []Document{document}, generative.documents)
+			require.Len(t, retriever.filters, 1)
+			assert.Equal(t, tt.filter.FileIDs, retriever.filters[0].FileIDs)
+			assert.Equal(t, tt.filter.Language, retriever.filters[0].Language)
+			assert.Equal(t, tt.filter.Metadata, retriever.filters[0].Metadata)
+			assert.Equal(t, Vector{float32(len(question.Content))}, retriever.filters[0].Vector)
+		})
+	}
+}
+
+func TestRagServer_ValidateQuery(t *testing.T) {
+	t.Parallel()
+
+	var (
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+		processed = &File{ID: NewFileID(), Status: FileStatusProcessedSuccessfully}
+		uploaded  = &File{ID: NewFileID(), Status: FileStatusUploaded}
+		question  = Question{Type: QuestionTypeText, Content: "What were scope 1 emissions?"}
+	)
+
+	tests := []struct {
+		name        string
+		question    Question
+		filter      DocumentFilter
+		expectedErr error
+	}{
+		{"valid query", question, DocumentFilter{FileIDs: []FileID{processed.ID}}, nil},
+		{"empty content", Question{Type: QuestionTypeText}, DocumentFilter{FileIDs: []FileID{processed.ID}}, ErrInvalidQuery},
+		{"file does not exist", question, DocumentFilter{FileIDs: []FileID{NewFileID()}}, ErrNotFound},
+		{"file is not processed", question, DocumentFilter{FileIDs: []FileID{uploaded.ID}}, ErrInvalidFileStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				retriever = newFakeRetriever()
+				rs        = newTestRagServer(newFakeStore(processed, uploaded))
+			)
+			rs.retriever = retriever
+
+			err := rs.ValidateQuery(context.Background(), principal, tt.question, tt.filter)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Empty(t, retriever.filters, "documents are not retrieved")
+		})
+	}
+}
+
+func TestRagServer_StreamQuery(t *testing.T) {
+	t.Parallel()
+
+	var (
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+		processed = &File{ID: NewFileID(), Status: FileStatusProcessedSuccessfully}
+		document  = Document{FileID: processed.ID, Page: 1, Content: "Scope 1 emissions were 100 tCO2e."}
+		question  = Question{Type: QuestionTypeText, Content: "What were scope 1 emissions?"}
+		filter    = DocumentFilter{FileIDs: []FileID{processed.ID}}
+		response  = Response{Text: "Scope 1 emissions were 100 tCO2e."}
+	)
+
+	newRagServer := func(streaming bool) *ragServer {
+		rs := newTestRagServer(newFakeStore(processed))
+		rs.retriever = newFakeRetriever(document)
+		rs.generative = &fakeGenerativeModel{responses: []Response{response}}
+		if streaming {
+			rs.generative = fakeStreamingGenerativeModel{&fakeGenerativeModel{responses: []Response{response}}}
+		}
+		return rs
+	}
+
+	t.Run("partial callback is required", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newRagServer(true).StreamQuery(context.Background(), principal, question, filter, nil)
+		require.EqualError(t, err, "partial callback is required")
+	})
+
+	t.Run("query is validated", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newRagServer(true).StreamQuery(context.Background(), principal, Question{Type: QuestionTypeText}, filter, func(text string) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrInvalidQuery)
+	})
+
+	t.Run("partial output is passed to the callback", func(t *testing.T) {
+		t.Parallel()
+
+		var partials []string
+		actual, err := newRagServer(true).StreamQuery(context.Background(), principal, question, filter, func(text string) error {
+			partials = append(partials, text)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, response, actual)
+		assert.Equal(t, []string{string(response.Text)}, partials)
+	})
+
+	t.Run("models without streaming only return the response", func(t *testing.T) {
+		t.Parallel()
+
+		var partials []string
+		actual, err := newRagServer(false).StreamQuery(context.Background(), principal, question, filter, func(text string) error {
+			partials = append(partials, text)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, response, actual)
+		assert.Empty(t, partials)
+	})
+}
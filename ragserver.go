@@ -3,6 +3,8 @@ package ragserver
 import (
 	_ "embed"
 	"errors"
+	"net/http"
+	"net/netip"
 	"time"
 
 	"go.uber.org/zap"
@@ -18,15 +20,29 @@ var TestEn string
 type clock func() time.Time
 
 type ragServer struct {
-	extractor      Extractor
-	embedder       Embedder
-	retriever      Retriever
-	generative     GenerativeModel
-	store          Store
-	filestorage    FileStorage
-	now            clock
-	relevantTopics RelevantTopics
-	logger         *zap.Logger
+	extractors         map[string]Extractor // keyed by content type
+	chunker            Chunker
+	embedder           Embedder
+	retriever          Retriever
+	previousRetrievers map[string]Retriever // keyed by name, see WithPreviousRetrievers
+	generative         GenerativeModel
+	store              Store
+	filestorage        FileStorage
+	httpClient         *http.Client
+	importNetworks     []netip.Prefix
+	maxFileSize        int64
+	uploadExpiry       time.Duration
+	processInterval    time.Duration
+	processJitter      time.Duration
+	fileConcurrency    int
+	fileTimeout        time.Duration
+	maxFileAttempts    int
+	fileRetryBackoff   time.Duration
+	now                clock
+	relevantTopics     RelevantTopics
+	relevanceFilter    RelevanceFilter
+	indexedMetadata    []MetadataField
+	logger             *zap.Logger
 }
 
 type Option func(*ragServer)
@@ -37,28 +53,179 @@ func WithRelevantTopics(topics RelevantTopics) Option {
 	}
 }
 
+// WithRelevanceFilter sets the filter which decides which documents are relevant to topics,
+// KeywordFilter is used by default. See EmbeddingFilter for a semantic alternative.
+func WithRelevanceFilter(filter RelevanceFilter) Option {
+	return func(rs *ragServer) {
+		rs.relevanceFilter = filter
+	}
+}
+
+// WithPreviousRetrievers registers retrievers files were stored by before switching to the current
+// one, so documents of files reprocessed with the current retriever are deleted from them. Files
+// stored by a retriever which is neither current nor registered can't be reprocessed.
+func WithPreviousRetrievers(retrievers ...Retriever) Option {
+	return func(rs *ragServer) {
+		for _, retriever := range retrievers {
+			rs.previousRetrievers[retriever.Name()] = retriever
+		}
+	}
+}
+
+// WithIndexedMetadata sets metadata fields copied to documents so retrieval can be filtered by them,
+// the retriever must be configured with the same fields. Files can be listed by any metadata.
+func WithIndexedMetadata(fields ...MetadataField) Option {
+	return func(rs *ragServer) {
+		rs.indexedMetadata = fields
+	}
+}
+
+// WithExtractor registers an extractor for files of the given content type. Only files with
+// a content type that has an extractor registered can be uploaded.
+func WithExtractor(contentType string, extractor Extractor) Option {
+	return func(rs *ragServer) {
+		rs.extractors[contentType] = extractor
+	}
+}
+
+// WithChunker sets the chunker used to split extracted documents, sentence chunker
+// with embedded English training data is used by default.
+func WithChunker(chunker Chunker) Option {
+	return func(rs *ragServer) {
+		rs.chunker = chunker
+	}
+}
+
+// WithHTTPClient sets the client used to download files imported from a URL. The client is used
+// as is, it is responsible for refusing connections to internal addresses.
+func WithHTTPClient(client *http.Client) Option {
+	return func(rs *ragServer) {
+		rs.httpClient = client
+	}
+}
+
+// WithImportAllowedNetworks allows importing files from private or other internal networks,
+// for example an intranet file server. Files are only imported from public addresses by default.
+func WithImportAllowedNetworks(prefixes ...netip.Prefix) Option {
+	return func(rs *ragServer) {
+		rs.importNetworks = append(rs.importNetworks, prefixes...)
+	}
+}
+
+// WithMaxFileSize sets the maximum size of uploaded and imported files in bytes, MaxFileSize is used by default.
+func WithMaxFileSize(size int64) Option {
+	return func(rs *ragServer) {
+		rs.maxFileSize = size
+	}
+}
+
+// WithUploadExpiry sets how long a resumable upload is kept without receiving a chunk, 24 hours by default.
+// Expired uploads are deleted together with bytes received so far when polling for files.
+func WithUploadExpiry(expiry time.Duration) Option {
+	return func(rs *ragServer) {
+		if expiry > 0 {
+			rs.uploadExpiry = expiry
+		}
+	}
+}
+
+// WithFileConcurrency sets the maximum number of files processed at the same time, 10 by default.
+// Claimed files are processed by a pool of this many workers so one slow file doesn't hold up others.
+func WithFileConcurrency(n int) Option {
+	return func(rs *ragServer) {
+		if n > 0 {
+			rs.fileConcurrency = n
+		}
+	}
+}
+
+// WithProcessInterval sets how often uploaded files are polled for processing, 1 second by default.
+func WithProcessInterval(interval time.Duration) Option {
+	return func(rs *ragServer) {
+		if interval > 0 {
+			rs.processInterval = interval
+		}
+	}
+}
+
+// WithProcessJitter sets the maximum random delay added to each poll, so multiple servers
+// sharing a database don't poll at the same time, 100 milliseconds by default.
+func WithProcessJitter(jitter time.Duration) Option {
+	return func(rs *ragServer) {
+		rs.processJitter = jitter
+	}
+}
+
+// WithFileTimeout sets how long a file can be processed for before it fails, 15 minutes by default.
+func WithFileTimeout(timeout time.Duration) Option {
+	return func(rs *ragServer) {
+		if timeout > 0 {
+			rs.fileTimeout = timeout
+		}
+	}
+}
+
+// WithMaxFileAttempts sets how many times processing of a file is attempted when it fails with
+// a retryable error, see IsRetryable, 5 by default. Use 1 to disable retries.
+func WithMaxFileAttempts(n int) Option {
+	return func(rs *ragServer) {
+		if n > 0 {
+			rs.maxFileAttempts = n
+		}
+	}
+}
+
+// WithFileRetryBackoff sets how long to wait before processing a failed file again, 1 minute by default.
+// The backoff doubles with each attempt up to 1 hour.
+func WithFileRetryBackoff(backoff time.Duration) Option {
+	return func(rs *ragServer) {
+		if backoff > 0 {
+			rs.fileRetryBackoff = backoff
+		}
+	}
+}
+
 func WithLogger(logger *zap.Logger) Option {
 	return func(rs *ragServer) {
 		rs.logger = logger
 	}
 }
 
+// New creates a RAG server. The extractor is used for PDF files, use WithExtractor option to support
+// other content types.
 func New(extractor Extractor, embedder Embedder, retriever Retriever, gm GenerativeModel, storeAdapter Store, fileStorage FileStorage, options ...Option) *ragServer {
 	rs := &ragServer{
-		extractor:   extractor,
-		embedder:    embedder,
-		retriever:   retriever,
-		generative:  gm,
-		store:       storeAdapter,
-		filestorage: fileStorage,
-		now:         func() time.Time { return time.Now().UTC() },
-		logger:      zap.NewNop(),
+		extractors: map[string]Extractor{
+			ContentTypePDF: extractor,
+		},
+		chunker:            NewSentenceChunker(nil),
+		embedder:           embedder,
+		retriever:          retriever,
+		previousRetrievers: map[string]Retriever{},
+		generative:         gm,
+		store:              storeAdapter,
+		filestorage:        fileStorage,
+		maxFileSize:        MaxFileSize,
+		uploadExpiry:       defaultUploadExpiry,
+		processInterval:    defaultProcessInterval,
+		processJitter:      defaultProcessJitter,
+		fileConcurrency:    defaultFileConcurrency,
+		fileTimeout:        defaultFileTimeout,
+		maxFileAttempts:    defaultMaxFileAttempts,
+		fileRetryBackoff:   defaultFileRetryBackoff,
+		now:                func() time.Time { return time.Now().UTC() },
+		relevanceFilter:    KeywordFilter{},
+		logger:             zap.NewNop(),
 	}
 
 	for _, o := range options {
 		o(rs)
 	}
 
+	if rs.httpClient == nil {
+		rs.httpClient = newImportClient(rs.importNetworks)
+	}
+
 	return rs
 }
 
@@ -66,6 +233,10 @@ func (rs *ragServer) filePpartial() authz.Partial {
 	return authz.FilterBy("embedder", rs.embedder.Name()).And("retriever", rs.retriever.Name())
 }
 
+func (rs *ragServer) uploadPartial(principal authz.Principal) authz.Partial {
+	return authz.FilterBy("author", principal.ID())
+}
+
 func (rs *ragServer) screeningPartial() authz.Partial {
 	return authz.NilPartial
 }
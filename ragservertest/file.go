@@ -26,6 +26,24 @@ func WithFileRetriever(retriever string) FileOption {
 	}
 }
 
+func WithFileChunker(chunker string) FileOption {
+	return func(f *ragserver.File) {
+		f.Chunker = chunker
+	}
+}
+
+func WithFileHash(hash string) FileOption {
+	return func(f *ragserver.File) {
+		f.Hash = hash
+	}
+}
+
+func WithFileSourceURL(sourceURL string) FileOption {
+	return func(f *ragserver.File) {
+		f.SourceURL = sourceURL
+	}
+}
+
 func WithFileStatus(status ragserver.FileStatus) FileOption {
 	return func(f *ragserver.File) {
 		f.Status = status
@@ -64,6 +82,7 @@ func (g *DataGen) File(options ...FileOption) *ragserver.File {
 		Hash:        g.LetterN(25),
 		Embedder:    g.Name(),
 		Retriever:   g.Name(),
+		Chunker:     "sentence",
 		Status:      fileStates[0],
 		Created:     g.now,
 		Updated:     g.now,
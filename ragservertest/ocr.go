@@ -0,0 +1,54 @@
+package ragservertest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+)
+
+// OCR is a deterministic fake implementation of ragserver.OCR which can be used
+// to test image processing without tesseract installed. It returns text registered
+// for exact image contents, or DefaultText for any other image.
+type OCR struct {
+	DefaultText string
+
+	mu    sync.Mutex
+	texts map[string]string
+}
+
+func NewOCR(defaultText string) *OCR {
+	return &OCR{
+		DefaultText: defaultText,
+		texts:       map[string]string{},
+	}
+}
+
+// Add registers text to be recognized in the image.
+func (o *OCR) Add(image []byte, text string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.texts[imageKey(image)] = text
+}
+
+func (o *OCR) Recognize(ctx context.Context, image io.Reader) (string, error) {
+	data, err := io.ReadAll(image)
+	if err != nil {
+		return "", err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if text, ok := o.texts[imageKey(data)]; ok {
+		return text, nil
+	}
+	return o.DefaultText, nil
+}
+
+func imageKey(image []byte) string {
+	hash := sha256.Sum256(image)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,53 @@
+package ragservertest
+
+import (
+	"time"
+
+	"github.com/RichardKnop/ragserver"
+)
+
+type UploadOption func(*ragserver.Upload)
+
+func WithUploadAuthorID(id ragserver.AuthorID) UploadOption {
+	return func(u *ragserver.Upload) {
+		u.AuthorID = id
+	}
+}
+
+func WithUploadOffset(offset int64) UploadOption {
+	return func(u *ragserver.Upload) {
+		u.Offset = offset
+	}
+}
+
+func WithUploadFileID(id ragserver.FileID) UploadOption {
+	return func(u *ragserver.Upload) {
+		u.FileID = id
+	}
+}
+
+func WithUploadExpires(expires time.Time) UploadOption {
+	return func(u *ragserver.Upload) {
+		u.Expires = expires
+	}
+}
+
+func (g *DataGen) Upload(options ...UploadOption) *ragserver.Upload {
+	anUpload := ragserver.Upload{
+		ID:        ragserver.NewUploadID(),
+		AuthorID:  ragserver.NewAuthorID(),
+		FileName:  g.Name() + ".pdf",
+		Size:      int64(g.IntRange(1, ragserver.MaxFileSize)),
+		TempFile:  "/tmp/file" + g.DigitN(10),
+		HashState: []byte(g.LetterN(32)),
+		Expires:   g.now.Add(24 * time.Hour),
+		Created:   g.now,
+		Updated:   g.now,
+	}
+
+	for _, o := range options {
+		o(&anUpload)
+	}
+
+	return &anUpload
+}
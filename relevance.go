@@ -0,0 +1,138 @@
+package ragserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// KeywordFilter keeps documents which match keywords and patterns of a topic, see Topic.
+// It is used by default.
+type KeywordFilter struct{}
+
+func (KeywordFilter) Classify(ctx context.Context, topics RelevantTopics, documents []Document) ([]*Topic, error) {
+	var (
+		classified = make([]*Topic, len(documents))
+		matcher    = topics.Matcher()
+	)
+	for i, aDocument := range documents {
+		classified[i] = matcher.match(strings.ToLower(relevanceContent(aDocument)))
+	}
+	return classified, nil
+}
+
+// ValidateTopics rejects topics with only a description, a description is a sentence which would
+// almost never be contained in a document. Such topics need EmbeddingFilter or another semantic filter.
+func (KeywordFilter) ValidateTopics(topics RelevantTopics) error {
+	for _, topic := range topics {
+		if len(topic.Keywords) == 0 && len(topic.Patterns) == 0 {
+			return fmt.Errorf("%w: topic %q has no keywords or patterns, a description is only used by semantic relevance filters", ErrInvalidTopics, topic.Name)
+		}
+	}
+	return nil
+}
+
+// EmbeddingFilter keeps documents semantically similar to a topic, so paraphrases such as "direct
+// emissions" are kept for a "scope 1" topic without listing every phrasing as a keyword. Documents and
+// topic descriptions are embedded and a document is relevant to the most similar topic if cosine
+// similarity of their vectors is at least the threshold. Exclude keywords of topics still apply.
+type EmbeddingFilter struct {
+	embedder  Embedder
+	threshold float64
+}
+
+// NewEmbeddingFilter returns a filter which embeds documents with the embedder, usually the same one
+// documents are embedded with when they are saved. Threshold depends on the embedding model, 0.5 is
+// a reasonable start for sentence transformers.
+//
+// Every document is embedded to be classified and relevant documents are embedded again when they are
+// saved, vectors can't be reused as documents are classified with their section heading. This roughly
+// doubles embedding cost of processing files, pass a local embedder if documents are embedded by a paid API.
+func NewEmbeddingFilter(embedder Embedder, threshold float64) *EmbeddingFilter {
+	return &EmbeddingFilter{
+		embedder:  embedder,
+		threshold: threshold,
+	}
+}
+
+func (f *EmbeddingFilter) Classify(ctx context.Context, topics RelevantTopics, documents []Document) ([]*Topic, error) {
+	classified := make([]*Topic, len(documents))
+	if len(topics) == 0 || len(documents) == 0 {
+		return classified, nil
+	}
+
+	topicVectors := make([]Vector, 0, len(topics))
+	for _, topic := range topics {
+		vector, err := f.embedder.EmbedContent(ctx, topic.Label())
+		if err != nil {
+			return nil, fmt.Errorf("error embedding topic %q: %w", topic.Name, err)
+		}
+		topicVectors = append(topicVectors, vector)
+	}
+
+	// Sections are embedded with the content for the same reason they are matched by keyword filter
+	candidates := make([]Document, 0, len(documents))
+	for _, aDocument := range documents {
+		aDocument.Content = relevanceContent(aDocument)
+		candidates = append(candidates, aDocument)
+	}
+
+	// Embedders which batch documents themselves get them all at once so batches run concurrently
+	batchSize := embedProgressBatchSize
+	if batchesDocuments(f.embedder) {
+		batchSize = max(len(candidates), 1)
+	}
+
+	i := 0
+	for batch := range slices.Chunk(candidates, batchSize) {
+		vectors, err := f.embedder.EmbedDocuments(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding documents: %w", err)
+		}
+		if len(vectors) != len(batch) {
+			return nil, fmt.Errorf("embedded batch size mismatch")
+		}
+
+		for j, vector := range vectors {
+			var bestScore float64
+			for k := range topics {
+				if topics[k].Excludes(batch[j].Content) {
+					continue
+				}
+				score := cosineSimilarity(vector, topicVectors[k])
+				if score >= f.threshold && (classified[i] == nil || score > bestScore) {
+					classified[i], bestScore = &topics[k], score
+				}
+			}
+			i += 1
+		}
+	}
+
+	return classified, nil
+}
+
+// relevanceContent is the document content with its section heading, which often says what the content is about.
+func relevanceContent(aDocument Document) string {
+	if aDocument.Section == "" {
+		return aDocument.Content
+	}
+	return aDocument.Section + "\n" + aDocument.Content
+}
+
+func cosineSimilarity(a, b Vector) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,150 @@
+package ragserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conceptEmbedder embeds content as counts of words of each concept, so synonyms are similar
+// the way they are for an embedding model.
+type conceptEmbedder struct {
+	concepts [][]string
+}
+
+func (e conceptEmbedder) Name() string {
+	return "concept"
+}
+
+func (e conceptEmbedder) EmbedDocuments(ctx context.Context, documents []Document) ([]Vector, error) {
+	vectors := make([]Vector, 0, len(documents))
+	for _, aDocument := range documents {
+		vector, err := e.EmbedContent(ctx, aDocument.Content)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+func (e conceptEmbedder) EmbedContent(ctx context.Context, content string) (Vector, error) {
+	vector := make(Vector, len(e.concepts))
+	for _, word := range strings.Fields(strings.ToLower(content)) {
+		word = strings.Trim(word, ".,:")
+		for i, concept := range e.concepts {
+			for _, conceptWord := range concept {
+				if word == conceptWord {
+					vector[i] += 1
+				}
+			}
+		}
+	}
+	return vector, nil
+}
+
+func TestKeywordFilter_Classify(t *testing.T) {
+	t.Parallel()
+
+	topics := RelevantTopics{
+		{Name: "scope", Keywords: []string{"scope 1"}},
+		{Name: "net-zero", Keywords: []string{"net zero"}},
+		{Name: "water", Description: "water withdrawal and consumption"},
+	}
+	documents := []Document{
+		{Content: "Our scope 1 emissions decreased."},
+		{Content: "Revenue increased."},
+		{Section: "Net zero", Content: "We have set targets for 2050."},
+		{Content: "Water withdrawal decreased by 10%."},
+	}
+
+	classified, err := KeywordFilter{}.Classify(context.Background(), topics, documents)
+	require.NoError(t, err)
+	// Topics with only a description are rejected when topics are validated, they match nothing
+	assert.Equal(t, []*Topic{&topics[0], nil, &topics[1], nil}, classified)
+}
+
+func TestEmbeddingFilter_Classify(t *testing.T) {
+	t.Parallel()
+
+	embedder := conceptEmbedder{
+		concepts: [][]string{
+			{"emissions", "ghg", "carbon", "direct"},
+			{"revenue", "sales", "profit"},
+			{"water", "withdrawal"},
+		},
+	}
+
+	topics := RelevantTopics{
+		{
+			Name:            "scope",
+			Description:     "direct GHG emissions",
+			ExcludeKeywords: []string{"table of contents"},
+		},
+		{
+			Name:     "water",
+			Keywords: []string{"water withdrawal"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		documents []Document
+		expected  []*Topic
+	}{
+		{
+			"paraphrases are relevant",
+			0.5,
+			[]Document{
+				{Content: "Direct carbon emissions were 100 tonnes."},
+				{Content: "Sales and profit increased."},
+				{Content: "Water withdrawal decreased."},
+			},
+			[]*Topic{&topics[0], nil, &topics[1]},
+		},
+		{
+			"section is taken into account",
+			0.5,
+			[]Document{
+				{Section: "Carbon emissions", Content: "It was 100 tonnes."},
+			},
+			[]*Topic{&topics[0]},
+		},
+		{
+			"below threshold",
+			0.9,
+			[]Document{
+				{Content: "Direct emissions and sales."},
+			},
+			[]*Topic{nil},
+		},
+		{
+			"most similar topic",
+			0.3,
+			[]Document{
+				{Content: "Water withdrawal and carbon emissions, water withdrawal of sites."},
+			},
+			[]*Topic{&topics[1]},
+		},
+		{
+			"excluded keyword",
+			0.5,
+			[]Document{
+				{Content: "Table of contents: carbon emissions."},
+			},
+			[]*Topic{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classified, err := NewEmbeddingFilter(embedder, tt.threshold).Classify(context.Background(), topics, tt.documents)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, classified)
+		})
+	}
+}
@@ -3,7 +3,9 @@ package ragserver
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -26,9 +28,12 @@ func NewScreeningID() ScreeningID {
 	return ScreeningID{uuid.Must(uuid.NewV4())}
 }
 
+// ScreeningParams reference files either by exact version or by logical document, logical documents
+// are resolved to their latest processed version so the screening records the exact files it used.
 type ScreeningParams struct {
-	FileIDs   []FileID
-	Questions []Question
+	FileIDs            []FileID
+	LogicalDocumentIDs []LogicalDocumentID
+	Questions          []Question
 }
 
 type Screening struct {
@@ -111,11 +116,24 @@ func (rs *ragServer) CreateScreening(ctx context.Context, principal authz.Princi
 	if len(params.Questions) == 0 {
 		return nil, fmt.Errorf("at least one question is required")
 	}
-	if len(params.FileIDs) == 0 {
+	if len(params.FileIDs) == 0 && len(params.LogicalDocumentIDs) == 0 {
 		return nil, fmt.Errorf("at least one file is required")
 	}
 
-	files, err := rs.processedFilesFromIDs(ctx, params.FileIDs...)
+	versionIDs, err := rs.latestProcessedFileIDs(ctx, params.LogicalDocumentIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A file can be given directly and also be the latest version of a logical document
+	fileIDs := slices.Clone(params.FileIDs)
+	for _, versionID := range versionIDs {
+		if !slices.Contains(fileIDs, versionID) {
+			fileIDs = append(fileIDs, versionID)
+		}
+	}
+
+	files, err := rs.processedFilesFromIDs(ctx, fileIDs...)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +242,7 @@ func (rs *ragServer) processedFilesFromIDs(ctx context.Context, ids ...FileID) (
 	}
 
 	if len(fileIDMap) < len(ids) {
-		return nil, fmt.Errorf("duplicate file IDs provided")
+		return nil, fmt.Errorf("%w: duplicate file IDs provided", ErrInvalidQuery)
 	}
 
 	files := make([]*File, 0, len(ids))
@@ -233,10 +251,13 @@ func (rs *ragServer) processedFilesFromIDs(ctx context.Context, ids ...FileID) (
 	for _, fileID := range ids {
 		aFile, err := rs.store.FindFile(ctx, fileID, rs.filePpartial())
 		if err != nil {
-			return nil, fmt.Errorf("error finding file: %v", err)
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: file %s", ErrNotFound, fileID)
+			}
+			return nil, fmt.Errorf("error finding file: %w", err)
 		}
 		if aFile.Status != FileStatusProcessedSuccessfully {
-			return nil, fmt.Errorf("file not processed: %s", fileID)
+			return nil, fmt.Errorf("%w: file not processed: %s", ErrInvalidFileStatus, fileID)
 		}
 		files = append(files, aFile)
 	}
@@ -13,7 +13,7 @@ import (
 
 func (rs *ragServer) ProcessScreenings(ctx context.Context) func() {
 	var (
-		ticker = time.NewTicker(processInterval - maxJitter/2)
+		ticker = time.NewTicker(screeningProcessInterval - screeningMaxJitter/2)
 		rand   = rand.New(rand.NewSource(time.Now().UnixNano()))
 		wg     = new(sync.WaitGroup)
 	)
@@ -24,8 +24,8 @@ func (rs *ragServer) ProcessScreenings(ctx context.Context) func() {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				if maxJitter > 0 {
-					jitterDuration := time.Duration(rand.Int63n(int64(maxJitter)))
+				if screeningMaxJitter > 0 {
+					jitterDuration := time.Duration(rand.Int63n(int64(screeningMaxJitter)))
 					if err := jitter(ctx, jitterDuration); err != nil {
 						if !errors.Is(err, context.Canceled) {
 							rs.logger.Sugar().With("error", err).Error("random jitter failed")
@@ -51,7 +51,9 @@ func (rs *ragServer) ProcessScreenings(ctx context.Context) func() {
 }
 
 const (
-	processScreeningTimeout = 30 * time.Minute
+	screeningProcessInterval = 1 * time.Second
+	screeningMaxJitter       = 100 * time.Millisecond
+	processScreeningTimeout  = 30 * time.Minute
 )
 
 func (rs *ragServer) processScreenings(ctx context.Context) (int, error) {
@@ -167,64 +169,84 @@ func (rs *ragServer) processScreening(ctx context.Context, aScreening *Screening
 }
 
 func (rs *ragServer) answwerQuestion(ctx context.Context, aQuestion *Question, fileIDs ...FileID) error {
+	response, err := rs.generateResponse(ctx, *aQuestion, DocumentFilter{FileIDs: fileIDs}, nil)
+	if err != nil {
+		return err
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("marshaling response: %v", err)
+	}
+
+	if err := rs.store.SaveAnswer(ctx, Answer{
+		QuestionID: aQuestion.ID,
+		Response:   string(jsonResponse),
+		Created:    rs.now(),
+	}); err != nil {
+		return fmt.Errorf("saving answer: %w", err)
+	}
+
+	return nil
+}
+
+// generateResponse embeds the question, retrieves the most relevant documents from files of the filter
+// and asks the generative model to answer the question using those documents as context. When onPartial
+// is not nil and the generative model supports streaming, partial output is passed to it as it arrives.
+// Documents are also filtered by language and metadata of the filter.
+func (rs *ragServer) generateResponse(ctx context.Context, aQuestion Question, filter DocumentFilter, onPartial func(text string) error) (Response, error) {
 	switch aQuestion.Type {
 	case QuestionTypeText, QuestionTypeMetric, QuestionTypeBoolean:
 	default:
-		return fmt.Errorf("invalid question type: %s", aQuestion.Type)
+		return Response{}, fmt.Errorf("invalid question type: %s", aQuestion.Type)
 	}
 
-	_, err := rs.processedFilesFromIDs(ctx, fileIDs...)
+	_, err := rs.processedFilesFromIDs(ctx, filter.FileIDs...)
 	if err != nil {
-		return err
+		return Response{}, err
 	}
 
-	rs.logger.Sugar().With("question", aQuestion.ID, "file_ids", fileIDs).Info("generating answer for question")
+	rs.logger.Sugar().With("question", aQuestion.ID, "file_ids", filter.FileIDs).Info("generating answer for question")
 
 	// Embed the query contents.
 	vector, err := rs.embedder.EmbedContent(ctx, aQuestion.Content)
 	if err != nil {
-		return fmt.Errorf("embedding query content: %v", err)
+		return Response{}, fmt.Errorf("embedding query content: %v", err)
 	}
 
 	// Search redis/weaviate to find the most relevant (closest in vector space)
 	// documents to the query.
 	documents, err := rs.retriever.SearchDocuments(ctx, DocumentFilter{
-		Vector:  vector,
-		FileIDs: fileIDs,
+		Vector:   vector,
+		FileIDs:  filter.FileIDs,
+		Language: filter.Language,
+		Metadata: filter.Metadata,
 	}, 25)
 	if err != nil {
-		return fmt.Errorf("searching documents: %v", err)
+		return Response{}, fmt.Errorf("searching documents: %v", err)
 	}
 
 	if len(documents) == 0 {
-		return fmt.Errorf("no documents found for question: %s", aQuestion)
+		return Response{}, fmt.Errorf("no documents found for question: %s", aQuestion.Content)
 	}
 
 	rs.logger.Sugar().With("question", aQuestion.ID).Infof("found %d documents", len(documents))
 
-	responses, err := rs.generative.Generate(ctx, *aQuestion, documents)
-	if err != nil {
-		return fmt.Errorf("calling generative model: %v", err)
+	var responses []Response
+	if streaming, ok := rs.generative.(StreamingGenerativeModel); ok && onPartial != nil {
+		responses, err = streaming.StreamGenerate(ctx, aQuestion, documents, onPartial)
+	} else {
+		responses, err = rs.generative.Generate(ctx, aQuestion, documents)
 	}
-
-	if len(responses) != 1 {
-		return fmt.Errorf("expected 1 response, got %d", len(responses))
-	}
-
-	jsonResponse, err := json.Marshal(responses[0])
 	if err != nil {
-		return fmt.Errorf("marshaling response: %v", err)
+		return Response{}, fmt.Errorf("calling generative model: %v", err)
 	}
 
-	if err := rs.store.SaveAnswer(ctx, Answer{
-		QuestionID: aQuestion.ID,
-		Response:   string(jsonResponse),
-		Created:    rs.now(),
-	}); err != nil {
-		return fmt.Errorf("saving answer: %w", err)
+	if len(responses) != 1 {
+		return Response{}, fmt.Errorf("expected 1 response, got %d", len(responses))
 	}
 
-	return nil
+	return responses[0], nil
 }
 
 func (rs *ragServer) processingScreeningSucceeded(ctx context.Context, aScreening *Screening) error {
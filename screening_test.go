@@ -1,11 +1,15 @@
 package ragserver
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
 )
 
 func TestScreening_CompleteWithStatus(t *testing.T) {
@@ -68,3 +72,68 @@ func TestScreening_CompleteWithStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestRagServer_CreateScreening(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+		newFile   = func() *File {
+			return &File{
+				ID:        NewFileID(),
+				Status:    FileStatusProcessedSuccessfully,
+				Embedder:  "fake-embedder",
+				Retriever: "fake-retriever",
+			}
+		}
+		file1     = newFile()
+		file2     = newFile()
+		aDocument = &LogicalDocument{ID: NewLogicalDocumentID(), Name: "Annual report"}
+		questions = []Question{{Type: QuestionTypeText, Content: "What is the revenue?"}}
+	)
+	require.NoError(t, aDocument.AddVersion(file1, time.Now()))
+
+	store := newFakeStore(file1, file2)
+	store.documents[aDocument.ID] = aDocument
+	rs := newTestRagServer(store)
+
+	t.Run("Latest version of a logical document is added", func(t *testing.T) {
+		aScreening, err := rs.CreateScreening(ctx, principal, ScreeningParams{
+			FileIDs:            []FileID{file2.ID},
+			LogicalDocumentIDs: []LogicalDocumentID{aDocument.ID},
+			Questions:          questions,
+		})
+		require.NoError(t, err)
+		require.Len(t, aScreening.Files, 2)
+		assert.Equal(t, file2.ID, aScreening.Files[0].ID)
+		assert.Equal(t, file1.ID, aScreening.Files[1].ID)
+	})
+
+	t.Run("File which is also the latest version is added once", func(t *testing.T) {
+		aScreening, err := rs.CreateScreening(ctx, principal, ScreeningParams{
+			FileIDs:            []FileID{file1.ID},
+			LogicalDocumentIDs: []LogicalDocumentID{aDocument.ID},
+			Questions:          questions,
+		})
+		require.NoError(t, err)
+		require.Len(t, aScreening.Files, 1)
+		assert.Equal(t, file1.ID, aScreening.Files[0].ID)
+	})
+
+	t.Run("Duplicate file IDs are rejected", func(t *testing.T) {
+		_, err := rs.CreateScreening(ctx, principal, ScreeningParams{
+			FileIDs:   []FileID{file1.ID, file1.ID},
+			Questions: questions,
+		})
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	})
+
+	t.Run("Logical document does not exist", func(t *testing.T) {
+		_, err := rs.CreateScreening(ctx, principal, ScreeningParams{
+			LogicalDocumentIDs: []LogicalDocumentID{NewLogicalDocumentID()},
+			Questions:          questions,
+		})
+		assert.ErrorIs(t, err, ErrInvalidLogicalDocument)
+	})
+}
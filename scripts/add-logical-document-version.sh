@@ -0,0 +1,18 @@
+#!/bin/bash
+
+set -eu
+
+# Check if arguments are provided
+if [ $# -lt 2 ]; then
+    echo "Usage: $0 '<logical document ID>' '<file ID>'"
+    exit 1
+fi
+
+# Add a file as the latest version of a logical document
+DOCUMENT_ID=$1
+FILE_ID=$2
+
+curl -X POST \
+    -H 'Content-Type: application/json' \
+    -d "$(jq -n --arg file_id "$FILE_ID" '{file_id: $file_id}')" \
+    http://localhost:8080/logical-documents/${DOCUMENT_ID}/versions -s | jq .
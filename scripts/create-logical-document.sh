@@ -0,0 +1,18 @@
+#!/bin/bash
+
+set -eu
+
+# Check if an argument is provided
+if [ $# -eq 0 ]; then
+    echo "Usage: $0 '<logical document name>' ['<file ID>' ...]"
+    exit 1
+fi
+
+# Create a logical document, files are added as its versions from the oldest
+NAME=$1
+shift
+
+curl -X POST \
+    -H 'Content-Type: application/json' \
+    -d "$(jq -n --arg name "$NAME" '{name: $name, file_ids: $ARGS.positional}' --args "$@")" \
+    http://localhost:8080/logical-documents -s | jq .
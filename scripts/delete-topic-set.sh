@@ -0,0 +1,9 @@
+#!/bin/bash
+
+set -eu
+
+NAME=$1
+
+curl -X DELETE \
+    -H 'Content-Type: application/json' \
+    http://localhost:8080/topic-sets/${NAME}
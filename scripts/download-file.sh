@@ -0,0 +1,14 @@
+#!/bin/bash
+
+set -eu
+
+FILE_ID=$1
+OUTPUT=${2:-}
+
+if [ -z "$OUTPUT" ]; then
+  curl -X GET -O -J \
+      http://localhost:8080/files/${FILE_ID}/content
+else
+  curl -X GET -o "$OUTPUT" \
+      http://localhost:8080/files/${FILE_ID}/content
+fi
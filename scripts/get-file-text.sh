@@ -0,0 +1,19 @@
+#!/bin/bash
+
+set -eu
+
+FILE_ID=$1
+FORMAT=text
+
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --markdown)
+      FORMAT=markdown
+      ;;
+  esac
+  shift
+done
+
+curl -X GET -G \
+    --data-urlencode "format=${FORMAT}" \
+    http://localhost:8080/files/${FILE_ID}/text
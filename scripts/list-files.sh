@@ -2,6 +2,14 @@
 
 set -eu
 
+# Files can be filtered by metadata conditions such as 'company:Acme|Globex' or 'reporting_year>=2023'
+QUERY=()
+for condition in "$@"; do
+    QUERY+=(--data-urlencode "metadata=$condition")
+done
+
 curl \
+    -G \
     -H 'Content-Type: application/json' \
+    ${QUERY[@]+"${QUERY[@]}"} \
     http://localhost:8080/files | jq .
@@ -0,0 +1,7 @@
+#!/bin/bash
+
+set -eu
+
+curl \
+    -H 'Content-Type: application/json' \
+    http://localhost:8080/topic-sets | jq .
@@ -0,0 +1,13 @@
+#!/bin/bash
+
+set -eu
+
+# Pass --all to reprocess files processed with the current embedder and retriever as well
+ALL=false
+if [ "${1:-}" == "--all" ]; then
+    ALL=true
+fi
+
+curl -X POST \
+    -H 'Content-Type: application/json' \
+    "http://localhost:8080/files/reindex?all=${ALL}" | jq .
@@ -0,0 +1,9 @@
+#!/bin/bash
+
+set -eu
+
+FILE_ID=$1
+
+curl -X POST \
+    -H 'Content-Type: application/json' \
+    http://localhost:8080/files/${FILE_ID}/reprocess | jq .
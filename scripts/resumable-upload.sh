@@ -0,0 +1,45 @@
+#!/bin/bash
+
+set -eu
+
+# Check if an argument is provided
+if [ $# -eq 0 ]; then
+    echo "Usage: $0 '<your file>' [chunk size in bytes]"
+    exit 1
+fi
+
+# Upload a file to the ragserver in chunks, resuming from the last received byte if a chunk fails
+FILE=$1
+CHUNK_SIZE=${2:-5242880}
+
+size=$(wc -c < "$FILE" | tr -d ' ')
+
+upload_url=$(curl -X POST \
+    -H 'Content-Type: application/json' \
+    -d "$(jq -n --arg name "$(basename "$FILE")" --argjson size "$size" '{file_name: $name, size: $size}')" \
+    http://localhost:8080/uploads -s -D - -o /dev/null | grep -i '^location:' | awk '{print $2}' | tr -d '\r');
+
+upload_url="http://localhost:8080$upload_url"
+
+printf "\nUploading $size bytes to '$upload_url'\n"
+
+offset=0
+file_id=""
+while [ "$offset" -lt "$size" ];
+do
+    if response=$(tail -c +$((offset + 1)) "$FILE" | head -c "$CHUNK_SIZE" | curl -X PATCH \
+        -H 'Content-Type: application/offset+octet-stream' \
+        -H "Upload-Offset: $offset" \
+        --data-binary @- \
+        -s -f \
+        "$upload_url"); then
+        file_id=$(echo "$response" | jq -r '.file_id // empty');
+    else
+        printf "\nChunk failed, resuming\n"
+        sleep 1
+    fi;
+    offset=$(curl -I -s "$upload_url" | grep -i '^upload-offset:' | awk '{print $2}' | tr -d '\r');
+    printf "\r$(date +%H:%M:%S): $offset/$size bytes";
+done
+
+printf "\nUploaded a file with ID $file_id\n"
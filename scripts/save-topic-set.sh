@@ -0,0 +1,18 @@
+#!/bin/bash
+
+set -eu
+
+# Check if arguments are provided
+if [ $# -lt 2 ]; then
+    echo "Usage: $0 '<topic set name>' '<topics JSON array>'"
+    exit 1
+fi
+
+# Create a topic set preset or replace topics of an existing one
+NAME=$1
+TOPICS=$2
+
+curl -X PUT \
+    -H 'Content-Type: application/json' \
+    -d "$(jq -n --argjson topics "$TOPICS" '{topics: $topics}')" \
+    http://localhost:8080/topic-sets/${NAME} -s | jq .
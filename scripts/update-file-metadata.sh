@@ -0,0 +1,18 @@
+#!/bin/bash
+
+set -eu
+
+# Check if arguments are provided
+if [ $# -lt 2 ]; then
+    echo "Usage: $0 '<file ID>' '<metadata JSON object>'"
+    exit 1
+fi
+
+FILE_ID=$1
+METADATA=$2
+
+echo "{\"metadata\": $METADATA}" | curl \
+    -X PATCH \
+    -H 'Content-Type: application/json' \
+    -d @- \
+    http://localhost:8080/files/${FILE_ID} | jq .
@@ -0,0 +1,17 @@
+#!/bin/bash
+
+set -eu
+
+# Check if an argument is provided
+if [ $# -eq 0 ]; then
+    echo "Usage: $0 '<your ZIP archive>'"
+    exit 1
+fi
+
+# Upload a ZIP archive to the ragserver, a file is created from each entry
+ARCHIVE=$1
+
+curl -X POST \
+    -H 'Content-Type: multipart/form-data' \
+    -F file=@"$ARCHIVE" \
+    http://localhost:8080/files/archive -s | jq '.results[] | {file_name, id: .file.id, error}'
@@ -4,16 +4,21 @@ set -eu
 
 # Check if an argument is provided
 if [ $# -eq 0 ]; then
-    echo "Usage: $0 '<your file>'"
+    echo "Usage: $0 '<your file>' ['<topic set name>'] ['<metadata JSON object>']"
     exit 1
 fi
 
-# Upload a file to the ragserver and capture the uploaded file ID
+# Upload a file to the ragserver and capture the uploaded file ID,
+# documents are filtered by topics of the optional topic set preset
 FILE=$1
+TOPIC_SET=${2:-}
+METADATA=${3:-}
 
 file_id=$(curl -X POST \
     -H 'Content-Type: multipart/form-data' \
     -F file=@"$FILE" \
+    -F topic_set="$TOPIC_SET" \
+    -F metadata="$METADATA" \
     http://localhost:8080/files -s | jq -r ".id");
 
 printf "\nUploading a file with ID $file_id\n"
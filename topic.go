@@ -0,0 +1,298 @@
+package ragserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+var ErrInvalidTopics = errors.New("invalid topics")
+
+// Topic decides whether content is relevant. Content is relevant if it contains at least MinMatches
+// of the keywords and patterns, and none of the exclude keywords. Keywords are matched as case
+// insensitive substrings, patterns are case insensitive regular expressions. Description is used
+// by semantic relevance filters instead of keywords and patterns, see RelevanceFilter. Topics with
+// only a description can't be used with KeywordFilter, see TopicValidator.
+type Topic struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description,omitempty"`
+	Keywords        []string `json:"keywords,omitempty"`
+	Patterns        []string `json:"patterns,omitempty"`
+	ExcludeKeywords []string `json:"exclude_keywords,omitempty"`
+	MinMatches      int      `json:"min_matches,omitempty"` // zero means a single match is enough
+}
+
+// Validate checks the topic has something to match and its patterns compile.
+func (t Topic) Validate() error {
+	if len(t.Keywords) == 0 && len(t.Patterns) == 0 && strings.TrimSpace(t.Description) == "" {
+		return fmt.Errorf("%w: topic %q has no keywords, patterns or description", ErrInvalidTopics, t.Name)
+	}
+	for _, pattern := range t.Patterns {
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("%w: topic %q has invalid pattern: %v", ErrInvalidTopics, t.Name, err)
+		}
+	}
+	if t.MinMatches < 0 || t.MinMatches > len(t.Keywords)+len(t.Patterns) {
+		return fmt.Errorf("%w: topic %q min matches must be between 0 and the number of keywords and patterns", ErrInvalidTopics, t.Name)
+	}
+	return nil
+}
+
+// Excludes returns true if the content contains any of the exclude keywords, case insensitive.
+func (t Topic) Excludes(content string) bool {
+	lowerContent := strings.ToLower(content)
+	for _, keyword := range t.ExcludeKeywords {
+		if strings.Contains(lowerContent, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Label describes the topic for semantic relevance filters, topics without a description
+// are described by their name and keywords.
+func (t Topic) Label() string {
+	if description := strings.TrimSpace(t.Description); description != "" {
+		return description
+	}
+	if len(t.Keywords) == 0 {
+		return t.Name
+	}
+	return t.Name + ": " + strings.Join(t.Keywords, ", ")
+}
+
+// compilePattern compiles a case insensitive pattern.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// topicMatcher matches lowercase content against a topic with its keywords lowercased and
+// patterns compiled, so they are prepared once rather than for every document.
+type topicMatcher struct {
+	topic      *Topic
+	keywords   []string
+	patterns   []*regexp.Regexp
+	minMatches int
+}
+
+// TopicMatcher matches content against keywords and patterns of topics. Create one with
+// RelevantTopics.Matcher and reuse it for all documents being classified.
+type TopicMatcher struct {
+	matchers []topicMatcher
+}
+
+// Matcher prepares topics to be matched against many documents. Invalid patterns are skipped,
+// topics are validated before they are used.
+func (rt RelevantTopics) Matcher() TopicMatcher {
+	matchers := make([]topicMatcher, 0, len(rt))
+	for i := range rt {
+		m := topicMatcher{
+			topic:      &rt[i],
+			minMatches: max(rt[i].MinMatches, 1),
+		}
+		for _, keyword := range rt[i].Keywords {
+			m.keywords = append(m.keywords, strings.ToLower(keyword))
+		}
+		for _, pattern := range rt[i].Patterns {
+			if re, err := compilePattern(pattern); err == nil {
+				m.patterns = append(m.patterns, re)
+			}
+		}
+		matchers = append(matchers, m)
+	}
+	return TopicMatcher{matchers: matchers}
+}
+
+// IsRelevant returns the first topic the content is relevant to.
+func (tm TopicMatcher) IsRelevant(content string) (Topic, bool) {
+	if topic := tm.match(strings.ToLower(content)); topic != nil {
+		return *topic, true
+	}
+	return Topic{}, false
+}
+
+// match returns the first topic the lowercase content is relevant to, nil if there is none.
+func (tm TopicMatcher) match(lowerContent string) *Topic {
+	for _, m := range tm.matchers {
+		if m.matches(lowerContent) {
+			return m.topic
+		}
+	}
+	return nil
+}
+
+// matches returns true if the lowercase content is relevant to the topic.
+func (m topicMatcher) matches(lowerContent string) bool {
+	if m.topic.Excludes(lowerContent) {
+		return false
+	}
+
+	matches := 0
+	for _, keyword := range m.keywords {
+		if strings.Contains(lowerContent, keyword) {
+			matches += 1
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(lowerContent) {
+			matches += 1
+		}
+	}
+
+	return matches >= m.minMatches
+}
+
+type RelevantTopics []Topic
+
+// Validate checks all topics, see Topic.Validate.
+func (rt RelevantTopics) Validate() error {
+	for _, topic := range rt {
+		if err := topic.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTopics checks topics and that the relevance filter can classify documents by them.
+func (rs *ragServer) validateTopics(topics RelevantTopics) error {
+	if err := topics.Validate(); err != nil {
+		return err
+	}
+	if validator, ok := rs.relevanceFilter.(TopicValidator); ok {
+		return validator.ValidateTopics(topics)
+	}
+	return nil
+}
+
+// TopicSet is a named preset of topics stored so it can be picked when uploading files. A copy of
+// the topic set is recorded on each file uploaded with it, Name is empty for topics sent with an upload.
+type TopicSet struct {
+	Name    string         `json:"name,omitempty"`
+	Topics  RelevantTopics `json:"topics"`
+	Created time.Time      `json:"-"`
+	Updated time.Time      `json:"-"`
+}
+
+// sameTopics returns true if documents are filtered by the same topics, regardless of the preset name.
+// Nil topic sets stand for topics configured on the server.
+func (ts *TopicSet) sameTopics(other *TopicSet) bool {
+	if ts == nil || other == nil {
+		return ts == nil && other == nil
+	}
+	return reflect.DeepEqual(ts.Topics, other.Topics)
+}
+
+// TopicSelection selects topics documents of new files are filtered by, either a preset by name or
+// topics sent with the upload. Files uploaded without either use topics configured with WithRelevantTopics.
+type TopicSelection struct {
+	Preset string
+	Topics RelevantTopics
+}
+
+// resolveTopics returns a copy of topics to record on new files, nil if none were selected.
+func (rs *ragServer) resolveTopics(ctx context.Context, selection TopicSelection) (*TopicSet, error) {
+	switch {
+	case selection.Preset != "" && len(selection.Topics) > 0:
+		return nil, fmt.Errorf("%w: either a preset or topics can be selected, not both", ErrInvalidTopics)
+	case selection.Preset != "":
+		var preset *TopicSet
+		if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+			var err error
+			preset, err = rs.store.FindTopicSet(ctx, selection.Preset)
+			return err
+		}); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: topic set %q not found", ErrInvalidTopics, selection.Preset)
+			}
+			return nil, err
+		}
+		return &TopicSet{Name: preset.Name, Topics: preset.Topics}, nil
+	case len(selection.Topics) > 0:
+		if err := rs.validateTopics(selection.Topics); err != nil {
+			return nil, err
+		}
+		return &TopicSet{Topics: selection.Topics}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// SaveTopicSet creates a topic set preset or replaces topics of an existing one with the same name.
+// Files already uploaded with the preset keep the topics they were uploaded with.
+func (rs *ragServer) SaveTopicSet(ctx context.Context, principal authz.Principal, aTopicSet *TopicSet) (*TopicSet, error) {
+	rs.logger.Sugar().With("name", aTopicSet.Name).Info("saving topic set")
+
+	if strings.TrimSpace(aTopicSet.Name) == "" {
+		return nil, fmt.Errorf("%w: missing topic set name", ErrInvalidTopics)
+	}
+	if len(aTopicSet.Topics) == 0 {
+		return nil, fmt.Errorf("%w: topic set has no topics", ErrInvalidTopics)
+	}
+	if err := rs.validateTopics(aTopicSet.Topics); err != nil {
+		return nil, err
+	}
+
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		existing, err := rs.store.FindTopicSet(ctx, aTopicSet.Name)
+		switch {
+		case err == nil:
+			aTopicSet.Created = existing.Created
+		case errors.Is(err, ErrNotFound):
+			aTopicSet.Created = rs.now()
+		default:
+			return err
+		}
+		aTopicSet.Updated = rs.now()
+
+		return rs.store.SaveTopicSet(ctx, aTopicSet)
+	}); err != nil {
+		return nil, err
+	}
+
+	return aTopicSet, nil
+}
+
+func (rs *ragServer) ListTopicSets(ctx context.Context, principal authz.Principal) ([]*TopicSet, error) {
+	var topicSets []*TopicSet
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		topicSets, err = rs.store.ListTopicSets(ctx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return topicSets, nil
+}
+
+func (rs *ragServer) FindTopicSet(ctx context.Context, principal authz.Principal, name string) (*TopicSet, error) {
+	var aTopicSet *TopicSet
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		aTopicSet, err = rs.store.FindTopicSet(ctx, name)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return aTopicSet, nil
+}
+
+func (rs *ragServer) DeleteTopicSet(ctx context.Context, principal authz.Principal, name string) error {
+	rs.logger.Sugar().With("name", name).Info("deleting topic set")
+
+	return rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		if _, err := rs.store.FindTopicSet(ctx, name); err != nil {
+			return err
+		}
+		return rs.store.DeleteTopicSet(ctx, name)
+	})
+}
@@ -0,0 +1,239 @@
+package ragserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicMatcher_IsRelevant(t *testing.T) {
+	t.Parallel()
+
+	var (
+		scope = Topic{
+			Name:     "scope",
+			Keywords: []string{"scope 1", "scope 2"},
+		}
+		emissions = Topic{
+			Name:            "emissions",
+			Keywords:        []string{"emissions"},
+			Patterns:        []string{`\bghg\b`, `\d+(\.\d+)? ?tco2e`},
+			ExcludeKeywords: []string{"table of contents"},
+			MinMatches:      2,
+		}
+		netZero = Topic{
+			Name:        "net zero",
+			Description: "targets to reach net zero emissions",
+		}
+	)
+
+	tests := []struct {
+		name          string
+		topics        RelevantTopics
+		content       string
+		expectedTopic Topic
+		expected      bool
+	}{
+		{
+			"no topics",
+			nil,
+			"Scope 1 emissions",
+			Topic{},
+			false,
+		},
+		{
+			"keyword matches case insensitive",
+			RelevantTopics{scope},
+			"Our SCOPE 1 emissions decreased.",
+			scope,
+			true,
+		},
+		{
+			"no keyword matches",
+			RelevantTopics{scope},
+			"Our revenue increased.",
+			Topic{},
+			false,
+		},
+		{
+			"keyword and pattern match",
+			RelevantTopics{emissions},
+			"Total emissions were 120.5 tCO2e.",
+			emissions,
+			true,
+		},
+		{
+			"patterns match",
+			RelevantTopics{emissions},
+			"GHG inventory of 100 tCO2e.",
+			emissions,
+			true,
+		},
+		{
+			"fewer than min matches",
+			RelevantTopics{emissions},
+			"Emissions are discussed later.",
+			Topic{},
+			false,
+		},
+		{
+			"pattern matches whole words only",
+			RelevantTopics{emissions},
+			"Emissions of the ghgx category.",
+			Topic{},
+			false,
+		},
+		{
+			"excluded keyword",
+			RelevantTopics{emissions},
+			"Table of Contents: GHG emissions ... 12",
+			Topic{},
+			false,
+		},
+		{
+			"first relevant topic",
+			RelevantTopics{emissions, scope},
+			"Scope 1 emissions were 100 tCO2e.",
+			emissions,
+			true,
+		},
+		{
+			"topic without keywords and patterns doesn't match",
+			RelevantTopics{netZero},
+			"Our targets to reach net zero emissions are set.",
+			Topic{},
+			false,
+		},
+		{
+			"next topic if first is excluded",
+			RelevantTopics{emissions, scope},
+			"Table of contents: scope 1 emissions, 100 tCO2e",
+			scope,
+			true,
+		},
+		{
+			"invalid patterns don't match",
+			RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}, Patterns: []string{`scope [1-3`}}},
+			"Scope [1-3 emissions",
+			Topic{},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, ok := tt.topics.Matcher().IsRelevant(tt.content)
+			assert.Equal(t, tt.expected, ok)
+			assert.Equal(t, tt.expectedTopic, topic)
+		})
+	}
+}
+
+func TestRelevantTopics_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		topics RelevantTopics
+		err    string
+	}{
+		{
+			"valid",
+			RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}, Patterns: []string{`scope [1-3]`}, MinMatches: 2}},
+			"",
+		},
+		{
+			"description only",
+			RelevantTopics{{Name: "scope", Description: "direct greenhouse gas emissions"}},
+			"",
+		},
+		{
+			"nothing to match",
+			RelevantTopics{{Name: "scope", ExcludeKeywords: []string{"scope 3"}}},
+			`invalid topics: topic "scope" has no keywords, patterns or description`,
+		},
+		{
+			"invalid pattern",
+			RelevantTopics{{Name: "scope", Patterns: []string{`scope [1-3`}}},
+			"invalid topics: topic \"scope\" has invalid pattern: error parsing regexp: missing closing ]: `[1-3`",
+		},
+		{
+			"min matches exceeds keywords and patterns",
+			RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}, MinMatches: 2}},
+			`invalid topics: topic "scope" min matches must be between 0 and the number of keywords and patterns`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.topics.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidTopics)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRagServer_ValidateTopics(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keywords    = RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}}}
+		description = RelevantTopics{{Name: "scope", Description: "direct greenhouse gas emissions"}}
+	)
+
+	tests := []struct {
+		name   string
+		filter RelevanceFilter
+		topics RelevantTopics
+		err    string
+	}{
+		{"keywords with keyword filter", KeywordFilter{}, keywords, ""},
+		{
+			"description only with keyword filter",
+			KeywordFilter{},
+			description,
+			`invalid topics: topic "scope" has no keywords or patterns, a description is only used by semantic relevance filters`,
+		},
+		{"description only with embedding filter", NewEmbeddingFilter(fakeEmbedder{}, 0.5), description, ""},
+		{
+			"invalid topic with embedding filter",
+			NewEmbeddingFilter(fakeEmbedder{}, 0.5),
+			RelevantTopics{{Name: "scope"}},
+			`invalid topics: topic "scope" has no keywords, patterns or description`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rs := newTestRagServer(newFakeStore())
+			rs.relevanceFilter = tt.filter
+
+			_, err := rs.resolveTopics(context.Background(), TopicSelection{Topics: tt.topics})
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidTopics)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestTopicSet_SameTopics(t *testing.T) {
+	t.Parallel()
+
+	topics := RelevantTopics{{Name: "scope", Keywords: []string{"scope 1"}}}
+
+	assert.True(t, (*TopicSet)(nil).sameTopics(nil))
+	assert.False(t, (*TopicSet)(nil).sameTopics(&TopicSet{Topics: topics}))
+	assert.False(t, (&TopicSet{Topics: topics}).sameTopics(nil))
+	assert.True(t, (&TopicSet{Name: "preset", Topics: topics}).sameTopics(&TopicSet{Topics: topics}))
+	assert.False(t, (&TopicSet{Topics: topics}).sameTopics(&TopicSet{Topics: RelevantTopics{{Name: "scope", Keywords: []string{"scope 2"}}}}))
+}
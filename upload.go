@@ -0,0 +1,452 @@
+package ragserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+var (
+	ErrInvalidUpload        = errors.New("invalid upload")
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+	ErrUploadCompleted      = errors.New("upload already completed")
+	ErrUploadLocked         = errors.New("upload is being written by another request")
+	ErrUploadExpired        = errors.New("upload expired")
+)
+
+const (
+	defaultUploadExpiry = 24 * time.Hour
+	// uploadLockDuration is how long a request writing a chunk holds the upload, it must be
+	// longer than a request is allowed to take so a lock only expires after its request failed.
+	uploadLockDuration = 10 * time.Minute
+	uploadCleanupLimit = 100
+)
+
+type UploadID struct{ uuid.UUID }
+
+func NewUploadID() UploadID {
+	return UploadID{uuid.Must(uuid.NewV4())}
+}
+
+// Upload is a file uploaded in chunks which can be resumed after a failed request. Chunks are
+// appended to a temp file and the SHA-256 state is saved with each chunk, so the hash doesn't need
+// to be calculated again once all bytes are received and a file is created.
+//
+// Uploads which don't receive a chunk before they expire are deleted with bytes received so far.
+type Upload struct {
+	ID          UploadID
+	AuthorID    AuthorID
+	FileName    string
+	Size        int64     // total size declared when the upload was created
+	Offset      int64     // number of bytes received so far
+	TempFile    string    // name of the temp file the bytes are written to
+	HashState   []byte    // marshalled SHA-256 state of bytes received so far
+	FileID      FileID    // file created once all bytes are received
+	Topics      *TopicSet // topics recorded on the file, see TopicSelection
+	Metadata    Metadata  // metadata recorded on the file
+	Expires     time.Time // extended with each chunk received
+	LockID      uuid.UUID // request writing a chunk, see WriteUpload
+	LockedUntil time.Time // lock of the request writing a chunk expires after
+	Created     time.Time
+	Updated     time.Time
+}
+
+type UploadFilter struct {
+	ExpiresBefore time.Time
+	Lock          bool
+}
+
+// Completed returns true when all bytes have been received and a file has been created.
+func (u *Upload) Completed() bool {
+	return !u.FileID.UUID.IsNil()
+}
+
+// Expired returns true when the upload didn't receive a chunk in time.
+func (u *Upload) Expired(now time.Time) bool {
+	return !now.Before(u.Expires)
+}
+
+// locked returns true while a request holds the upload to write a chunk.
+func (u *Upload) locked(now time.Time) bool {
+	return !u.LockID.IsNil() && now.Before(u.LockedUntil)
+}
+
+// write appends contents of data at the current offset, up to the declared size, and updates
+// the offset and hash state. Offset and hash state reflect bytes written to dst even if reading
+// from data fails part way through, so the upload can be resumed from there.
+func (u *Upload) write(dst io.WriteSeeker, data io.Reader) error {
+	hashWriter := sha256.New()
+	if err := hashWriter.(encoding.BinaryUnmarshaler).UnmarshalBinary(u.HashState); err != nil {
+		return fmt.Errorf("error restoring hash state: %w", err)
+	}
+
+	if _, err := dst.Seek(u.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("error seeking to offset: %w", err)
+	}
+
+	written, copyErr := io.CopyN(io.MultiWriter(dst, hashWriter), data, u.Size-u.Offset)
+
+	hashState, err := marshalHash(hashWriter)
+	if err != nil {
+		return err
+	}
+	u.Offset += written
+	u.HashState = hashState
+
+	// Chunks smaller than the remaining size are expected
+	if copyErr != nil && !errors.Is(copyErr, io.EOF) {
+		return fmt.Errorf("error writing upload: %w", copyErr)
+	}
+
+	// Any more data would go over the declared size
+	if u.Offset == u.Size {
+		if n, _ := data.Read(make([]byte, 1)); n > 0 {
+			return fmt.Errorf("%w: data exceeds declared size of %d bytes", ErrInvalidUpload, u.Size)
+		}
+	}
+
+	return nil
+}
+
+// hash returns hex encoded SHA-256 of bytes received so far.
+func (u *Upload) hash() (string, error) {
+	hashWriter := sha256.New()
+	if err := hashWriter.(encoding.BinaryUnmarshaler).UnmarshalBinary(u.HashState); err != nil {
+		return "", fmt.Errorf("error restoring hash state: %w", err)
+	}
+	return hex.EncodeToString(hashWriter.Sum(nil)), nil
+}
+
+func marshalHash(hashWriter hash.Hash) ([]byte, error) {
+	state, err := hashWriter.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("error saving hash state: %w", err)
+	}
+	return state, nil
+}
+
+// CreateUpload starts a resumable upload of a file of the given size. Contents are sent
+// in one or more chunks with WriteUpload.
+func (rs *ragServer) CreateUpload(ctx context.Context, principal authz.Principal, fileName string, size int64, params FileParams) (*Upload, error) {
+	rs.logger.Sugar().With("filename", fileName, "size", size).Info("creating upload")
+
+	if strings.TrimSpace(fileName) == "" {
+		return nil, fmt.Errorf("%w: missing file name", ErrInvalidUpload)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: size must be positive", ErrInvalidUpload)
+	}
+	if size > rs.maxFileSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrFileTooLarge, size, rs.maxFileSize)
+	}
+
+	if err := rs.validateMetadata(params.Metadata); err != nil {
+		return nil, err
+	}
+
+	// Topics are resolved when the upload starts so it fails early for unknown presets
+	topicSet, err := rs.resolveTopics(ctx, params.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	tempFile, err := rs.filestorage.NewTempFile()
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp file: %v", err)
+	}
+	defer tempFile.Close()
+
+	hashState, err := marshalHash(sha256.New())
+	if err != nil {
+		return nil, err
+	}
+
+	anUpload := &Upload{
+		ID:        NewUploadID(),
+		AuthorID:  AuthorID{principal.ID().UUID},
+		FileName:  fileName,
+		Size:      size,
+		TempFile:  tempFile.Name(),
+		HashState: hashState,
+		Topics:    topicSet,
+		Metadata:  params.Metadata,
+		Expires:   rs.now().Add(rs.uploadExpiry),
+		Created:   rs.now(),
+		Updated:   rs.now(),
+	}
+
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		if err := rs.store.SavePrincipal(ctx, principal); err != nil {
+			return fmt.Errorf("error saving principal: %w", err)
+		}
+
+		if err := rs.store.SaveUploads(ctx, anUpload); err != nil {
+			return fmt.Errorf("error saving upload: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		rs.filestorage.DeleteTempFile(tempFile.Name())
+		return nil, fmt.Errorf("error saving upload: %v", err)
+	}
+
+	return anUpload, nil
+}
+
+func (rs *ragServer) FindUpload(ctx context.Context, principal authz.Principal, id UploadID) (*Upload, error) {
+	var anUpload *Upload
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		anUpload, err = rs.store.FindUpload(ctx, id, rs.uploadPartial(principal), false)
+		if err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return anUpload, nil
+}
+
+// WriteUpload appends a chunk of data to the upload. The offset must match the number of bytes
+// received so far, clients which lost track of it after a failed request can find the upload
+// to resume from its offset. Once all bytes are received, a file is created the same way as
+// for a regular upload and its ID is recorded on the upload.
+//
+// Chunks are written to the temp file outside of a transaction so a slow client doesn't hold
+// a database connection. Instead the request locks the upload by recording its lock ID on it,
+// so requests handled by other instances can't write to the same upload at the same time.
+func (rs *ragServer) WriteUpload(ctx context.Context, principal authz.Principal, id UploadID, offset int64, data io.Reader) (*Upload, error) {
+	lockID := uuid.Must(uuid.NewV4())
+
+	var anUpload *Upload
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		anUpload, err = rs.store.FindUpload(ctx, id, rs.uploadPartial(principal), true)
+		if err != nil {
+			return err
+		}
+
+		now := rs.now()
+		switch {
+		case anUpload.Completed():
+			return ErrUploadCompleted
+		case anUpload.Expired(now):
+			return ErrUploadExpired
+		case anUpload.locked(now):
+			return ErrUploadLocked
+		case offset != anUpload.Offset:
+			return fmt.Errorf("%w: expected offset %d, got %d", ErrUploadOffsetMismatch, anUpload.Offset, offset)
+		}
+
+		anUpload.LockID = lockID
+		anUpload.LockedUntil = now.Add(uploadLockDuration)
+		if err := rs.store.SaveUploads(ctx, anUpload); err != nil {
+			return fmt.Errorf("error locking upload: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Bytes written so far are saved even if the request fails part way through
+	aFile, writeErr := rs.writeUpload(principal, anUpload, data)
+
+	// Bytes received before the client disconnected must be saved so the upload can be resumed
+	ctx = context.WithoutCancel(ctx)
+
+	// Resuming the upload wouldn't change contents of the file, so there is no point keeping it
+	if errors.Is(writeErr, ErrInvalidFileType) {
+		if err := rs.deleteUploads(ctx, anUpload); err != nil {
+			rs.logger.Sugar().With("id", anUpload.ID, "error", err).Error("error deleting upload of invalid file type")
+		}
+		return nil, writeErr
+	}
+
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		current, err := rs.store.FindUpload(ctx, id, rs.uploadPartial(principal), true)
+		if err != nil {
+			return err
+		}
+		// The lock expired and another request might have written to the upload since
+		if current.LockID != lockID {
+			return ErrUploadLocked
+		}
+
+		if aFile != nil {
+			if err := rs.saveNewFiles(ctx, principal, aFile); err != nil {
+				return err
+			}
+			anUpload.FileID = aFile.ID
+		}
+
+		now := rs.now()
+		anUpload.LockID = uuid.Nil
+		anUpload.LockedUntil = time.Time{}
+		anUpload.Expires = now.Add(rs.uploadExpiry)
+		anUpload.Updated = now
+		if err := rs.store.SaveUploads(ctx, anUpload); err != nil {
+			return fmt.Errorf("error saving upload: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if anUpload.Completed() {
+		rs.filestorage.DeleteTempFile(anUpload.TempFile)
+	}
+
+	return anUpload, writeErr
+}
+
+// writeUpload appends data to the temp file of the upload and returns a new file once all bytes
+// are received. Offset and hash state of the upload are updated even if writing fails.
+func (rs *ragServer) writeUpload(principal authz.Principal, anUpload *Upload, data io.Reader) (*File, error) {
+	tempFile, err := rs.filestorage.OpenTempFile(anUpload.TempFile)
+	if err != nil {
+		return nil, fmt.Errorf("error opening temp file: %w", err)
+	}
+	defer tempFile.Close()
+
+	if err := anUpload.write(tempFile, data); err != nil {
+		return nil, err
+	}
+
+	if anUpload.Offset < anUpload.Size {
+		return nil, nil
+	}
+
+	return rs.completeUpload(principal, anUpload, tempFile)
+}
+
+// completeUpload stores contents of an upload which received all bytes and returns a new file
+// for it, the file is saved with the upload.
+func (rs *ragServer) completeUpload(principal authz.Principal, anUpload *Upload, tempFile TempFile) (*File, error) {
+	rs.logger.Sugar().With("id", anUpload.ID, "filename", anUpload.FileName, "size", anUpload.Size).Info("completing upload")
+
+	fileHash, err := anUpload.hash()
+	if err != nil {
+		return nil, err
+	}
+
+	aFile, err := rs.storeFile(principal, anUpload.FileName, tempFile, anUpload.Size, fileHash, "")
+	if err != nil {
+		return nil, err
+	}
+	aFile.Topics = anUpload.Topics
+	aFile.Metadata = anUpload.Metadata
+
+	return aFile, nil
+}
+
+// DeleteUpload cancels an upload and deletes bytes received so far. Files created
+// from completed uploads are not deleted.
+func (rs *ragServer) DeleteUpload(ctx context.Context, principal authz.Principal, id UploadID) error {
+	rs.logger.Sugar().With("id", id).Info("deleting upload")
+
+	var anUpload *Upload
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		anUpload, err = rs.store.FindUpload(ctx, id, rs.uploadPartial(principal), true)
+		if err != nil {
+			return err
+		}
+
+		// A chunk being written would save the upload again after it was deleted
+		if anUpload.locked(rs.now()) {
+			return ErrUploadLocked
+		}
+
+		if err := rs.store.DeleteUploads(ctx, anUpload); err != nil {
+			return fmt.Errorf("error deleting upload: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	rs.deleteUploadTempFiles(anUpload)
+
+	return nil
+}
+
+// deleteExpiredUploads deletes uploads which didn't receive a chunk before they expired,
+// together with bytes received so far, and returns how many were deleted.
+func (rs *ragServer) deleteExpiredUploads(ctx context.Context) (int, error) {
+	var uploads []*Upload
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		now := rs.now()
+
+		expired, err := rs.store.ListUploads(ctx, UploadFilter{
+			ExpiresBefore: now,
+			Lock:          true,
+		}, SortParams{
+			Limit: uploadCleanupLimit,
+			Order: SortOrderAsc,
+			By:    `u."expires"`,
+		})
+		if err != nil {
+			return fmt.Errorf("list expired uploads: %w", err)
+		}
+
+		for _, anUpload := range expired {
+			// A request which took too long might still be writing a chunk
+			if !anUpload.locked(now) {
+				uploads = append(uploads, anUpload)
+			}
+		}
+
+		if err := rs.store.DeleteUploads(ctx, uploads...); err != nil {
+			return fmt.Errorf("delete uploads: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	rs.deleteUploadTempFiles(uploads...)
+
+	return len(uploads), nil
+}
+
+// deleteUploads deletes uploads and their temp files.
+func (rs *ragServer) deleteUploads(ctx context.Context, uploads ...*Upload) error {
+	if err := rs.store.Transactional(ctx, &sql.TxOptions{}, func(ctx context.Context) error {
+		return rs.store.DeleteUploads(ctx, uploads...)
+	}); err != nil {
+		return err
+	}
+
+	rs.deleteUploadTempFiles(uploads...)
+
+	return nil
+}
+
+// deleteUploadTempFiles deletes temp files of uploads, completed uploads don't have one anymore.
+func (rs *ragServer) deleteUploadTempFiles(uploads ...*Upload) {
+	for _, anUpload := range uploads {
+		if anUpload.Completed() {
+			continue
+		}
+		if err := rs.filestorage.DeleteTempFile(anUpload.TempFile); err != nil {
+			rs.logger.Sugar().With("id", anUpload.ID, "error", err).Warn("error deleting upload temp file")
+		}
+	}
+}
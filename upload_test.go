@@ -0,0 +1,225 @@
+package ragserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RichardKnop/ragserver/pkg/authz"
+)
+
+// failingReader returns data followed by an error, as a request body does when the client disconnects.
+type failingReader struct {
+	data io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestUpload_Write(t *testing.T) {
+	t.Parallel()
+
+	const contents = "Our total scope 1 emissions were 100 tCO2e."
+
+	newUpload := func(t *testing.T) (*Upload, *os.File) {
+		hashState, err := marshalHash(sha256.New())
+		require.NoError(t, err)
+
+		tempFile, err := os.Create(filepath.Join(t.TempDir(), "upload"))
+		require.NoError(t, err)
+		t.Cleanup(func() { tempFile.Close() })
+
+		return &Upload{Size: int64(len(contents)), HashState: hashState}, tempFile
+	}
+
+	expectedHash := sha256.Sum256([]byte(contents))
+
+	t.Run("Write in chunks", func(t *testing.T) {
+		anUpload, tempFile := newUpload(t)
+
+		for _, chunk := range []string{contents[:10], contents[10:30], contents[30:]} {
+			require.NoError(t, anUpload.write(tempFile, strings.NewReader(chunk)))
+		}
+
+		assert.Equal(t, anUpload.Size, anUpload.Offset)
+
+		fileHash, err := anUpload.hash()
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(expectedHash[:]), fileHash)
+
+		written, err := os.ReadFile(tempFile.Name())
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(written))
+	})
+
+	t.Run("Resume after failed request", func(t *testing.T) {
+		anUpload, tempFile := newUpload(t)
+
+		err := anUpload.write(tempFile, &failingReader{strings.NewReader(contents[:15])})
+		require.Error(t, err)
+		assert.Equal(t, int64(15), anUpload.Offset)
+
+		require.NoError(t, anUpload.write(tempFile, strings.NewReader(contents[15:])))
+
+		fileHash, err := anUpload.hash()
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(expectedHash[:]), fileHash)
+	})
+
+	t.Run("Data over declared size", func(t *testing.T) {
+		anUpload, tempFile := newUpload(t)
+
+		err := anUpload.write(tempFile, strings.NewReader(contents+" This is a decrease."))
+		require.ErrorIs(t, err, ErrInvalidUpload)
+		assert.Equal(t, anUpload.Size, anUpload.Offset)
+
+		written, err := os.ReadFile(tempFile.Name())
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(written))
+	})
+}
+
+func TestRagServer_WriteUpload(t *testing.T) {
+	t.Parallel()
+
+	const contents = "Our total scope 1 emissions were 100 tCO2e."
+
+	var (
+		ctx       = context.Background()
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+	)
+
+	newRagServer := func(t *testing.T) (*ragServer, *fakeStore, *Upload) {
+		store := newFakeStore()
+		rs := newTestRagServer(store)
+		rs.filestorage = fakeFileStorage{contents: map[string][]byte{}, tempDir: t.TempDir()}
+		rs.extractors[ContentTypeText] = fakeExtractor{}
+		rs.maxFileSize = MaxFileSize
+		rs.uploadExpiry = defaultUploadExpiry
+
+		anUpload, err := rs.CreateUpload(ctx, principal, "emissions.txt", int64(len(contents)), FileParams{})
+		require.NoError(t, err)
+
+		return rs, store, anUpload
+	}
+
+	t.Run("Chunks are written and a file is created", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store, anUpload := newRagServer(t)
+
+		written, err := rs.WriteUpload(ctx, principal, anUpload.ID, 0, strings.NewReader(contents[:10]))
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), written.Offset)
+		assert.True(t, written.LockID.IsNil(), "lock is released")
+
+		written, err = rs.WriteUpload(ctx, principal, anUpload.ID, 10, strings.NewReader(contents[10:]))
+		require.NoError(t, err)
+		require.True(t, written.Completed())
+		assert.Contains(t, store.files, written.FileID)
+		assert.NoFileExists(t, anUpload.TempFile)
+	})
+
+	t.Run("Upload locked by another request", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store, anUpload := newRagServer(t)
+		anUpload.LockID = uuid.Must(uuid.NewV4())
+		anUpload.LockedUntil = rs.now().Add(time.Minute)
+		require.NoError(t, store.SaveUploads(ctx, anUpload))
+
+		_, err := rs.WriteUpload(ctx, principal, anUpload.ID, 0, strings.NewReader(contents))
+		require.ErrorIs(t, err, ErrUploadLocked)
+		require.ErrorIs(t, rs.DeleteUpload(ctx, principal, anUpload.ID), ErrUploadLocked)
+
+		// Lock of a request which failed expires
+		anUpload.LockedUntil = rs.now().Add(-time.Second)
+		require.NoError(t, store.SaveUploads(ctx, anUpload))
+
+		_, err = rs.WriteUpload(ctx, principal, anUpload.ID, 0, strings.NewReader(contents))
+		require.NoError(t, err)
+	})
+
+	t.Run("Upload expired", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store, anUpload := newRagServer(t)
+		anUpload.Expires = rs.now().Add(-time.Second)
+		require.NoError(t, store.SaveUploads(ctx, anUpload))
+
+		_, err := rs.WriteUpload(ctx, principal, anUpload.ID, 0, strings.NewReader(contents))
+		require.ErrorIs(t, err, ErrUploadExpired)
+	})
+
+	t.Run("Upload of invalid file type is deleted", func(t *testing.T) {
+		t.Parallel()
+
+		rs, store, anUpload := newRagServer(t)
+		delete(rs.extractors, ContentTypeText)
+
+		_, err := rs.WriteUpload(ctx, principal, anUpload.ID, 0, strings.NewReader(contents))
+		require.ErrorIs(t, err, ErrInvalidFileType)
+		assert.NotContains(t, store.uploads, anUpload.ID)
+		assert.NoFileExists(t, anUpload.TempFile)
+	})
+}
+
+func TestRagServer_DeleteExpiredUploads(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx       = context.Background()
+		store     = newFakeStore()
+		rs        = newTestRagServer(store)
+		principal = authz.New(authz.ID{UUID: uuid.Must(uuid.NewV4())}, "test principal")
+	)
+	rs.filestorage = fakeFileStorage{contents: map[string][]byte{}, tempDir: t.TempDir()}
+	rs.maxFileSize = MaxFileSize
+	rs.uploadExpiry = defaultUploadExpiry
+
+	newUpload := func(expires time.Time, locked bool) *Upload {
+		anUpload, err := rs.CreateUpload(ctx, principal, "emissions.txt", 10, FileParams{})
+		require.NoError(t, err)
+		anUpload.Expires = expires
+		if locked {
+			anUpload.LockID = uuid.Must(uuid.NewV4())
+			anUpload.LockedUntil = rs.now().Add(time.Minute)
+		}
+		require.NoError(t, store.SaveUploads(ctx, anUpload))
+		return anUpload
+	}
+
+	var (
+		expired       = newUpload(rs.now().Add(-time.Minute), false)
+		expiredLocked = newUpload(rs.now().Add(-time.Minute), true)
+		active        = newUpload(rs.now().Add(time.Hour), false)
+	)
+
+	deleted, err := rs.deleteExpiredUploads(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assert.NotContains(t, store.uploads, expired.ID)
+	assert.NoFileExists(t, expired.TempFile)
+
+	for _, anUpload := range []*Upload{expiredLocked, active} {
+		assert.Contains(t, store.uploads, anUpload.ID)
+		assert.FileExists(t, anUpload.TempFile)
+	}
+}
@@ -0,0 +1,37 @@
+package ragserver
+
+import (
+	"sync"
+)
+
+// workerPool runs jobs on a bounded number of goroutines. It is used by a single polling
+// goroutine, so the number of available workers can only grow between checking it and running jobs.
+type workerPool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{
+		slots: make(chan struct{}, size),
+	}
+}
+
+// available returns the number of workers not running a job.
+func (p *workerPool) available() int {
+	return cap(p.slots) - len(p.slots)
+}
+
+// run starts the job on a worker, blocking until one is available.
+func (p *workerPool) run(job func()) {
+	p.slots <- struct{}{}
+	p.wg.Go(func() {
+		defer func() { <-p.slots }()
+		job()
+	})
+}
+
+// wait blocks until all running jobs have finished.
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
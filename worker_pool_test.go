@@ -0,0 +1,44 @@
+package ragserver
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Parallel()
+
+	var (
+		pool    = newWorkerPool(2)
+		release = make(chan struct{})
+		started = make(chan struct{}, 3)
+		done    atomic.Int32
+	)
+
+	assert.Equal(t, 2, pool.available())
+
+	job := func() {
+		started <- struct{}{}
+		<-release
+		done.Add(1)
+	}
+
+	pool.run(job)
+	pool.run(job)
+	<-started
+	<-started
+	assert.Equal(t, 0, pool.available())
+
+	// A third job waits for a worker to become available
+	go pool.run(job)
+	assert.Len(t, started, 0)
+
+	close(release)
+	<-started
+	pool.wait()
+
+	assert.Equal(t, int32(3), done.Load())
+	assert.Equal(t, 2, pool.available())
+}